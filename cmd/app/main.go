@@ -7,6 +7,8 @@ import (
 	"FitByte/internal/middleware"
 	"FitByte/internal/repositories"
 	"FitByte/internal/service"
+	"context"
+	"time"
 
 	"FitByte/pkg/log"
 
@@ -24,27 +26,99 @@ func main() {
 
 	db := infrastructure.InitDB(appConfig)
 	minioClient := infrastructure.InitMinioStorage(appConfig)
+	programCatalog := infrastructure.InitTrainingPrograms(appConfig)
+	exerciseCatalog := infrastructure.InitExerciseCatalog(appConfig)
 
 	r := gin.Default()
 	r.Use(gin.Recovery())
 	r.Use(middleware.RequestLogger())
 
+	// Idempotency keys guard the activity, file and profile writes against client retries
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, appConfig.Idempotency.TTL)
+	idempotencyService.StartExpiryPurge(time.Hour)
+
+	// Lets a service save a change and what depends on it through several repositories at once
+	transactor := repositories.NewTransactor(db)
+
 	profileRepo := repositories.NewProfileRepository(db)
-	profileService := service.NewProfileService(appConfig, profileRepo)
-	profileHandler := handlers.NewProfileHandler(r, appConfig, profileService)
+	trainingLoadRepo := repositories.NewTrainingLoadRepository(db)
+	trainingLoadService := service.NewTrainingLoadService(trainingLoadRepo, profileRepo)
+	followRepo := repositories.NewFollowRepository(db)
+	followService := service.NewFollowService(followRepo, profileRepo)
+	profileService := service.NewProfileService(appConfig, profileRepo, transactor, trainingLoadService, followService)
+	profileHandler := handlers.NewProfileHandler(r, appConfig, profileService, idempotencyService)
 	profileHandler.SetupRoutes()
 
+	activityRepo := repositories.NewActivityRepository(db)
+	activityTrackRepo := repositories.NewActivityTrackRepository(db)
+	activityLapRepo := repositories.NewActivityLapRepository(db)
+
+	plannedWorkoutRepo := repositories.NewPlannedWorkoutRepository(db)
+	plannedWorkoutService := service.NewPlannedWorkoutService(plannedWorkoutRepo, activityRepo, profileRepo)
+	activityRevisionRepo := repositories.NewActivityRevisionRepository(db)
+	activityRevisionService := service.NewActivityRevisionService(activityRevisionRepo, activityRepo, profileRepo, transactor, trainingLoadService)
+
 	minioRepo := repositories.NewMinioRepository(minioClient, appConfig.Minio.Bucket)
 	fileRepo := repositories.NewFileRepository(db)
 	fileService := service.NewFileService(fileRepo, minioRepo)
-	fileHandler := handlers.NewFileHandler(r, appConfig, fileService)
+	importJobRepo := repositories.NewImportJobRepository(db)
+	activityImportService := service.NewActivityImportService(activityRepo, importJobRepo, profileRepo, transactor, fileService, plannedWorkoutService, activityRevisionService, trainingLoadService)
+	// Import jobs cut off by the last shutdown can never finish
+	if err := activityImportService.FailInterruptedImportJobs(context.Background()); err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to fail interrupted import jobs")
+	}
+	fileHandler := handlers.NewFileHandler(r, appConfig, fileService, activityImportService, idempotencyService)
 	fileHandler.SetupRoutes()
 
-	activityRepo := repositories.NewActivityRepository(db)
-	activityService := service.NewActivityService(activityRepo)
-	activityHandler := handlers.NewActivityHandler(r, appConfig, activityService)
+	activityFileRepo := repositories.NewActivityFileRepository(db)
+	activityAttachmentService := service.NewActivityAttachmentService(activityRepo, activityFileRepo, fileRepo, minioRepo)
+	activityService := service.NewActivityService(activityRepo, profileRepo, transactor, plannedWorkoutService, activityRevisionService, activityAttachmentService, trainingLoadService)
+	activityExportService := service.NewActivityExportService(activityRepo, profileRepo, activityTrackRepo, fileRepo, minioRepo)
+	activityTrackService := service.NewActivityTrackService(activityRepo, activityTrackRepo, activityLapRepo, profileRepo)
+	// Deleted activities can be restored until the retention window purges them
+	activityTrashService := service.NewActivityTrashService(activityRepo, profileRepo, transactor, activityRevisionService, activityAttachmentService, trainingLoadService, appConfig.Trash.Retention)
+	activityTrashService.StartRetentionPurge(time.Hour)
+	activityHandler := handlers.NewActivityHandler(r, appConfig, activityService, activityExportService, activityTrackService, activityTrashService, activityRevisionService, activityAttachmentService, idempotencyService)
 	activityHandler.SetupRoutes()
 
+	goalRepo := repositories.NewGoalRepository(db)
+	goalService := service.NewGoalService(goalRepo, activityRepo)
+	goalHandler := handlers.NewGoalHandler(r, appConfig, goalService)
+	goalHandler.SetupRoutes()
+
+	plannedWorkoutHandler := handlers.NewPlannedWorkoutHandler(r, appConfig, plannedWorkoutService)
+	plannedWorkoutHandler.SetupRoutes()
+
+	// Enrollments move on to their next program week in the background
+	programEnrollmentRepo := repositories.NewProgramEnrollmentRepository(db)
+	trainingProgramService := service.NewTrainingProgramService(programCatalog, programEnrollmentRepo)
+	trainingProgramService.StartProgressionSync(time.Hour)
+	trainingProgramHandler := handlers.NewTrainingProgramHandler(r, appConfig, trainingProgramService)
+	trainingProgramHandler.SetupRoutes()
+
+	workoutTemplateRepo := repositories.NewWorkoutTemplateRepository(db)
+	workoutTemplateService := service.NewWorkoutTemplateService(workoutTemplateRepo, activityService)
+	workoutTemplateHandler := handlers.NewWorkoutTemplateHandler(r, appConfig, workoutTemplateService, idempotencyService)
+	workoutTemplateHandler.SetupRoutes()
+
+	strengthRepo := repositories.NewStrengthRepository(db)
+	strengthService := service.NewStrengthService(exerciseCatalog, strengthRepo, activityRepo)
+	strengthHandler := handlers.NewStrengthHandler(r, appConfig, strengthService, idempotencyService)
+	strengthHandler.SetupRoutes()
+
+	activityHeartRateRepo := repositories.NewActivityHeartRateRepository(db)
+	heartRateService := service.NewHeartRateService(activityRepo, activityHeartRateRepo, profileRepo, transactor, trainingLoadService)
+	heartRateHandler := handlers.NewHeartRateHandler(r, appConfig, heartRateService, idempotencyService)
+	heartRateHandler.SetupRoutes()
+
+	trainingLoadHandler := handlers.NewTrainingLoadHandler(r, appConfig, trainingLoadService)
+	trainingLoadHandler.SetupRoutes()
+
+	feedService := service.NewFeedService(activityRepo, profileRepo, followRepo)
+	followHandler := handlers.NewFollowHandler(r, appConfig, followService, feedService, idempotencyService)
+	followHandler.SetupRoutes()
+
 	log.Logger.Info().Str("port", appConfig.App.Port).Msg("Starting server")
 	if err := r.Run(":" + appConfig.App.Port); err != nil {
 		log.Logger.Fatal().Err(err).Msg("Failed to start server")
@@ -1,10 +1,16 @@
 package configs
 
+import "time"
+
 type Config struct {
-	App    App          `mapstructure:"app" validate:"required"`
-	DB     Database     `mapstructure:"database" validate:"required"`
-	Secret SecretConfig `mapstructure:"secret" validate:"required"`
-	Minio  MinioConfig  `mapstructure:"minio" validate:"required"`
+	App         App               `mapstructure:"app" validate:"required"`
+	DB          Database          `mapstructure:"database" validate:"required"`
+	Secret      SecretConfig      `mapstructure:"secret" validate:"required"`
+	Minio       MinioConfig       `mapstructure:"minio" validate:"required"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Programs    ProgramsConfig    `mapstructure:"programs"`
+	Trash       TrashConfig       `mapstructure:"trash"`
+	Exercises   ExercisesConfig   `mapstructure:"exercises"`
 }
 
 type App struct {
@@ -30,3 +36,23 @@ type MinioConfig struct {
 	UseSSL          bool   `mapstructure:"use_ssl"`
 	Bucket          string `mapstructure:"bucket" validate:"required"`
 }
+
+type IdempotencyConfig struct {
+	// TTL is how long responses are kept for replay to requests with the same Idempotency-Key
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+type ProgramsConfig struct {
+	// Dir holds the training program definitions, one YAML or JSON file per version
+	Dir string `mapstructure:"dir"`
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted activities can be restored before they are purged
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+type ExercisesConfig struct {
+	// File is the YAML exercise catalog used by strength sessions
+	File string `mapstructure:"file"`
+}
@@ -5,3 +5,41 @@ var AllowedExts = map[string]bool{
 	".jpeg": true,
 	".png":  true,
 }
+
+// WorkoutFileFormats maps workout file extensions to their import format
+var WorkoutFileFormats = map[string]string{
+	".gpx": "gpx",
+	".tcx": "tcx",
+	".fit": "fit",
+}
+
+// MaxWorkoutFileSize is the maximum size of an imported workout file (20 MiB)
+const MaxWorkoutFileSize = int64(20 * 1024 * 1024)
+
+// AppleHealthFileFormats maps Apple Health export extensions to their import format
+var AppleHealthFileFormats = map[string]string{
+	".zip": "zip",
+	".xml": "xml",
+}
+
+// MaxAppleHealthFileSize is the maximum size of an uploaded Apple Health export (2 GiB)
+const MaxAppleHealthFileSize = int64(2 * 1024 * 1024 * 1024)
+
+// MaxActivityBatchSize is the maximum number of activities accepted by a single batch create request
+const MaxActivityBatchSize = 100
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatGPX    = "gpx"
+)
+
+// ExportContentTypes maps activity export formats to their response content type
+var ExportContentTypes = map[string]string{
+	ExportFormatCSV:    "text/csv",
+	ExportFormatNDJSON: "application/x-ndjson",
+	ExportFormatGPX:    "application/gpx+xml",
+}
+
+// MaxActivityAttachments is the maximum number of uploaded files attached to one activity
+const MaxActivityAttachments = 10
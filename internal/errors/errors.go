@@ -3,9 +3,48 @@ package errors
 import "errors"
 
 var (
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrorUserNotFound     = errors.New("user not found")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrActivityNotFound   = errors.New("activity not found")
+	ErrUserAlreadyExists        = errors.New("user already exists")
+	ErrorUserNotFound           = errors.New("user not found")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrActivityNotFound         = errors.New("activity not found")
+	ErrActivityNotInTrash       = errors.New("activity not found in trash")
+	ErrRevisionNotFound         = errors.New("activity revision not found")
+	ErrGoalNotFound             = errors.New("goal not found")
+	ErrInvalidActivityType      = errors.New("invalid activity type")
+	ErrInvalidWorkoutFile       = errors.New("invalid workout file")
+	ErrUnknownSport             = errors.New("unknown workout sport")
+	ErrImportJobNotFound        = errors.New("import job not found")
+	ErrUnsupportedExportFormat  = errors.New("unsupported export format")
+	ErrDistanceNotSupported     = errors.New("distance and elevation gain are only supported for Running, Cycling, Hiking, Walking and Swimming")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrInvalidCursor            = errors.New("invalid cursor")
+	ErrTrackNotFound            = errors.New("activity has no GPS track")
+	ErrPoolLengthNotSupported   = errors.New("pool length is only supported for Swimming")
+	ErrActivityOverlap          = errors.New("activity overlaps an existing activity")
+	ErrNothingToMerge           = errors.New("at least two different activities are needed to merge")
+	ErrActivitiesNotOverlapping = errors.New("activities can only be merged when they overlap the primary activity")
+	ErrPlannedWorkoutNotFound   = errors.New("planned workout not found")
+	ErrTemplateNotFound         = errors.New("workout template not found")
+	ErrTemplateNameTaken        = errors.New("a workout template with this name already exists")
+	ErrProgramNotFound          = errors.New("training program not found")
+	ErrEnrollmentNotFound       = errors.New("program enrollment not found")
+	ErrAlreadyEnrolled          = errors.New("already enrolled in this training program")
+	ErrEnrollmentNotActive      = errors.New("program enrollment is not active")
+	ErrStartDateInPast          = errors.New("startDate must not be in the past")
+	ErrVersionMismatch          = errors.New("resource has been modified since the given version")
+	ErrFileNotFound             = errors.New("file not found")
+	ErrFileNotOwned             = errors.New("file belongs to another user")
+	ErrFileNotImage             = errors.New("only JPEG and PNG uploads can be attached")
+	ErrFileAlreadyAttached      = errors.New("file is already attached to an activity")
+	ErrTooManyAttachments       = errors.New("activity would exceed the maximum number of attachments")
+	ErrExerciseNotFound         = errors.New("exercise not found")
+	ErrNotStrengthActivity      = errors.New("exercises can only be logged on StrengthTraining activities")
+	ErrAttachmentNotFound       = errors.New("file is not attached to this activity")
+	ErrHeartRateNotFound        = errors.New("activity has no heart rate recorded")
+	ErrTooFewHeartRateSamples   = errors.New("heart rate needs at least two readings between 25 and 250 bpm")
+	ErrMaxHeartRateUnknown      = errors.New("set maxHeartRate or birthDate in the profile to get heart rate zones")
+	ErrCannotFollowSelf         = errors.New("users cannot follow themselves")
+	ErrFollowNotFound           = errors.New("not following this user")
+	ErrFollowRequestNotFound    = errors.New("follow request not found")
 )
-				
\ No newline at end of file
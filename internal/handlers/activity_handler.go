@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"FitByte/configs"
+	"FitByte/internal/constant"
 	customErrors "FitByte/internal/errors"
 	"FitByte/internal/middleware"
 	"FitByte/internal/models"
 	"FitByte/internal/service"
+	"FitByte/pkg/log"
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,24 +21,37 @@ import (
 )
 
 type ActivityHandler struct {
-	Engine      *gin.Engine
-	AppConfig   configs.Config
-	ActivitySvc service.ActivityService
-	validator   *validator.Validate
+	Engine        *gin.Engine
+	AppConfig     configs.Config
+	ActivitySvc   service.ActivityService
+	ExportSvc     service.ActivityExportService
+	TrackSvc      service.ActivityTrackService
+	TrashSvc      service.ActivityTrashService
+	RevisionSvc   service.ActivityRevisionService
+	AttachmentSvc service.ActivityAttachmentService
+	IdemSvc       service.IdempotencyService
+	validator     *validator.Validate
 }
 
-func NewActivityHandler(engine *gin.Engine, appConfig configs.Config, activityService service.ActivityService) *ActivityHandler {
+func NewActivityHandler(engine *gin.Engine, appConfig configs.Config, activityService service.ActivityService, exportService service.ActivityExportService, trackService service.ActivityTrackService, trashService service.ActivityTrashService, revisionService service.ActivityRevisionService, attachmentService service.ActivityAttachmentService, idempotencyService service.IdempotencyService) *ActivityHandler {
 	return &ActivityHandler{
-		Engine:      engine,
-		AppConfig:   appConfig,
-		ActivitySvc: activityService,
-		validator:   validator.New(),
+		Engine:        engine,
+		AppConfig:     appConfig,
+		ActivitySvc:   activityService,
+		ExportSvc:     exportService,
+		TrackSvc:      trackService,
+		TrashSvc:      trashService,
+		RevisionSvc:   revisionService,
+		AttachmentSvc: attachmentService,
+		IdemSvc:       idempotencyService,
+		validator:     validator.New(),
 	}
 }
 
 func (h *ActivityHandler) SetupRoutes() {
 	protectedRoutes := h.Engine.Group("/v1")
 	protectedRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	protectedRoutes.Use(middleware.Idempotency(h.IdemSvc))
 	protectedRoutes.Use(middleware.ContentTypeMiddleware())
 	protectedRoutes.Use(middleware.ValidationMiddleware())
 
@@ -41,8 +59,26 @@ func (h *ActivityHandler) SetupRoutes() {
 	protectedRoutes.POST("/activity", 
 		middleware.ValidateJSONForNulls([]string{"activityType", "doneAt", "durationInMinutes"}),
 		h.CreateActivity)
+
+	protectedRoutes.POST("/activity/batch", h.CreateActivitiesBatch)
+	protectedRoutes.POST("/activity/merge", h.MergeActivities)
+	protectedRoutes.POST("/activity/:activityId/restore", h.RestoreActivity)
+	protectedRoutes.POST("/activity/:activityId/revert",
+		middleware.ValidateJSONForNulls([]string{"revision"}),
+		h.RevertActivity)
+	protectedRoutes.POST("/activity/:activityId/attachments",
+		middleware.ValidateJSONForNulls([]string{"fileIds"}),
+		h.AttachFiles)
 	
 	protectedRoutes.GET("/activity", h.GetActivities)
+	protectedRoutes.GET("/activity/export", h.ExportActivities)
+	protectedRoutes.GET("/activity/duplicates", h.GetDuplicateCandidates)
+	protectedRoutes.GET("/activity/trash", h.GetTrash)
+	protectedRoutes.GET("/activity/:activityId", h.GetActivity)
+	protectedRoutes.GET("/activity/:activityId/track", h.GetActivityTrack)
+	protectedRoutes.GET("/activity/:activityId/splits", h.GetActivitySplits)
+	protectedRoutes.GET("/activity/:activityId/history", h.GetActivityHistory)
+	protectedRoutes.GET("/activity/:activityId/attachments", h.GetAttachments)
 	
 	// PATCH activity with null validation for optional fields that shouldn't be null when provided
 	protectedRoutes.PATCH("/activity/:activityId", 
@@ -50,6 +86,9 @@ func (h *ActivityHandler) SetupRoutes() {
 		h.UpdateActivity)
 		
 	protectedRoutes.DELETE("/activity/:activityId", h.DeleteActivity)
+	protectedRoutes.DELETE("/activity/:activityId/attachments/:fileId", h.DetachFile)
+
+	protectedRoutes.GET("/tags", h.GetTags)
 }
 
 func (h *ActivityHandler) CreateActivity(c *gin.Context) {
@@ -82,9 +121,22 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 		return
 	}
 
+	var conflictQuery models.ActivityConflictQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &conflictQuery); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
 	ctx := c.Request.Context()
-	response, err := h.ActivitySvc.CreateActivity(ctx, userID, req)
+	response, err := h.ActivitySvc.CreateActivity(ctx, userID, req, conflictQuery.ConflictPolicy)
 	if err != nil {
+		if respondActivityOverlap(c, err) {
+			return
+		}
+		if isActivityMetricsError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activity"})
 		return
 	}
@@ -92,7 +144,7 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
-func (h *ActivityHandler) GetActivities(c *gin.Context) {
+func (h *ActivityHandler) CreateActivitiesBatch(c *gin.Context) {
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -101,58 +153,287 @@ func (h *ActivityHandler) GetActivities(c *gin.Context) {
 
 	userID := uint(userIDInterface.(int64))
 
-	query := models.GetActivitiesQuery{}
-	
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			query.Limit = limit
-		}
+	var req models.CreateActivitiesBatchRequest
+
+	// Handle JSON binding errors (empty body, malformed JSON)
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
 	}
-	
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-			query.Offset = offset
+
+	if len(req.Activities) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "activities must not be empty"})
+		return
+	}
+
+	if len(req.Activities) > constant.MaxActivityBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("activities must contain at most %d items", constant.MaxActivityBatchSize)})
+		return
+	}
+
+	var conflictQuery models.ActivityConflictQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &conflictQuery); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	// Get validator from context
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	// Validate every item independently, keeping track of where the valid ones came from
+	results := make([]models.BatchActivityResult, len(req.Activities))
+	validItems := make([]models.CreateActivityRequest, 0, len(req.Activities))
+	validIndexes := make([]int, 0, len(req.Activities))
+	for i, item := range req.Activities {
+		results[i].Index = i
+
+		if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), item); validationErrors != nil {
+			results[i].Errors = validationErrors
+			continue
+		}
+
+		if _, err := time.Parse(time.RFC3339, item.DoneAt); err != nil {
+			results[i].Errors = map[string]string{"doneat": "doneAt must be a valid ISO date"}
+			continue
+		}
+
+		if err := service.ValidateActivityMetrics(item.ActivityType, item.DistanceMeters, item.ElevationGainMeters, item.PoolLengthMeters); err != nil {
+			results[i].Errors = map[string]string{"activitytype": err.Error()}
+			continue
 		}
+
+		validItems = append(validItems, item)
+		validIndexes = append(validIndexes, i)
+	}
+
+	ctx := c.Request.Context()
+	overlaps, err := h.ActivitySvc.DetectOverlaps(ctx, userID, validItems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activities"})
+		return
 	}
 
-	if activityType := c.Query("activityType"); activityType != "" {
-		if _, exists := models.ActivityTypeCalories[activityType]; exists {
-			query.ActivityType = activityType
+	// Items of the same batch can overlap each other as well as what is already saved
+	batchOverlaps := models.BatchOverlaps(validItems)
+
+	// Under the reject policy overlapping items fail like invalid ones, both items of an
+	// overlapping pair within the batch included
+	if conflictQuery.ConflictPolicy == models.ConflictPolicyReject {
+		keptItems, keptIndexes, keptOverlaps := validItems[:0], validIndexes[:0], overlaps[:0]
+		for i, item := range validItems {
+			if len(overlaps[i]) > 0 || len(batchOverlaps[i]) > 0 {
+				results[validIndexes[i]].Errors = map[string]string{"doneat": customErrors.ErrActivityOverlap.Error()}
+				continue
+			}
+			keptItems = append(keptItems, item)
+			keptIndexes = append(keptIndexes, validIndexes[i])
+			keptOverlaps = append(keptOverlaps, overlaps[i])
 		}
+		validItems, validIndexes, overlaps = keptItems, keptIndexes, keptOverlaps
+	}
+
+	invalidCount := len(req.Activities) - len(validItems)
+	if len(validItems) == 0 || (req.AllOrNothing && invalidCount > 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"results": results})
+		return
+	}
+
+	responses, err := h.ActivitySvc.CreateActivities(ctx, userID, validItems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activities"})
+		return
 	}
 
-	if doneAtFromStr := c.Query("doneAtFrom"); doneAtFromStr != "" {
-		if doneAtFrom, err := time.Parse(time.RFC3339, doneAtFromStr); err == nil {
-			query.DoneAtFrom = doneAtFrom
+	for i, response := range responses {
+		response.OverlapsWith = overlaps[i]
+		// Rejected batch overlaps were dropped above, so only flagged ones are left
+		if conflictQuery.ConflictPolicy != models.ConflictPolicyReject {
+			for _, j := range batchOverlaps[i] {
+				response.OverlapsWith = append(response.OverlapsWith, responses[j].ActivityID)
+			}
 		}
+		result := &results[validIndexes[i]]
+		result.Success = true
+		result.Activity = &response
 	}
 
-	if doneAtToStr := c.Query("doneAtTo"); doneAtToStr != "" {
-		if doneAtTo, err := time.Parse(time.RFC3339, doneAtToStr); err == nil {
-			query.DoneAtTo = doneAtTo
+	status := http.StatusCreated
+	if invalidCount > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, gin.H{"results": results})
+}
+
+func (h *ActivityHandler) GetActivities(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	query, validationErrors := parseActivitiesQuery(c, h.validator)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	page, err := h.ActivitySvc.GetActivities(ctx, userID, query)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor is invalid or does not match the sort order"})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
+		return
+	}
+
+	// The body stays a plain array for existing clients; paging metadata goes in headers
+	if page.NextCursor != nil {
+		c.Header("X-Next-Cursor", *page.NextCursor)
+	}
+	if page.TotalCount != nil {
+		c.Header("X-Total-Count", strconv.FormatInt(*page.TotalCount, 10))
+	}
+
+	c.JSON(http.StatusOK, page.Activities)
+}
+
+func (h *ActivityHandler) ExportActivities(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	format := c.DefaultQuery("format", constant.ExportFormatCSV)
+	contentType, exists := constant.ExportContentTypes[format]
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of: csv ndjson gpx"})
+		return
+	}
+
+	query, validationErrors := parseActivitiesQuery(c, h.validator)
+	if validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	// Export ignores limit and offset and returns every matching activity
+	query.Limit = 0
+	query.Offset = 0
+
+	filename := fmt.Sprintf("activities_%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Detach from the request deadline so large exports are not cut off; a client
+	// disconnect still stops the export through the failed write
+	ctx := context.WithoutCancel(c.Request.Context())
+	err := h.ExportSvc.ExportActivities(ctx, userID, query, format, c.Writer)
+	if err != nil {
+		// Headers are already sent, so the only thing left to do is stop writing
+		log.Logger.Error().Err(err).Str("format", format).Msg("Failed to export activities")
+	}
+}
+
+func (h *ActivityHandler) GetActivityTrack(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
-	if caloriesBurnedMinStr := c.Query("caloriesBurnedMin"); caloriesBurnedMinStr != "" {
-		if caloriesBurnedMin, err := strconv.Atoi(caloriesBurnedMinStr); err == nil && caloriesBurnedMin >= 0 {
-			query.CaloriesBurnedMin = caloriesBurnedMin
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	var query models.GetActivityTrackQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.TrackSvc.GetActivityTrack(ctx, userID, activityID, query)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
 		}
+		if errors.Is(err, customErrors.ErrTrackNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity has no GPS track"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity track"})
+		return
 	}
 
-	if caloriesBurnedMaxStr := c.Query("caloriesBurnedMax"); caloriesBurnedMaxStr != "" {
-		if caloriesBurnedMax, err := strconv.Atoi(caloriesBurnedMaxStr); err == nil && caloriesBurnedMax >= 0 {
-			query.CaloriesBurnedMax = caloriesBurnedMax
+	if query.Format == models.TrackFormatGeoJSON {
+		c.Header("Content-Type", "application/geo+json")
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ActivityHandler) GetActivitySplits(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	response, err := h.TrackSvc.GetActivitySplits(ctx, userID, activityID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity splits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ActivityHandler) GetActivity(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
 	ctx := c.Request.Context()
-	activities, err := h.ActivitySvc.GetActivities(ctx, userID, query)
+	response, err := h.ActivitySvc.GetActivity(ctx, userID, activityID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity"})
 		return
 	}
 
-	c.JSON(http.StatusOK, activities)
+	if middleware.NotModified(c, middleware.VersionETag(response.Version)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
@@ -192,20 +473,75 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 		return
 	}
 
+	var conflictQuery models.ActivityConflictQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &conflictQuery); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	expectedVersion, ok := middleware.IfMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match must be a single ETag of the activity"})
+		return
+	}
+
 	ctx := c.Request.Context()
-	response, err := h.ActivitySvc.UpdateActivity(ctx, userID, activityID, req)
+	response, err := h.ActivitySvc.UpdateActivity(ctx, userID, activityID, req, conflictQuery.ConflictPolicy, expectedVersion)
 	if err != nil {
 		if errors.Is(err, customErrors.ErrActivityNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
 			return
 		}
+		if errors.Is(err, customErrors.ErrVersionMismatch) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Activity has been modified since it was read"})
+			return
+		}
+		if respondActivityOverlap(c, err) {
+			return
+		}
+		if isActivityMetricsError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update activity"})
 		return
 	}
 
+	c.Header("ETag", middleware.VersionETag(response.Version))
 	c.JSON(http.StatusOK, response)
 }
 
+// parseActivitiesQuery binds and validates the activity list filters from the query string,
+// returning errors keyed by query parameter when any of them is invalid
+func parseActivitiesQuery(c *gin.Context, validate *validator.Validate) (models.GetActivitiesQuery, map[string]string) {
+	query := models.GetActivitiesQuery{}
+
+	if validationErrors := middleware.BindQuery(c, validate, &query); validationErrors != nil {
+		return query, validationErrors
+	}
+
+	query.Tags = models.NormalizeTags(query.Tags)
+	query.Search = strings.TrimSpace(query.Search)
+
+	return query, nil
+}
+
+// respondActivityOverlap answers with 409 Conflict and the overlapped activities when
+// err rejects an activity under the reject conflict policy
+func respondActivityOverlap(c *gin.Context, err error) bool {
+	var overlapErr *service.ActivityOverlapError
+	if !errors.As(err, &overlapErr) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": overlapErr.Error(), "overlapsWith": overlapErr.ActivityIDs})
+	return true
+}
+
+// isActivityMetricsError reports whether err rejects distance, elevation or pool length for the activity type
+func isActivityMetricsError(err error) bool {
+	return errors.Is(err, customErrors.ErrDistanceNotSupported) || errors.Is(err, customErrors.ErrPoolLengthNotSupported)
+}
+
 func (h *ActivityHandler) validateUpdateRequest(req *models.UpdateActivityRequest) error {
 	if req.ActivityType != nil && *req.ActivityType == "" {
 		return errors.New("activityType cannot be empty string")
@@ -250,3 +586,325 @@ func (h *ActivityHandler) DeleteActivity(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Activity deleted successfully"})
 }
+
+
+func (h *ActivityHandler) GetTags(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	ctx := c.Request.Context()
+	tags, err := h.ActivitySvc.GetTags(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *ActivityHandler) GetDuplicateCandidates(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetDuplicatesQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	candidates, err := h.ActivitySvc.GetDuplicateCandidates(ctx, userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get duplicate activities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+func (h *ActivityHandler) MergeActivities(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var req models.MergeActivitiesRequest
+
+	// Handle JSON binding errors (empty body, malformed JSON)
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	// Get validator from context
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.ActivitySvc.MergeActivities(ctx, userID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrNothingToMerge) || errors.Is(err, customErrors.ErrActivitiesNotOverlapping) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, customErrors.ErrTooManyAttachments) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A merged activity can have at most %d attachments", constant.MaxActivityAttachments)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge activities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ActivityHandler) GetTrash(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetTrashQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	activities, err := h.TrashSvc.GetTrash(ctx, userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get deleted activities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, activities)
+}
+
+func (h *ActivityHandler) RestoreActivity(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	response, err := h.TrashSvc.RestoreActivity(ctx, userID, activityID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotInTrash) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found in trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore activity"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ActivityHandler) GetActivityHistory(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	history, err := h.RevisionSvc.GetHistory(ctx, userID, activityID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *ActivityHandler) RevertActivity(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	var req models.RevertActivityRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.RevisionSvc.RevertActivity(ctx, userID, activityID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity revision not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revert activity"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ActivityHandler) GetAttachments(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	attachments, err := h.AttachmentSvc.GetAttachments(ctx, userID, activityID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (h *ActivityHandler) AttachFiles(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	var req models.AttachFilesRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	attachments, err := h.AttachmentSvc.AttachFiles(ctx, userID, activityID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErrors.ErrActivityNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		case errors.Is(err, customErrors.ErrFileNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		case errors.Is(err, customErrors.ErrFileNotOwned):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, customErrors.ErrFileNotImage):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, customErrors.ErrFileAlreadyAttached):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, customErrors.ErrTooManyAttachments):
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("An activity can have at most %d attachments", constant.MaxActivityAttachments)})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to attach files"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachments)
+}
+
+func (h *ActivityHandler) DetachFile(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	fileID, err := strconv.ParseUint(c.Param("fileId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fileId is invalid"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err = h.AttachmentSvc.DetachFile(ctx, userID, activityID, uint(fileID))
+	if err != nil {
+		if errors.Is(err, customErrors.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detach file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File detached successfully"})
+}
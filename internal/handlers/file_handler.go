@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
 	"FitByte/internal/middleware"
 	"FitByte/internal/models"
 	"FitByte/internal/service"
 	"FitByte/pkg/log"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -22,20 +24,33 @@ type FileHandler struct {
 	Engine    *gin.Engine
 	AppConfig configs.Config
 	FileSvc   service.FileService
+	ImportSvc service.ActivityImportService
+	IdemSvc   service.IdempotencyService
 }
 
-func NewFileHandler(engine *gin.Engine, appConfig configs.Config, fileService service.FileService) *FileHandler {
+func NewFileHandler(engine *gin.Engine, appConfig configs.Config, fileService service.FileService, importService service.ActivityImportService, idempotencyService service.IdempotencyService) *FileHandler {
 	return &FileHandler{
 		Engine:    engine,
 		AppConfig: appConfig,
 		FileSvc:   fileService,
+		ImportSvc: importService,
+		IdemSvc:   idempotencyService,
 	}
 }
 
 func (h *FileHandler) SetupRoutes() {
 	routes := h.Engine.Group("/v1/file")
 	routes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	routes.Use(middleware.Idempotency(h.IdemSvc))
 	routes.POST("", h.Upload)
+
+	// Workout imports are multipart uploads, so they live here rather than behind the JSON-only activity routes
+	importRoutes := h.Engine.Group("/v1/activity/import")
+	importRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	importRoutes.Use(middleware.Idempotency(h.IdemSvc))
+	importRoutes.POST("", h.ImportActivity)
+	importRoutes.POST("/apple-health", h.ImportAppleHealth)
+	importRoutes.GET("/jobs/:jobId", h.GetImportJob)
 }
 
 func (h *FileHandler) Upload(c *gin.Context) {
@@ -66,6 +81,145 @@ func (h *FileHandler) Upload(c *gin.Context) {
 		return
 	}
 
+	fileModel := newUploadFile(userID, file, header)
+
+	saved, err := h.FileSvc.SaveFile(ctx, userID, fileModel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to upload file",
+		})
+		return
+	}
+
+	// The file ID is what attaches the upload to an activity
+	c.JSON(http.StatusOK, gin.H{
+		"uri":    saved.FileURL,
+		"fileId": saved.ID,
+	})
+}
+
+func (h *FileHandler) ImportActivity(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to get file from request",
+		})
+		return
+	}
+	defer func(file multipart.File) {
+		err := file.Close()
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to close file")
+		}
+	}(file)
+
+	format, err := validateWorkoutUpload(header)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid file upload",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Optional override for files whose sport is missing or not recognised
+	activityType := c.PostForm("activityType")
+	if activityType != "" {
+		if _, exists := models.ActivityTypeCalories[activityType]; !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "activityType is invalid"})
+			return
+		}
+	}
+
+	response, err := h.ImportSvc.ImportActivity(ctx, uint(userID), format, activityType, newUploadFile(userID, file, header))
+	if err != nil {
+		if errors.Is(err, customErrors.ErrInvalidWorkoutFile) || errors.Is(err, customErrors.ErrUnknownSport) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to import activity",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *FileHandler) ImportAppleHealth(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to get file from request",
+		})
+		return
+	}
+	defer func(file multipart.File) {
+		err := file.Close()
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to close file")
+		}
+	}(file)
+
+	if header.Size > constant.MaxAppleHealthFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid file upload",
+			"message": "file size too large. Maximum allowed: 2 GiB",
+		})
+		return
+	}
+
+	format, exists := constant.AppleHealthFileFormats[strings.ToLower(filepath.Ext(header.Filename))]
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid file upload",
+			"message": "invalid file type. Only Apple Health export.zip or export.xml files are allowed",
+		})
+		return
+	}
+
+	job, err := h.ImportSvc.StartAppleHealthImport(ctx, uint(userID), format, file)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to start import",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *FileHandler) GetImportJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+	jobID := c.Param("jobId")
+
+	job, err := h.ImportSvc.GetImportJob(ctx, uint(userID), jobID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get import job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// newUploadFile names an uploaded file after its owner and upload time and places it under the owner's folder
+func newUploadFile(userID int64, file multipart.File, header *multipart.FileHeader) models.UploadFile {
 	timestamp := time.Now().Unix()
 	uploadedFileName := header.Filename
 	ext := filepath.Ext(uploadedFileName)
@@ -76,26 +230,28 @@ func (h *FileHandler) Upload(c *gin.Context) {
 		contentType = "application/octet-stream"
 	}
 
-	fileModel := models.UploadFile{
+	return models.UploadFile{
 		FileName:    filename,
 		FileData:    file,
 		Size:        header.Size,
 		ContentType: contentType,
 		FilePath:    fmt.Sprintf("/uploads/%d/%s", userID, filename),
 	}
+}
 
-	uri, err := h.FileSvc.SaveFileUpload(ctx, userID, fileModel)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   err.Error(),
-			"message": "Failed to upload file",
-		})
-		return
+func validateWorkoutUpload(header *multipart.FileHeader) (string, error) {
+	if header.Size > constant.MaxWorkoutFileSize {
+		return "", fmt.Errorf("file size too large. Maximum allowed: 20 MiB")
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"uri": uri,
-	})
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+
+	format, exists := constant.WorkoutFileFormats[ext]
+	if !exists {
+		return "", fmt.Errorf("invalid file type. Only GPX, TCX and FIT files are allowed")
+	}
+
+	return format, nil
 }
 
 func validateFileUpload(header *multipart.FileHeader) error {
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type FollowHandler struct {
+	Engine    *gin.Engine
+	AppConfig configs.Config
+	FollowSvc service.FollowService
+	FeedSvc   service.FeedService
+	IdemSvc   service.IdempotencyService
+	validator *validator.Validate
+}
+
+func NewFollowHandler(engine *gin.Engine, appConfig configs.Config, followService service.FollowService, feedService service.FeedService, idempotencyService service.IdempotencyService) *FollowHandler {
+	return &FollowHandler{
+		Engine:    engine,
+		AppConfig: appConfig,
+		FollowSvc: followService,
+		FeedSvc:   feedService,
+		IdemSvc:   idempotencyService,
+		validator: validator.New(),
+	}
+}
+
+func (h *FollowHandler) SetupRoutes() {
+	// Following takes no request body, so these routes do not require a JSON content type
+	protectedRoutes := h.Engine.Group("/v1")
+	protectedRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	protectedRoutes.Use(middleware.Idempotency(h.IdemSvc))
+
+	protectedRoutes.POST("/users/:userId/follow", h.Follow)
+	protectedRoutes.DELETE("/users/:userId/follow", h.Unfollow)
+
+	protectedRoutes.GET("/user/followers", h.GetFollowers)
+	protectedRoutes.DELETE("/user/followers/:userId", h.RemoveFollower)
+	protectedRoutes.GET("/user/following", h.GetFollowing)
+	protectedRoutes.GET("/user/follow-requests", h.GetFollowRequests)
+	protectedRoutes.POST("/user/follow-requests/:userId/accept", h.AcceptFollowRequest)
+	protectedRoutes.DELETE("/user/follow-requests/:userId", h.DeclineFollowRequest)
+
+	protectedRoutes.GET("/users/:userId/activities", h.GetUserActivities)
+	protectedRoutes.GET("/feed", h.GetFeed)
+}
+
+func (h *FollowHandler) Follow(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	followeeID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	follow, created, err := h.FollowSvc.Follow(ctx, userID, followeeID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrCannotFollowSelf) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrorUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrFollowNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Follow changed while it was being made, try again"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, follow)
+}
+
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	followeeID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.FollowSvc.Unfollow(ctx, userID, followeeID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrFollowNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not following this user"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed successfully"})
+}
+
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	h.listFollows(c, h.FollowSvc.GetFollowers, "Failed to get followers")
+}
+
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	h.listFollows(c, h.FollowSvc.GetFollowing, "Failed to get followed users")
+}
+
+func (h *FollowHandler) GetFollowRequests(c *gin.Context) {
+	h.listFollows(c, h.FollowSvc.GetFollowRequests, "Failed to get follow requests")
+}
+
+// listFollows serves the follower, following and follow request lists, which only
+// differ in where they come from
+func (h *FollowHandler) listFollows(c *gin.Context, list func(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error), failure string) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.ListFollowsQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	users, err := list(ctx, userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *FollowHandler) AcceptFollowRequest(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	followerID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.FollowSvc.AcceptFollowRequest(ctx, userID, followerID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrFollowRequestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Follow request not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept follow request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follow request accepted successfully"})
+}
+
+func (h *FollowHandler) DeclineFollowRequest(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	followerID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.FollowSvc.DeclineFollowRequest(ctx, userID, followerID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrFollowRequestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Follow request not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline follow request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follow request declined successfully"})
+}
+
+func (h *FollowHandler) RemoveFollower(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	followerID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.FollowSvc.RemoveFollower(ctx, userID, followerID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrFollowNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Follower not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove follower"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follower removed successfully"})
+}
+
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetFeedQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	page, err := h.FeedSvc.GetFeed(ctx, userID, query)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor is invalid"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feed"})
+		return
+	}
+
+	// Like the activity list, the body is a plain array and the next page goes in a header
+	if page.NextCursor != nil {
+		c.Header("X-Next-Cursor", *page.NextCursor)
+	}
+
+	c.JSON(http.StatusOK, page.Items)
+}
+
+func (h *FollowHandler) GetUserActivities(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	viewerID := uint(userIDInterface.(int64))
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var query models.GetFeedQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	page, err := h.FeedSvc.GetUserActivities(ctx, viewerID, userID, query)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrorUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor is invalid"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activities"})
+		return
+	}
+
+	if page.NextCursor != nil {
+		c.Header("X-Next-Cursor", *page.NextCursor)
+	}
+
+	c.JSON(http.StatusOK, page.Items)
+}
+
+// parseUserIDParam reads the userId path parameter, answering 400 when it is not an ID
+func parseUserIDParam(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is invalid"})
+		return 0, false
+	}
+	return uint(userID), true
+}
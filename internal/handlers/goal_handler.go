@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type GoalHandler struct {
+	Engine    *gin.Engine
+	AppConfig configs.Config
+	GoalSvc   service.GoalService
+}
+
+func NewGoalHandler(engine *gin.Engine, appConfig configs.Config, goalService service.GoalService) *GoalHandler {
+	return &GoalHandler{
+		Engine:    engine,
+		AppConfig: appConfig,
+		GoalSvc:   goalService,
+	}
+}
+
+func (h *GoalHandler) SetupRoutes() {
+	protectedRoutes := h.Engine.Group("/v1/goals")
+	protectedRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	protectedRoutes.Use(middleware.ContentTypeMiddleware())
+	protectedRoutes.Use(middleware.ValidationMiddleware())
+
+	protectedRoutes.POST("",
+		middleware.ValidateJSONForNulls([]string{"metric", "period", "target"}),
+		h.CreateGoal)
+	protectedRoutes.GET("", h.GetGoals)
+	protectedRoutes.GET("/progress", h.GetGoalsProgress)
+	protectedRoutes.GET("/:goalId", h.GetGoal)
+	protectedRoutes.PATCH("/:goalId",
+		middleware.ValidateJSONForNulls([]string{"metric", "period", "target"}),
+		h.UpdateGoal)
+	protectedRoutes.DELETE("/:goalId", h.DeleteGoal)
+}
+
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var req models.CreateGoalRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.GoalSvc.CreateGoal(ctx, userID, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	ctx := c.Request.Context()
+	goals, err := h.GoalSvc.GetGoals(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get goals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	goalID := c.Param("goalId")
+
+	ctx := c.Request.Context()
+	goal, err := h.GoalSvc.GetGoal(ctx, userID, goalID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrGoalNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get goal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	goalID := c.Param("goalId")
+
+	var req models.UpdateGoalRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.GoalSvc.UpdateGoal(ctx, userID, goalID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrGoalNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	goalID := c.Param("goalId")
+
+	ctx := c.Request.Context()
+	err := h.GoalSvc.DeleteGoal(ctx, userID, goalID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrGoalNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+func (h *GoalHandler) GetGoalsProgress(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	var query models.GetGoalsProgressQuery
+	if validationErrors := middleware.BindQuery(c, validate.(*validator.Validate), &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	progress, err := h.GoalSvc.GetGoalsProgress(ctx, userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get goals progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type HeartRateHandler struct {
+	Engine       *gin.Engine
+	AppConfig    configs.Config
+	HeartRateSvc service.HeartRateService
+	IdemSvc      service.IdempotencyService
+	validator    *validator.Validate
+}
+
+func NewHeartRateHandler(engine *gin.Engine, appConfig configs.Config, heartRateService service.HeartRateService, idempotencyService service.IdempotencyService) *HeartRateHandler {
+	return &HeartRateHandler{
+		Engine:       engine,
+		AppConfig:    appConfig,
+		HeartRateSvc: heartRateService,
+		IdemSvc:      idempotencyService,
+		validator:    validator.New(),
+	}
+}
+
+func (h *HeartRateHandler) SetupRoutes() {
+	// The heart rate of an activity is a part of it
+	activityRoutes := h.Engine.Group("/v1/activity")
+	activityRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	activityRoutes.Use(middleware.Idempotency(h.IdemSvc))
+	activityRoutes.Use(middleware.ContentTypeMiddleware())
+	activityRoutes.Use(middleware.ValidationMiddleware())
+
+	activityRoutes.GET("/:activityId/heart-rate", h.GetHeartRate)
+	activityRoutes.PUT("/:activityId/heart-rate",
+		middleware.ValidateJSONForNulls([]string{"intervalSeconds", "samples"}),
+		h.SetHeartRate)
+	activityRoutes.DELETE("/:activityId/heart-rate", h.DeleteHeartRate)
+	activityRoutes.GET("/:activityId/heart-rate/zones", h.GetActivityZones)
+
+	analyticsRoutes := h.Engine.Group("/v1/analytics")
+	analyticsRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+
+	analyticsRoutes.GET("/heart-rate-zones", h.GetWeeklyZones)
+}
+
+func (h *HeartRateHandler) SetHeartRate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	var req models.SetHeartRateRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	series, err := h.HeartRateSvc.SetHeartRate(ctx, userID, activityID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrTooFewHeartRateSamples) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if respondHeartRateError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save heart rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *HeartRateHandler) GetHeartRate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	var query models.GetHeartRateQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	series, err := h.HeartRateSvc.GetHeartRate(ctx, userID, activityID, query)
+	if err != nil {
+		if respondHeartRateError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get heart rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *HeartRateHandler) DeleteHeartRate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	err := h.HeartRateSvc.DeleteHeartRate(ctx, userID, activityID)
+	if err != nil {
+		if respondHeartRateError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete heart rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Heart rate deleted successfully"})
+}
+
+func (h *HeartRateHandler) GetActivityZones(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	zones, err := h.HeartRateSvc.GetActivityZones(ctx, userID, activityID)
+	if err != nil {
+		if respondHeartRateError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get heart rate zones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, zones)
+}
+
+func (h *HeartRateHandler) GetWeeklyZones(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetWeeklyHeartRateZonesQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	zones, err := h.HeartRateSvc.GetWeeklyZones(ctx, userID, query)
+	if err != nil {
+		if respondHeartRateError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get heart rate zones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, zones)
+}
+
+// respondHeartRateError writes the response for a missing activity, heart rate or maximum
+// heart rate, reporting whether it did
+func respondHeartRateError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, customErrors.ErrActivityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+	case errors.Is(err, customErrors.ErrHeartRateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Heart rate not found"})
+	case errors.Is(err, customErrors.ErrorUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, customErrors.ErrMaxHeartRateUnknown):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"FitByte/pkg/rrule"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PlannedWorkoutHandler struct {
+	Engine            *gin.Engine
+	AppConfig         configs.Config
+	PlannedWorkoutSvc service.PlannedWorkoutService
+	validator         *validator.Validate
+}
+
+func NewPlannedWorkoutHandler(engine *gin.Engine, appConfig configs.Config, plannedWorkoutService service.PlannedWorkoutService) *PlannedWorkoutHandler {
+	return &PlannedWorkoutHandler{
+		Engine:            engine,
+		AppConfig:         appConfig,
+		PlannedWorkoutSvc: plannedWorkoutService,
+		validator:         validator.New(),
+	}
+}
+
+func (h *PlannedWorkoutHandler) SetupRoutes() {
+	protectedRoutes := h.Engine.Group("/v1/planned-workouts")
+	protectedRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	protectedRoutes.Use(middleware.ContentTypeMiddleware())
+	protectedRoutes.Use(middleware.ValidationMiddleware())
+
+	protectedRoutes.POST("",
+		middleware.ValidateJSONForNulls([]string{"activityType", "date", "targetDurationInMinutes"}),
+		h.CreatePlannedWorkout)
+	protectedRoutes.GET("", h.GetPlannedWorkouts)
+	protectedRoutes.GET("/:plannedWorkoutId", h.GetPlannedWorkout)
+	protectedRoutes.PATCH("/:plannedWorkoutId",
+		middleware.ValidateJSONForNulls([]string{"activityType", "date", "targetDurationInMinutes", "targetCalories"}),
+		h.UpdatePlannedWorkout)
+	protectedRoutes.DELETE("/:plannedWorkoutId", h.DeletePlannedWorkout)
+
+	calendarRoutes := h.Engine.Group("/v1/calendar")
+	calendarRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	calendarRoutes.GET("", h.GetCalendar)
+}
+
+func (h *PlannedWorkoutHandler) CreatePlannedWorkout(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var req models.CreatePlannedWorkoutRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.PlannedWorkoutSvc.CreatePlannedWorkout(ctx, userID, req)
+	if err != nil {
+		if errors.Is(err, rrule.ErrInvalidRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create planned workout"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *PlannedWorkoutHandler) GetPlannedWorkouts(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	ctx := c.Request.Context()
+	workouts, err := h.PlannedWorkoutSvc.GetPlannedWorkouts(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get planned workouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workouts)
+}
+
+func (h *PlannedWorkoutHandler) GetPlannedWorkout(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	plannedWorkoutID := c.Param("plannedWorkoutId")
+
+	ctx := c.Request.Context()
+	workout, err := h.PlannedWorkoutSvc.GetPlannedWorkout(ctx, userID, plannedWorkoutID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrPlannedWorkoutNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Planned workout not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get planned workout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workout)
+}
+
+func (h *PlannedWorkoutHandler) UpdatePlannedWorkout(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	plannedWorkoutID := c.Param("plannedWorkoutId")
+
+	var req models.UpdatePlannedWorkoutRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.PlannedWorkoutSvc.UpdatePlannedWorkout(ctx, userID, plannedWorkoutID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrPlannedWorkoutNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Planned workout not found"})
+			return
+		}
+		if errors.Is(err, rrule.ErrInvalidRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update planned workout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PlannedWorkoutHandler) DeletePlannedWorkout(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	plannedWorkoutID := c.Param("plannedWorkoutId")
+
+	ctx := c.Request.Context()
+	err := h.PlannedWorkoutSvc.DeletePlannedWorkout(ctx, userID, plannedWorkoutID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrPlannedWorkoutNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Planned workout not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete planned workout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Planned workout deleted successfully"})
+}
+
+func (h *PlannedWorkoutHandler) GetCalendar(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetCalendarQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	days, err := h.PlannedWorkoutSvc.GetCalendar(ctx, userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, days)
+}
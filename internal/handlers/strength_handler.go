@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type StrengthHandler struct {
+	Engine      *gin.Engine
+	AppConfig   configs.Config
+	StrengthSvc service.StrengthService
+	IdemSvc     service.IdempotencyService
+	validator   *validator.Validate
+}
+
+func NewStrengthHandler(engine *gin.Engine, appConfig configs.Config, strengthService service.StrengthService, idempotencyService service.IdempotencyService) *StrengthHandler {
+	return &StrengthHandler{
+		Engine:      engine,
+		AppConfig:   appConfig,
+		StrengthSvc: strengthService,
+		IdemSvc:     idempotencyService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *StrengthHandler) SetupRoutes() {
+	exerciseRoutes := h.Engine.Group("/v1/exercises")
+	exerciseRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+
+	exerciseRoutes.GET("", h.GetExercises)
+	exerciseRoutes.GET("/:exerciseId/history", h.GetExerciseHistory)
+
+	// The sets of a strength session are a part of its activity
+	sessionRoutes := h.Engine.Group("/v1/activity")
+	sessionRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	sessionRoutes.Use(middleware.Idempotency(h.IdemSvc))
+	sessionRoutes.Use(middleware.ContentTypeMiddleware())
+	sessionRoutes.Use(middleware.ValidationMiddleware())
+
+	sessionRoutes.GET("/:activityId/exercises", h.GetSession)
+	sessionRoutes.PUT("/:activityId/exercises",
+		middleware.ValidateJSONForNulls([]string{"exercises"}),
+		h.SetSessionExercises)
+}
+
+func (h *StrengthHandler) GetExercises(c *gin.Context) {
+	c.JSON(http.StatusOK, h.StrengthSvc.GetExercises())
+}
+
+func (h *StrengthHandler) GetExerciseHistory(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetExerciseHistoryQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	history, err := h.StrengthSvc.GetExerciseHistory(ctx, userID, c.Param("exerciseId"), query)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrExerciseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get exercise history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *StrengthHandler) GetSession(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	session, err := h.StrengthSvc.GetSession(ctx, userID, activityID)
+	if err != nil {
+		if respondStrengthActivityError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get strength session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *StrengthHandler) SetSessionExercises(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	var req models.SetStrengthExercisesRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	session, err := h.StrengthSvc.SetSessionExercises(ctx, userID, activityID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrExerciseNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if respondStrengthActivityError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save strength session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// respondStrengthActivityError writes the response for an activity that is missing or is
+// not a strength session, reporting whether it did
+func respondStrengthActivityError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, customErrors.ErrActivityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+	case errors.Is(err, customErrors.ErrNotStrengthActivity):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
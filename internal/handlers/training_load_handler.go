@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TrainingLoadHandler struct {
+	Engine          *gin.Engine
+	AppConfig       configs.Config
+	TrainingLoadSvc service.TrainingLoadService
+	validator       *validator.Validate
+}
+
+func NewTrainingLoadHandler(engine *gin.Engine, appConfig configs.Config, trainingLoadService service.TrainingLoadService) *TrainingLoadHandler {
+	return &TrainingLoadHandler{
+		Engine:          engine,
+		AppConfig:       appConfig,
+		TrainingLoadSvc: trainingLoadService,
+		validator:       validator.New(),
+	}
+}
+
+func (h *TrainingLoadHandler) SetupRoutes() {
+	analyticsRoutes := h.Engine.Group("/v1/analytics")
+	analyticsRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+
+	analyticsRoutes.GET("/load", h.GetLoad)
+
+	activityRoutes := h.Engine.Group("/v1/activity")
+	activityRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+
+	activityRoutes.GET("/:activityId/load", h.GetActivityLoad)
+}
+
+func (h *TrainingLoadHandler) GetLoad(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var query models.GetTrainingLoadQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	load, err := h.TrainingLoadSvc.GetLoad(ctx, userID, query)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrorUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get training load"})
+		return
+	}
+
+	c.JSON(http.StatusOK, load)
+}
+
+func (h *TrainingLoadHandler) GetActivityLoad(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	activityID := c.Param("activityId")
+
+	ctx := c.Request.Context()
+	load, err := h.TrainingLoadSvc.GetActivityLoad(ctx, userID, activityID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrActivityNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrorUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity training load"})
+		return
+	}
+
+	c.JSON(http.StatusOK, load)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TrainingProgramHandler struct {
+	Engine     *gin.Engine
+	AppConfig  configs.Config
+	ProgramSvc service.TrainingProgramService
+	validator  *validator.Validate
+}
+
+func NewTrainingProgramHandler(engine *gin.Engine, appConfig configs.Config, programService service.TrainingProgramService) *TrainingProgramHandler {
+	return &TrainingProgramHandler{
+		Engine:     engine,
+		AppConfig:  appConfig,
+		ProgramSvc: programService,
+		validator:  validator.New(),
+	}
+}
+
+func (h *TrainingProgramHandler) SetupRoutes() {
+	programRoutes := h.Engine.Group("/v1/programs")
+	programRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	programRoutes.Use(middleware.ContentTypeMiddleware())
+	programRoutes.Use(middleware.ValidationMiddleware())
+
+	programRoutes.GET("", h.GetPrograms)
+	programRoutes.GET("/:programId", h.GetProgram)
+	programRoutes.POST("/:programId/enroll",
+		middleware.ValidateJSONForNulls([]string{"startDate"}),
+		h.Enroll)
+
+	enrollmentRoutes := h.Engine.Group("/v1/enrollments")
+	enrollmentRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+
+	enrollmentRoutes.GET("", h.GetEnrollments)
+	enrollmentRoutes.GET("/:enrollmentId", h.GetEnrollment)
+	enrollmentRoutes.DELETE("/:enrollmentId", h.CancelEnrollment)
+}
+
+func (h *TrainingProgramHandler) GetPrograms(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ProgramSvc.GetPrograms())
+}
+
+func (h *TrainingProgramHandler) GetProgram(c *gin.Context) {
+	var query models.GetTrainingProgramQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &query); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	program, err := h.ProgramSvc.GetProgram(c.Param("programId"), query.Version)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrProgramNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Training program not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get training program"})
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+func (h *TrainingProgramHandler) Enroll(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	programID := c.Param("programId")
+
+	var req models.EnrollProgramRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.ProgramSvc.Enroll(ctx, userID, programID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrProgramNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Training program not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrStartDateInPast) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, customErrors.ErrAlreadyEnrolled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll in training program"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *TrainingProgramHandler) GetEnrollments(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	ctx := c.Request.Context()
+	enrollments, err := h.ProgramSvc.GetEnrollments(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get program enrollments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollments)
+}
+
+func (h *TrainingProgramHandler) GetEnrollment(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	enrollmentID := c.Param("enrollmentId")
+
+	ctx := c.Request.Context()
+	enrollment, err := h.ProgramSvc.GetEnrollment(ctx, userID, enrollmentID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrEnrollmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Program enrollment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get program enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *TrainingProgramHandler) CancelEnrollment(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	enrollmentID := c.Param("enrollmentId")
+
+	ctx := c.Request.Context()
+	err := h.ProgramSvc.CancelEnrollment(ctx, userID, enrollmentID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrEnrollmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Program enrollment not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrEnrollmentNotActive) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel program enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Program enrollment cancelled successfully"})
+}
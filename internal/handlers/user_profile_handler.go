@@ -7,8 +7,11 @@ import (
 	"FitByte/internal/service"
 
 	// "context"
+	"bytes"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -20,13 +23,15 @@ type ProfileHandler struct {
 	Engine     *gin.Engine
 	AppConfig  configs.Config
 	ProfileSvc service.ProfileService
+	IdemSvc    service.IdempotencyService
 }
 
-func NewProfileHandler(engine *gin.Engine, appConfig configs.Config, profileService service.ProfileService) *ProfileHandler {
+func NewProfileHandler(engine *gin.Engine, appConfig configs.Config, profileService service.ProfileService, idempotencyService service.IdempotencyService) *ProfileHandler {
 	return &ProfileHandler{
 		Engine:     engine,
 		AppConfig:  appConfig,
 		ProfileSvc: profileService,
+		IdemSvc:    idempotencyService,
 	}
 }
 
@@ -45,6 +50,7 @@ func (h *ProfileHandler) SetupRoutes() {
 	protectedRoutes.Use(middleware.ContentTypeMiddleware())
 	protectedRoutes.Use(middleware.ValidationMiddleware())
 	protectedRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	protectedRoutes.Use(middleware.Idempotency(h.IdemSvc))
 	protectedRoutes.GET("/user", h.GetProfile)
 	protectedRoutes.PATCH("/user", h.UpdateProfile)
 
@@ -158,6 +164,10 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
+	if middleware.NotModified(c, middleware.VersionETag(profile.Version)) {
+		return
+	}
+
 	response := gin.H{
 		"email": profile.Email,
 	}
@@ -204,6 +214,10 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 		response["imageUri"] = profile.ImageURI
 	}
 
+	setUnitSettings(response, profile)
+	setHeartRateSettings(response, profile)
+	setAccountSettings(response, profile)
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -218,7 +232,17 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 
 	var req models.PatchProfileRequest
 
-	err := c.ShouldBindJSON(&req)
+	// The heart rate settings are cleared by an explicit null, which binding cannot tell
+	// apart from leaving them out
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	nulls := middleware.ExplicitNulls(body, []string{"maxHeartRate", "birthDate", "restingHeartRate"})
+
+	err = c.ShouldBindJSON(&req)
 	if middleware.HandleValidationError(c, err) {
 		return
 	}
@@ -243,13 +267,50 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 		"name":        req.Name,
 		"image_uri":   req.ImageURI,
 	}
+	if req.PreferredUnits != nil {
+		updates["preferred_units"] = *req.PreferredUnits
+	}
+	if req.MaxHeartRate != nil {
+		updates["max_heart_rate"] = *req.MaxHeartRate
+	} else if nulls["maxHeartRate"] {
+		updates["max_heart_rate"] = nil
+	}
+	if req.RestingHeartRate != nil {
+		updates["resting_heart_rate"] = *req.RestingHeartRate
+	} else if nulls["restingHeartRate"] {
+		updates["resting_heart_rate"] = nil
+	}
+	if req.BirthDate != nil {
+		birthDate, _ := time.Parse(models.DateLayout, *req.BirthDate)
+		if birthDate.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": map[string]string{"birthDate": "birthDate must not be in the future"}})
+			return
+		}
+		updates["birth_date"] = birthDate
+	} else if nulls["birthDate"] {
+		updates["birth_date"] = nil
+	}
+	if req.IsPrivate != nil {
+		updates["is_private"] = *req.IsPrivate
+	}
+
+	expectedVersion, ok := middleware.IfMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match must be a single ETag of the profile"})
+		return
+	}
 
 	ctx := c.Request.Context()
-	if err := h.ProfileSvc.UpdateUserProfile(ctx, userID, updates); err != nil {
+	profile, err := h.ProfileSvc.UpdateUserProfile(ctx, userID, updates, expectedVersion)
+	if err != nil {
 		if errors.Is(err, customErrors.ErrorUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
+		if errors.Is(err, customErrors.ErrVersionMismatch) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Profile has been modified since it was read"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
@@ -263,6 +324,39 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 		"name":       req.Name,
 		"imageUri":   req.ImageURI,
 	}
+	setUnitSettings(response, profile)
+	setHeartRateSettings(response, profile)
+	setAccountSettings(response, profile)
 
+	c.Header("ETag", middleware.VersionETag(profile.Version))
 	c.JSON(http.StatusOK, response)
 }
+
+// setUnitSettings adds the units the user picked and the ones distances are shown in to
+// a response
+func setUnitSettings(response gin.H, profile *models.Profile) {
+	if profile.PreferredUnits == "" {
+		response["preferredUnits"] = nil
+	} else {
+		response["preferredUnits"] = profile.PreferredUnits
+	}
+	response["units"] = models.UnitSystemForProfile(profile)
+}
+
+// setHeartRateSettings adds the profile's heart rate zone settings to a response
+func setHeartRateSettings(response gin.H, profile *models.Profile) {
+	response["maxHeartRate"] = profile.MaxHeartRate
+	response["restingHeartRate"] = profile.RestingHeartRate
+	if profile.BirthDate == nil {
+		response["birthDate"] = nil
+	} else {
+		response["birthDate"] = profile.BirthDate.Format(models.DateLayout)
+	}
+}
+
+// setAccountSettings adds the ID others follow the user by and whether following needs
+// the user's approval to a response
+func setAccountSettings(response gin.H, profile *models.Profile) {
+	response["userId"] = profile.ID
+	response["isPrivate"] = profile.IsPrivate
+}
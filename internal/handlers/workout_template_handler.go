@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"FitByte/configs"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/middleware"
+	"FitByte/internal/models"
+	"FitByte/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type WorkoutTemplateHandler struct {
+	Engine      *gin.Engine
+	AppConfig   configs.Config
+	TemplateSvc service.WorkoutTemplateService
+	IdemSvc     service.IdempotencyService
+	validator   *validator.Validate
+}
+
+func NewWorkoutTemplateHandler(engine *gin.Engine, appConfig configs.Config, templateService service.WorkoutTemplateService, idempotencyService service.IdempotencyService) *WorkoutTemplateHandler {
+	return &WorkoutTemplateHandler{
+		Engine:      engine,
+		AppConfig:   appConfig,
+		TemplateSvc: templateService,
+		IdemSvc:     idempotencyService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *WorkoutTemplateHandler) SetupRoutes() {
+	protectedRoutes := h.Engine.Group("/v1/workout-templates")
+	protectedRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	protectedRoutes.Use(middleware.ContentTypeMiddleware())
+	protectedRoutes.Use(middleware.ValidationMiddleware())
+
+	protectedRoutes.POST("",
+		middleware.ValidateJSONForNulls([]string{"name", "activityType", "durationInMinutes"}),
+		h.CreateTemplate)
+	protectedRoutes.GET("", h.GetTemplates)
+	protectedRoutes.GET("/:templateId", h.GetTemplate)
+	protectedRoutes.PATCH("/:templateId",
+		middleware.ValidateJSONForNulls([]string{"name", "activityType", "durationInMinutes", "exercises"}),
+		h.UpdateTemplate)
+	protectedRoutes.DELETE("/:templateId", h.DeleteTemplate)
+
+	// The overrides are optional, so an empty body without a Content-Type is accepted
+	activityRoutes := h.Engine.Group("/v1/activity/from-template")
+	activityRoutes.Use(middleware.AuthMiddleware(h.AppConfig.Secret.JWTSecret))
+	activityRoutes.Use(middleware.Idempotency(h.IdemSvc))
+	activityRoutes.POST("/:templateId", h.CreateActivityFromTemplate)
+}
+
+func (h *WorkoutTemplateHandler) CreateTemplate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	var req models.CreateWorkoutTemplateRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	req.Normalize()
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.TemplateSvc.CreateTemplate(ctx, userID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrTemplateNameTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workout template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *WorkoutTemplateHandler) GetTemplates(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+
+	ctx := c.Request.Context()
+	templates, err := h.TemplateSvc.GetTemplates(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workout templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func (h *WorkoutTemplateHandler) GetTemplate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	templateID := c.Param("templateId")
+
+	ctx := c.Request.Context()
+	template, err := h.TemplateSvc.GetTemplate(ctx, userID, templateID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workout template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workout template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *WorkoutTemplateHandler) UpdateTemplate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	templateID := c.Param("templateId")
+
+	var req models.UpdateWorkoutTemplateRequest
+
+	err := c.ShouldBindJSON(&req)
+	if middleware.HandleValidationError(c, err) {
+		return
+	}
+
+	validate, exists := c.Get("validator")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Validation service unavailable"})
+		return
+	}
+
+	req.Normalize()
+	if validationErrors := middleware.ValidateStruct(validate.(*validator.Validate), req); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	if req.Name != nil && *req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty string"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.TemplateSvc.UpdateTemplate(ctx, userID, templateID, req)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workout template not found"})
+			return
+		}
+		if errors.Is(err, customErrors.ErrTemplateNameTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workout template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WorkoutTemplateHandler) DeleteTemplate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	templateID := c.Param("templateId")
+
+	ctx := c.Request.Context()
+	err := h.TemplateSvc.DeleteTemplate(ctx, userID, templateID)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workout template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete workout template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workout template deleted successfully"})
+}
+
+func (h *WorkoutTemplateHandler) CreateActivityFromTemplate(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID := uint(userIDInterface.(int64))
+	templateID := c.Param("templateId")
+
+	var req models.CreateActivityFromTemplateRequest
+	if c.Request.ContentLength != 0 {
+		err := c.ShouldBindJSON(&req)
+		if middleware.HandleValidationError(c, err) {
+			return
+		}
+
+		if validationErrors := middleware.ValidateStruct(h.validator, req); validationErrors != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+			return
+		}
+	}
+
+	var conflictQuery models.ActivityConflictQuery
+	if validationErrors := middleware.BindQuery(c, h.validator, &conflictQuery); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.TemplateSvc.CreateActivityFromTemplate(ctx, userID, templateID, req, conflictQuery.ConflictPolicy)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workout template not found"})
+			return
+		}
+		if respondActivityOverlap(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activity"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,31 @@
+package infrastructure
+
+import (
+	"FitByte/configs"
+	"FitByte/pkg/log"
+	"FitByte/pkg/strength"
+	"os"
+)
+
+const defaultExercisesFile = "./files/exercises.yaml"
+
+// InitExerciseCatalog loads the exercise catalog that strength sessions are logged against
+func InitExerciseCatalog(appConfig configs.Config) *strength.Catalog {
+	file := appConfig.Exercises.File
+	if file == "" {
+		file = defaultExercisesFile
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Str("file", file).Msg("Failed to read exercise catalog")
+	}
+
+	catalog, err := strength.ParseCatalog(data)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Str("file", file).Msg("Failed to load exercise catalog")
+	}
+
+	log.Logger.Info().Int("exercises", len(catalog.All())).Msg("Exercise catalog loaded successfully")
+	return catalog
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"FitByte/configs"
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"FitByte/pkg/trainingplan"
+	"os"
+)
+
+const defaultProgramsDir = "./files/programs"
+
+// InitTrainingPrograms loads the training program catalog and checks that every session
+// is an activity type the application knows
+func InitTrainingPrograms(appConfig configs.Config) *trainingplan.Catalog {
+	dir := appConfig.Programs.Dir
+	if dir == "" {
+		dir = defaultProgramsDir
+	}
+
+	catalog, err := trainingplan.LoadCatalog(os.DirFS(dir))
+	if err != nil {
+		log.Logger.Fatal().Err(err).Str("dir", dir).Msg("Failed to load training programs")
+	}
+
+	programs := catalog.All()
+	for _, program := range programs {
+		for i, week := range program.Weeks {
+			for _, session := range week.Sessions {
+				if _, ok := models.ActivityTypeCalories[session.ActivityType]; !ok {
+					log.Logger.Fatal().
+						Str("programId", program.ID).
+						Int("version", program.Version).
+						Int("week", i+1).
+						Str("activityType", session.ActivityType).
+						Msg("Training program uses an unknown activity type")
+				}
+			}
+		}
+	}
+
+	log.Logger.Info().Int("programs", len(programs)).Msg("Training programs loaded successfully")
+	return catalog
+}
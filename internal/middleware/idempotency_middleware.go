@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxInMemoryIdempotentBody = 1 << 20
+)
+
+// IdempotencyStore claims idempotency keys and records the responses sent for them.
+// It is implemented by service.IdempotencyService.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, userID uint, key string, requestHash string) (*models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, id uint, status int, contentType string, body []byte) error
+	Release(ctx context.Context, id uint) error
+}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests carrying an Idempotency-Key
+// header safe to retry. The first request with a key runs normally and its response is
+// stored; a retry with the same key and request replays that response, while a retry
+// with a different request is rejected. Server errors are not stored, so they can be
+// retried with the same key. It must run after AuthMiddleware because keys are per user.
+func Idempotency(idempotencyService IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch && method != http.MethodDelete {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		userIDInterface, exists := c.Get("user_id")
+		if !exists {
+			c.Next()
+			return
+		}
+		userID := uint(userIDInterface.(int64))
+
+		requestHash, cleanup, err := hashRequest(c)
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to read request for idempotency check")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		defer cleanup()
+
+		ctx := c.Request.Context()
+		claim, replay, err := idempotencyService.Begin(ctx, userID, key, requestHash)
+		if err != nil {
+			switch {
+			case errors.Is(err, customErrors.ErrIdempotencyKeyReused):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, customErrors.ErrIdempotencyKeyInProgress):
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check idempotency key"})
+			}
+			return
+		}
+
+		if replay {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(claim.ResponseStatus, claim.ResponseContentType, claim.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// Storing the outcome must not depend on the request deadline, which may have passed
+		storeCtx := context.WithoutCancel(ctx)
+
+		defer func() {
+			if r := recover(); r != nil {
+				if err := idempotencyService.Release(storeCtx, claim.ID); err != nil {
+					log.Logger.Error().Err(err).Msg("Failed to release idempotency key")
+				}
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			err = idempotencyService.Release(storeCtx, claim.ID)
+		} else {
+			err = idempotencyService.Complete(storeCtx, claim.ID, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			log.Logger.Error().Err(err).Str("idempotencyKey", key).Msg("Failed to store idempotent response")
+		}
+	}
+}
+
+// hashRequest fingerprints the method, URL and body of a request and restores the body
+// for the handler. Large bodies such as file uploads are spooled to a temporary file
+// instead of being held in memory; the returned cleanup removes it.
+func hashRequest(c *gin.Context) (string, func(), error) {
+	hash := sha256.New()
+	io.WriteString(hash, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+
+	noop := func() {}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return hex.EncodeToString(hash.Sum(nil)), noop, nil
+	}
+
+	if c.Request.ContentLength >= 0 && c.Request.ContentLength <= maxInMemoryIdempotentBody {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", noop, err
+		}
+		hash.Write(body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		return hex.EncodeToString(hash.Sum(nil)), noop, nil
+	}
+
+	spool, err := os.CreateTemp("", "idempotent-body-*")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	if _, err := io.Copy(io.MultiWriter(spool, hash), c.Request.Body); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+	c.Request.Body = spool
+
+	return hex.EncodeToString(hash.Sum(nil)), cleanup, nil
+}
+
+// responseRecorder keeps a copy of the response body while writing it to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// QueryValidator is implemented by query structs with rules that span several
+// parameters, such as a range whose minimum must not exceed its maximum
+type QueryValidator interface {
+	ValidateQuery() map[string]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// BindQuery parses the request's query string into the struct pointed to by dst using
+// its `form` tags, then checks its `validate` tags and, when dst implements
+// QueryValidator, its cross-parameter rules. Unlike gin's binding, a value that cannot
+// be parsed is reported instead of ignored. It returns errors keyed by query parameter,
+// or nil when the query is valid.
+//
+// Supported field types are string, int, float64, bool, time.Time (RFC3339) and
+// []string, which accepts both repeated parameters and comma-separated lists.
+func BindQuery(c *gin.Context, validate *validator.Validate, dst interface{}) map[string]string {
+	errors := make(map[string]string)
+
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	params := c.Request.URL.Query()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("form")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		values, exists := params[name]
+		if !exists || len(values) == 0 {
+			continue
+		}
+
+		if err := setQueryField(v.Field(i), values); err != nil {
+			errors[name] = name + " " + err.Error()
+		}
+	}
+
+	// Fields that failed to parse are left at their zero value, so validating them would only add noise
+	if len(errors) > 0 {
+		return errors
+	}
+
+	if err := validate.Struct(dst); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return map[string]string{"query": "query is invalid"}
+		}
+		for _, fieldErr := range validationErrors {
+			name := queryParamName(t, fieldErr)
+			errors[name] = name + " " + queryValidationMessage(fieldErr)
+		}
+		return errors
+	}
+
+	if queryValidator, ok := dst.(QueryValidator); ok {
+		if crossErrors := queryValidator.ValidateQuery(); len(crossErrors) > 0 {
+			return crossErrors
+		}
+	}
+
+	return nil
+}
+
+func setQueryField(field reflect.Value, values []string) error {
+	value := strings.TrimSpace(values[len(values)-1])
+
+	if field.Type() == timeType {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("must be an RFC3339 date such as 2024-01-31T07:00:00Z")
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		field.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		field.SetBool(parsed)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("is not supported")
+		}
+		var items []string
+		for _, v := range values {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			slice.Index(i).SetString(item)
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("is not supported")
+	}
+	return nil
+}
+
+// queryParamName returns the query parameter a validation error belongs to
+func queryParamName(t reflect.Type, fieldErr validator.FieldError) string {
+	// Errors on slice elements are reported against the slice itself
+	structField := fieldErr.StructField()
+	if i := strings.IndexByte(structField, '['); i != -1 {
+		structField = structField[:i]
+	}
+	if field, ok := t.FieldByName(structField); ok {
+		if name := field.Tag.Get("form"); name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(fieldErr.Field())
+}
+
+func queryValidationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "min":
+		if fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice {
+			return "must have at least " + fieldErr.Param() + " characters or items"
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at most " + fieldErr.Param() + " characters"
+		}
+		if fieldErr.Kind() == reflect.Slice {
+			return "must have at most " + fieldErr.Param() + " items"
+		}
+		return "must be at most " + fieldErr.Param()
+	case "oneof":
+		return "must be one of: " + fieldErr.Param()
+	case "required":
+		return "is required"
+	case "datetime":
+		return "must be a date formatted as " + fieldErr.Param()
+	case "timezone":
+		return "must be a time zone such as Europe/Berlin"
+	default:
+		return "is invalid"
+	}
+}
@@ -81,6 +81,8 @@ func ValidateStruct(validate *validator.Validate, s interface{}) map[string]stri
 			errors[field] = field + " must be one of: " + err.Param()
 		case "url", "uri":
 			errors[field] = field + " must be a valid URL"
+		case "datetime":
+			errors[field] = field + " must be a date formatted as " + err.Param()
 		default:
 			errors[field] = field + " is invalid"
 		}
@@ -139,6 +141,25 @@ func CheckForExplicitNulls(body []byte, fields []string) error {
 	return nil
 }
 
+// ExplicitNulls returns which of the fields the JSON body sets to null, telling them
+// apart from fields that are left out
+func ExplicitNulls(body []byte, fields []string) map[string]bool {
+	nulls := make(map[string]bool)
+
+	var rawJSON map[string]interface{}
+	if err := json.Unmarshal(body, &rawJSON); err != nil {
+		return nulls
+	}
+
+	for _, field := range fields {
+		if value, exists := rawJSON[field]; exists && value == nil {
+			nulls[field] = true
+		}
+	}
+
+	return nulls
+}
+
 type ValidationError struct {
 	Field   string
 	Message string
@@ -2,6 +2,7 @@ package models
 
 
 import (
+   "strings"
    "time"
 
 
@@ -12,50 +13,220 @@ import (
 // Activity represents the activity entity in the database
 type Activity struct {
    gorm.Model
-   ActivityID          string    `json:"activityId" gorm:"uniqueIndex;not null"`
-   UserID              uint      `json:"-" gorm:"not null;index"`
-   ActivityType        string    `json:"activityType" gorm:"not null" validate:"required,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope"`
-   DoneAt              time.Time `json:"doneAt" gorm:"not null" validate:"required"`
-   DurationInMinutes   int       `json:"durationInMinutes" gorm:"not null" validate:"required,min=1"`
-   CaloriesBurned      int       `json:"caloriesBurned" gorm:"not null"`
+   ActivityID          string      `json:"activityId" gorm:"uniqueIndex;not null"`
+   UserID              uint        `json:"-" gorm:"not null;index"`
+   ActivityType        string      `json:"activityType" gorm:"not null" validate:"required,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+   DoneAt              time.Time   `json:"doneAt" gorm:"not null" validate:"required"`
+   DurationInMinutes   int         `json:"durationInMinutes" gorm:"not null" validate:"required,min=1"`
+   CaloriesBurned      int         `json:"caloriesBurned" gorm:"not null"`
+   DistanceMeters      *float64    `json:"distanceMeters"`
+   ElevationGainMeters *float64    `json:"elevationGainMeters"`
+   PoolLengthMeters    *float64    `json:"poolLengthMeters"`
+   Notes               *string     `json:"notes"`
+   Tags                StringArray `json:"tags" gorm:"type:text[];not null;default:'{}'"`
+   FileID              *uint       `json:"-" gorm:"index"`
+   SourceUUID          *string     `json:"-"`
+   Visibility          string      `json:"visibility" gorm:"not null;default:followers"`
+   Version             int         `json:"-" gorm:"not null;default:1"`
 }
 
 
 // CreateActivityRequest represents the request body for creating an activity
 type CreateActivityRequest struct {
-   ActivityType      string `json:"activityType" validate:"required,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope"`
-   DoneAt            string `json:"doneAt" validate:"required"`
-   DurationInMinutes int    `json:"durationInMinutes" validate:"required,min=1"`
+   ActivityType        string   `json:"activityType" validate:"required,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+   DoneAt              string   `json:"doneAt" validate:"required"`
+   DurationInMinutes   int      `json:"durationInMinutes" validate:"required,min=1"`
+   DistanceMeters      *float64 `json:"distanceMeters,omitempty" validate:"omitempty,gt=0"`
+   ElevationGainMeters *float64 `json:"elevationGainMeters,omitempty" validate:"omitempty,min=0"`
+   PoolLengthMeters    *float64 `json:"poolLengthMeters,omitempty" validate:"omitempty,gt=0,max=100"`
+   Notes               *string  `json:"notes,omitempty" validate:"omitempty,max=2000"`
+   Tags                []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=32"`
+   Visibility          string   `json:"visibility,omitempty" validate:"omitempty,oneof=private followers public"`
+}
+
+
+
+// CreateActivitiesBatchRequest represents the request body for creating activities in bulk.
+// When AllOrNothing is set, no activity is created unless every item is valid.
+type CreateActivitiesBatchRequest struct {
+   Activities   []CreateActivityRequest `json:"activities"`
+   AllOrNothing bool                    `json:"allOrNothing"`
+}
+
+
+// BatchActivityResult represents the outcome of a single item in a batch create request
+type BatchActivityResult struct {
+	Index    int               `json:"index"`
+	Success  bool              `json:"success"`
+	Activity *ActivityResponse `json:"activity,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
 }
 
 
 // UpdateActivityRequest represents the request body for updating an activity
 type UpdateActivityRequest struct {
-   ActivityType      *string `json:"activityType,omitempty" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope"`
-   DoneAt            *string `json:"doneAt,omitempty"`
-   DurationInMinutes *int    `json:"durationInMinutes,omitempty" validate:"omitempty,min=1"`
+   ActivityType        *string   `json:"activityType,omitempty" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+   DoneAt              *string   `json:"doneAt,omitempty"`
+   DurationInMinutes   *int      `json:"durationInMinutes,omitempty" validate:"omitempty,min=1"`
+   DistanceMeters      *float64  `json:"distanceMeters,omitempty" validate:"omitempty,gt=0"`
+   ElevationGainMeters *float64  `json:"elevationGainMeters,omitempty" validate:"omitempty,min=0"`
+   PoolLengthMeters    *float64  `json:"poolLengthMeters,omitempty" validate:"omitempty,gt=0,max=100"`
+   Notes               *string   `json:"notes,omitempty" validate:"omitempty,max=2000"`
+   Tags                *[]string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=32"`
+   Visibility          *string   `json:"visibility,omitempty" validate:"omitempty,oneof=private followers public"`
 }
 
 
+
 // ActivityResponse represents the response format for activity operations
 type ActivityResponse struct {
-	ActivityID        string    `json:"activityId"`
-	ActivityType      string    `json:"activityType"`
-	DoneAt            string    `json:"doneAt"`
-	DurationInMinutes int       `json:"durationInMinutes"`
-	CaloriesBurned    int       `json:"caloriesBurned"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	ActivityID          string   `json:"activityId"`
+	ActivityType        string   `json:"activityType"`
+	DoneAt              string   `json:"doneAt"`
+	DurationInMinutes   int      `json:"durationInMinutes"`
+	CaloriesBurned      int      `json:"caloriesBurned"`
+	DistanceMeters      *float64 `json:"distanceMeters"`
+	ElevationGainMeters *float64 `json:"elevationGainMeters"`
+	PoolLengthMeters    *float64 `json:"poolLengthMeters"`
+	Notes               *string  `json:"notes"`
+	Tags                []string `json:"tags"`
+	Visibility          string   `json:"visibility"`
+	Version             int      `json:"version"`
+	// Distance, elevation, pace and speed in the user's preferred units
+	Distance      *float64  `json:"distance"`
+	DistanceUnit  *string   `json:"distanceUnit"`
+	ElevationGain *float64  `json:"elevationGain"`
+	ElevationUnit *string   `json:"elevationUnit"`
+	Pace          *float64  `json:"pace"`
+	PaceUnit      *string   `json:"paceUnit"`
+	Speed         *float64  `json:"speed"`
+	SpeedUnit     *string   `json:"speedUnit"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	// Activities this one overlaps, set when it was saved under the flag conflict policy
+	OverlapsWith []string `json:"overlapsWith,omitempty"`
+	// Uploaded files attached to the activity, set when the activity is read or updated
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
 }
 
+
+
+// GetActivitiesQuery holds the activity list filters. Tags only matches activities
+// carrying every listed tag and Search is a full-text query over notes and tags.
 type GetActivitiesQuery struct {
-   Limit              int       `form:"limit"`
-   Offset             int       `form:"offset"`
-   ActivityType       string    `form:"activityType" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope"`
-   DoneAtFrom         time.Time `form:"doneAtFrom"`
-   DoneAtTo           time.Time `form:"doneAtTo"`
-   CaloriesBurnedMin  int       `form:"caloriesBurnedMin"`
-   CaloriesBurnedMax  int       `form:"caloriesBurnedMax"`
+   Limit             int       `form:"limit" validate:"min=0,max=1000"`
+   Offset            int       `form:"offset" validate:"min=0"`
+   ActivityType      string    `form:"activityType" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+   DoneAtFrom        time.Time `form:"doneAtFrom"`
+   DoneAtTo          time.Time `form:"doneAtTo"`
+   CaloriesBurnedMin int       `form:"caloriesBurnedMin" validate:"min=0"`
+   CaloriesBurnedMax int       `form:"caloriesBurnedMax" validate:"min=0"`
+   DistanceMetersMin float64   `form:"distanceMetersMin" validate:"min=0"`
+   DistanceMetersMax float64   `form:"distanceMetersMax" validate:"min=0"`
+   Tags              []string  `form:"tags" validate:"max=20,dive,max=32"`
+   Search            string    `form:"q" validate:"max=200"`
+   Sort              string    `form:"sort" validate:"omitempty,oneof=doneAt -doneAt caloriesBurned -caloriesBurned durationInMinutes -durationInMinutes createdAt -createdAt"`
+   Cursor            string    `form:"cursor" validate:"max=512"`
+   IncludeTotal      bool      `form:"includeTotal"`
+   // Keyset position decoded from Cursor: rows strictly after (AfterValue, AfterID) in sort order
+   AfterValue interface{} `form:"-"`
+   AfterID    uint        `form:"-"`
+}
+
+
+// ValidateQuery checks that every range in the query has its lower bound first
+func (q GetActivitiesQuery) ValidateQuery() map[string]string {
+   errors := make(map[string]string)
+
+
+   if !q.DoneAtFrom.IsZero() && !q.DoneAtTo.IsZero() && q.DoneAtFrom.After(q.DoneAtTo) {
+       errors["doneAtFrom"] = "doneAtFrom must not be after doneAtTo"
+   }
+
+
+   if q.CaloriesBurnedMin > 0 && q.CaloriesBurnedMax > 0 && q.CaloriesBurnedMin > q.CaloriesBurnedMax {
+       errors["caloriesBurnedMin"] = "caloriesBurnedMin must not be greater than caloriesBurnedMax"
+   }
+
+
+   if q.DistanceMetersMin > 0 && q.DistanceMetersMax > 0 && q.DistanceMetersMin > q.DistanceMetersMax {
+       errors["distanceMetersMin"] = "distanceMetersMin must not be greater than distanceMetersMax"
+   }
+
+
+   return errors
+}
+
+
+// ActivitySortFields maps the sort keys accepted by the activity list to their columns
+var ActivitySortFields = map[string]string{
+   "doneAt":            "done_at",
+   "caloriesBurned":    "calories_burned",
+   "durationInMinutes": "duration_in_minutes",
+   "createdAt":         "created_at",
+}
+
+
+// DefaultActivitySort lists the most recent activities first
+const DefaultActivitySort = "-doneAt"
+
+
+// ParseActivitySort splits a sort parameter such as "-caloriesBurned" into its column
+// and direction. A leading "-" sorts descending.
+func ParseActivitySort(sort string) (column string, descending bool, ok bool) {
+   key := strings.TrimPrefix(sort, "-")
+   column, ok = ActivitySortFields[key]
+   return column, strings.HasPrefix(sort, "-"), ok
+}
+
+
+// ActivityCursor is the position of the last activity on a page, encoded into the
+// opaque cursor handed to clients. Sort pins the cursor to the order it was issued for.
+type ActivityCursor struct {
+   Sort  string `json:"s"`
+   Value string `json:"v"`
+   ID    uint   `json:"i"`
+}
+
+
+// ActivityPage is one page of the activity list
+type ActivityPage struct {
+   Activities []ActivityResponse
+   NextCursor *string
+   TotalCount *int64
+}
+
+
+// TagUsage represents how many of the user's activities carry a tag
+type TagUsage struct {
+   Tag   string `json:"tag"`
+   Count int    `json:"count"`
+}
+
+
+// NormalizeTags trims and lowercases tags and drops empty and repeated ones
+func NormalizeTags(tags []string) StringArray {
+   normalized := make(StringArray, 0, len(tags))
+   seen := make(map[string]bool, len(tags))
+   for _, tag := range tags {
+       tag = strings.ToLower(strings.TrimSpace(tag))
+       if tag == "" || seen[tag] {
+           continue
+       }
+       seen[tag] = true
+       normalized = append(normalized, tag)
+   }
+   return normalized
+}
+
+
+// EnduranceActivityTypes are the activity types that can record distance and elevation
+var EnduranceActivityTypes = map[string]bool{
+   "Running":  true,
+   "Cycling":  true,
+   "Hiking":   true,
+   "Walking":  true,
+   "Swimming": true,
 }
 
 
@@ -71,5 +242,58 @@ var ActivityTypeCalories = map[string]int{
    "Running":    10,
    "HIIT":       10,
    "JumpRope":   10,
+   // A moderate lifting session, rest between sets included
+   "StrengthTraining": 6,
 }
 
+
+// sportActivityTypes maps sport names used by GPS devices and export formats to activity types
+var sportActivityTypes = map[string]string{
+   "running":                       "Running",
+   "run":                           "Running",
+   "trail_run":                     "Running",
+   "biking":                        "Cycling",
+   "cycling":                       "Cycling",
+   "ride":                          "Cycling",
+   "hiking":                        "Hiking",
+   "hike":                          "Hiking",
+   "walking":                       "Walking",
+   "walk":                          "Walking",
+   "swimming":                      "Swimming",
+   "swim":                          "Swimming",
+   "yoga":                          "Yoga",
+   "dancing":                       "Dancing",
+   "dance":                         "Dancing",
+   "hiit":                          "HIIT",
+   "jump_rope":                     "JumpRope",
+   "stretching":                    "Stretching",
+   "flexibility":                   "Stretching",
+   "socialdance":                   "Dancing",
+   "cardiodance":                   "Dancing",
+   "jumprope":                      "JumpRope",
+   "highintensityintervaltraining": "HIIT",
+   "strength_training":             "StrengthTraining",
+   "strengthtraining":              "StrengthTraining",
+   "weight_training":               "StrengthTraining",
+   "traditionalstrengthtraining":   "StrengthTraining",
+   "functionalstrengthtraining":    "StrengthTraining",
+}
+
+
+// ActivityTypeFromSport resolves a device sport name to an activity type
+func ActivityTypeFromSport(sport string) (string, bool) {
+   activityType, exists := sportActivityTypes[strings.ToLower(strings.TrimSpace(sport))]
+   return activityType, exists
+}
+
+
+// ImportActivityResponse represents the response for an activity imported from a workout file
+type ImportActivityResponse struct {
+	Activity            ActivityResponse `json:"activity"`
+	FileURI             string           `json:"fileUri"`
+	StartTime           string           `json:"startTime"`
+	DurationInSeconds   int              `json:"durationInSeconds"`
+	DistanceMeters      float64          `json:"distanceMeters"`
+	ElevationGainMeters float64          `json:"elevationGainMeters"`
+	Trackpoints         int              `json:"trackpoints"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ActivityFile links an uploaded file to the activity it is attached to
+type ActivityFile struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	ActivityID string    `json:"-" gorm:"not null;index"`
+	FileID     uint      `json:"-" gorm:"not null;uniqueIndex"`
+	UserID     uint      `json:"-" gorm:"not null"`
+	CreatedAt  time.Time `json:"-"`
+}
+
+// ActivityAttachment is an attached file together with the upload it points to
+type ActivityAttachment struct {
+	ActivityID string
+	FileID     uint
+	FileName   string
+	FileURL    string
+	CreatedAt  time.Time
+}
+
+// AttachFilesRequest represents the request body for attaching uploaded files to an activity
+type AttachFilesRequest struct {
+	FileIDs []uint `json:"fileIds" validate:"required,min=1,max=10,dive,required,min=1"`
+}
+
+// AttachmentResponse represents a file attached to an activity
+type AttachmentResponse struct {
+	FileID     uint      `json:"fileId"`
+	FileName   string    `json:"fileName"`
+	URL        string    `json:"url"`
+	AttachedAt time.Time `json:"attachedAt"`
+}
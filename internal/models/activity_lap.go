@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// ActivityLap is a lap recorded by the device in an imported TCX or FIT file
+type ActivityLap struct {
+	ID                uint      `json:"-" gorm:"primarykey"`
+	ActivityID        string    `json:"activityId" gorm:"not null;index"`
+	LapIndex          int       `json:"lapIndex" gorm:"not null"`
+	StartTime         time.Time `json:"startTime" gorm:"not null"`
+	DurationInSeconds int       `json:"durationInSeconds" gorm:"not null"`
+	DistanceMeters    float64   `json:"distanceMeters" gorm:"not null"`
+	Calories          int       `json:"calories"`
+	AvgHeartRate      int       `json:"avgHeartRate"`
+	MaxHeartRate      int       `json:"maxHeartRate"`
+}
+
+// SplitResponse represents one fixed-distance split of an activity's GPS track.
+// The last split is usually shorter than the rest.
+type SplitResponse struct {
+	Split             int      `json:"split"`
+	DistanceMeters    float64  `json:"distanceMeters"`
+	Distance          float64  `json:"distance"`
+	DistanceUnit      string   `json:"distanceUnit"`
+	DurationInSeconds int      `json:"durationInSeconds"`
+	Pace              float64  `json:"pace"`
+	PaceUnit          string   `json:"paceUnit"`
+	ElevationChange   *float64 `json:"elevationChange"`
+	ElevationUnit     string   `json:"elevationUnit"`
+	AvgHeartRate      *int     `json:"avgHeartRate"`
+}
+
+// LapResponse represents a device-recorded lap
+type LapResponse struct {
+	Lap               int       `json:"lap"`
+	StartTime         time.Time `json:"startTime"`
+	DurationInSeconds int       `json:"durationInSeconds"`
+	DistanceMeters    float64   `json:"distanceMeters"`
+	Distance          float64   `json:"distance"`
+	DistanceUnit      string    `json:"distanceUnit"`
+	Pace              *float64  `json:"pace"`
+	PaceUnit          string    `json:"paceUnit"`
+	Calories          *int      `json:"calories"`
+	AvgHeartRate      *int      `json:"avgHeartRate"`
+	MaxHeartRate      *int      `json:"maxHeartRate"`
+}
+
+// ActivitySplitsResponse represents the splits computed from an activity's track and
+// the laps recorded by the device
+type ActivitySplitsResponse struct {
+	ActivityID string          `json:"activityId"`
+	Splits     []SplitResponse `json:"splits"`
+	Laps       []LapResponse   `json:"laps"`
+}
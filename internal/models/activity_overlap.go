@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// ConflictPolicyFlag saves an overlapping activity and lists what it overlaps
+	ConflictPolicyFlag = "flag"
+	// ConflictPolicyReject refuses to save an activity that overlaps another one
+	ConflictPolicyReject = "reject"
+)
+
+// ActivityOverlapThreshold is the share of the shorter of two activities that has to
+// overlap the other one before they are treated as covering the same time span
+const ActivityOverlapThreshold = 0.5
+
+// ActivityConflictQuery represents the query parameters controlling what happens when a
+// new or updated activity overlaps an existing one. The policy defaults to flag.
+type ActivityConflictQuery struct {
+	ConflictPolicy string `form:"conflictPolicy" validate:"omitempty,oneof=flag reject"`
+}
+
+// EndsAt returns when the activity finished, DoneAt being when it started
+func (a Activity) EndsAt() time.Time {
+	return a.DoneAt.Add(time.Duration(a.DurationInMinutes) * time.Minute)
+}
+
+// OverlapRatio returns how much of the shorter of two time spans the other one covers,
+// from 0 for disjoint spans to 1 when one lies entirely within the other
+func OverlapRatio(startA, endA, startB, endB time.Time) float64 {
+	start, end := startA, endA
+	if startB.After(start) {
+		start = startB
+	}
+	if endB.Before(end) {
+		end = endB
+	}
+	if !end.After(start) {
+		return 0
+	}
+
+	shorter := endA.Sub(startA)
+	if endB.Sub(startB) < shorter {
+		shorter = endB.Sub(startB)
+	}
+	if shorter <= 0 {
+		return 0
+	}
+	return float64(end.Sub(start)) / float64(shorter)
+}
+
+// BatchOverlaps compares the items of a batch create request with each other and returns,
+// for every item, the indexes of the other items it overlaps. Items whose doneAt does not
+// parse overlap nothing.
+func BatchOverlaps(items []CreateActivityRequest) [][]int {
+	starts := make([]time.Time, len(items))
+	ends := make([]time.Time, len(items))
+	valid := make([]bool, len(items))
+	for i, item := range items {
+		doneAt, err := time.Parse(time.RFC3339, item.DoneAt)
+		if err != nil {
+			continue
+		}
+		starts[i], ends[i], valid[i] = doneAt, doneAt.Add(time.Duration(item.DurationInMinutes)*time.Minute), true
+	}
+
+	overlaps := make([][]int, len(items))
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if !valid[i] || !valid[j] {
+				continue
+			}
+			if OverlapRatio(starts[i], ends[i], starts[j], ends[j]) >= ActivityOverlapThreshold {
+				overlaps[i] = append(overlaps[i], j)
+				overlaps[j] = append(overlaps[j], i)
+			}
+		}
+	}
+	return overlaps
+}
+
+// ActivityOverlap is a pair of the user's activities covering the same time span
+type ActivityOverlap struct {
+	FirstActivityID  string
+	SecondActivityID string
+	OverlapMinutes   float64
+	ShorterMinutes   int
+}
+
+// GetDuplicatesQuery represents the query parameters for listing duplicate candidates
+type GetDuplicatesQuery struct {
+	Limit int `form:"limit" validate:"min=0,max=100"`
+}
+
+// DuplicateCandidate is a pair of activities that probably record the same workout.
+// OverlapRatio is the share of the shorter activity covered by the other one.
+type DuplicateCandidate struct {
+	Activities       []ActivityResponse `json:"activities"`
+	OverlapRatio     float64            `json:"overlapRatio"`
+	SameActivityType bool               `json:"sameActivityType"`
+}
+
+// MergeActivitiesRequest represents the request body for merging activities into one.
+// The primary activity is kept and the others are deleted; when it is not given the
+// activity with the most detail is kept.
+type MergeActivitiesRequest struct {
+	ActivityIDs       []string `json:"activityIds" validate:"required,min=2,max=10,dive,required"`
+	PrimaryActivityID string   `json:"primaryActivityId,omitempty"`
+}
+
+// MergeActivitiesResponse represents the activity that results from a merge
+type MergeActivitiesResponse struct {
+	Activity          ActivityResponse `json:"activity"`
+	MergedActivityIDs []string         `json:"mergedActivityIds"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBatchOverlaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []CreateActivityRequest
+		want  [][]int
+	}{
+		{
+			name: "disjoint",
+			items: []CreateActivityRequest{
+				{DoneAt: "2024-05-01T08:00:00Z", DurationInMinutes: 30},
+				{DoneAt: "2024-05-01T09:00:00Z", DurationInMinutes: 30},
+			},
+			want: [][]int{nil, nil},
+		},
+		{
+			name: "same workout twice",
+			items: []CreateActivityRequest{
+				{DoneAt: "2024-05-01T08:00:00Z", DurationInMinutes: 60},
+				{DoneAt: "2024-05-01T09:00:00Z", DurationInMinutes: 30},
+				{DoneAt: "2024-05-01T08:10:00Z", DurationInMinutes: 40},
+			},
+			want: [][]int{{2}, nil, {0}},
+		},
+		{
+			// 10 of the shorter 30 minutes are shared, under the threshold
+			name: "brief overlap",
+			items: []CreateActivityRequest{
+				{DoneAt: "2024-05-01T08:00:00Z", DurationInMinutes: 30},
+				{DoneAt: "2024-05-01T08:20:00Z", DurationInMinutes: 30},
+			},
+			want: [][]int{nil, nil},
+		},
+		{
+			name: "one item overlapping two",
+			items: []CreateActivityRequest{
+				{DoneAt: "2024-05-01T08:00:00Z", DurationInMinutes: 120},
+				{DoneAt: "2024-05-01T08:00:00Z", DurationInMinutes: 30},
+				{DoneAt: "2024-05-01T09:00:00Z", DurationInMinutes: 30},
+			},
+			want: [][]int{{1, 2}, {0}, {0}},
+		},
+		{
+			name: "time zones",
+			items: []CreateActivityRequest{
+				{DoneAt: "2024-05-01T10:00:00+02:00", DurationInMinutes: 45},
+				{DoneAt: "2024-05-01T08:05:00Z", DurationInMinutes: 45},
+			},
+			want: [][]int{{1}, {0}},
+		},
+		{
+			name: "unparseable doneAt",
+			items: []CreateActivityRequest{
+				{DoneAt: "yesterday", DurationInMinutes: 45},
+				{DoneAt: "2024-05-01T08:00:00Z", DurationInMinutes: 45},
+			},
+			want: [][]int{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BatchOverlaps(tt.items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BatchOverlaps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	RevisionOperationCreate  = "create"
+	RevisionOperationUpdate  = "update"
+	RevisionOperationDelete  = "delete"
+	RevisionOperationRestore = "restore"
+	RevisionOperationMerge   = "merge"
+	RevisionOperationRevert  = "revert"
+)
+
+const (
+	RevisionSourceAPI    = "api"
+	RevisionSourceImport = "import"
+)
+
+// ActivityRevision records one change to an activity in the database. Changes holds
+// the fields that changed and Snapshot the activity as it was after the change, which
+// is what reverting to the revision brings back.
+type ActivityRevision struct {
+	ID                 uint             `gorm:"primarykey"`
+	ActivityID         string           `gorm:"not null"`
+	UserID             uint             `gorm:"not null"`
+	Revision           int              `gorm:"not null"`
+	Operation          string           `gorm:"not null"`
+	Changes            ActivityChanges  `gorm:"type:jsonb;not null"`
+	Snapshot           ActivitySnapshot `gorm:"type:jsonb;not null"`
+	ActorID            uint             `gorm:"not null"`
+	Source             string           `gorm:"not null"`
+	RequestID          *string
+	RevertedToRevision *int
+	CreatedAt          time.Time
+}
+
+// ActivitySnapshot holds the editable fields of an activity
+type ActivitySnapshot struct {
+	ActivityType        string      `json:"activityType"`
+	DoneAt              time.Time   `json:"doneAt"`
+	DurationInMinutes   int         `json:"durationInMinutes"`
+	CaloriesBurned      int         `json:"caloriesBurned"`
+	DistanceMeters      *float64    `json:"distanceMeters"`
+	ElevationGainMeters *float64    `json:"elevationGainMeters"`
+	PoolLengthMeters    *float64    `json:"poolLengthMeters"`
+	Notes               *string     `json:"notes"`
+	Tags                StringArray `json:"tags"`
+	// Revisions recorded before activities could be shared have no visibility
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// NewActivitySnapshot captures the editable fields of activity
+func NewActivitySnapshot(activity Activity) ActivitySnapshot {
+	tags := activity.Tags
+	if tags == nil {
+		tags = StringArray{}
+	}
+	return ActivitySnapshot{
+		ActivityType:        activity.ActivityType,
+		DoneAt:              activity.DoneAt.UTC(),
+		DurationInMinutes:   activity.DurationInMinutes,
+		CaloriesBurned:      activity.CaloriesBurned,
+		DistanceMeters:      activity.DistanceMeters,
+		ElevationGainMeters: activity.ElevationGainMeters,
+		PoolLengthMeters:    activity.PoolLengthMeters,
+		Notes:               activity.Notes,
+		Tags:                tags,
+		Visibility:          activity.Visibility,
+	}
+}
+
+func (s ActivitySnapshot) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (s *ActivitySnapshot) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// FieldChange is the value of a field before and after a change
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ActivityChanges maps the JSON name of every changed field to its change
+type ActivityChanges map[string]FieldChange
+
+func (c ActivityChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *ActivityChanges) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+func scanJSON(src interface{}, dest interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	case []byte:
+		return json.Unmarshal(v, dest)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, dest)
+	}
+}
+
+// RevertActivityRequest represents the request body for reverting an activity to one
+// of its revisions
+type RevertActivityRequest struct {
+	Revision int `json:"revision" validate:"required,min=1"`
+}
+
+// ActivityRevisionResponse represents one entry of an activity's history
+type ActivityRevisionResponse struct {
+	Revision           int             `json:"revision"`
+	Operation          string          `json:"operation"`
+	Changes            ActivityChanges `json:"changes"`
+	ActorID            uint            `json:"actorId"`
+	Source             string          `json:"source"`
+	RequestID          *string         `json:"requestId"`
+	RevertedToRevision *int            `json:"revertedToRevision,omitempty"`
+	CreatedAt          time.Time       `json:"createdAt"`
+}
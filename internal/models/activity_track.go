@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	TrackFormatPolyline = "polyline"
+	TrackFormatGeoJSON  = "geojson"
+)
+
+// ActivityTrack is the GPS route recorded for an activity. Points are stored in the
+// compact encoding of geo.EncodeTrack and the bounding box is kept alongside them so
+// activities can be found by area without decoding any route.
+type ActivityTrack struct {
+	ID           uint      `json:"-" gorm:"primarykey"`
+	ActivityID   string    `json:"activityId" gorm:"uniqueIndex;not null"`
+	UserID       uint      `json:"-" gorm:"not null"`
+	PointCount   int       `json:"pointCount" gorm:"not null"`
+	Points       []byte    `json:"-" gorm:"not null"`
+	MinLatitude  float64   `json:"minLatitude" gorm:"not null"`
+	MinLongitude float64   `json:"minLongitude" gorm:"not null"`
+	MaxLatitude  float64   `json:"maxLatitude" gorm:"not null"`
+	MaxLongitude float64   `json:"maxLongitude" gorm:"not null"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// GetActivityTrackQuery represents the query parameters for fetching an activity's route
+type GetActivityTrackQuery struct {
+	Format    string  `form:"format" validate:"omitempty,oneof=polyline geojson"`
+	Tolerance float64 `form:"tolerance" validate:"omitempty,min=0,max=1000"`
+}
+
+// ActivityTrackResponse represents an activity's route as an encoded polyline.
+// BoundingBox is [minLongitude, minLatitude, maxLongitude, maxLatitude] as in GeoJSON.
+type ActivityTrackResponse struct {
+	ActivityID  string     `json:"activityId"`
+	Format      string     `json:"format"`
+	PointCount  int        `json:"pointCount"`
+	BoundingBox [4]float64 `json:"bbox"`
+	Polyline    string     `json:"polyline"`
+}
+
+// GeoJSONFeature represents an activity's route as a GeoJSON LineString feature
+type GeoJSONFeature struct {
+	Type        string                 `json:"type"`
+	BoundingBox [4]float64             `json:"bbox"`
+	Geometry    GeoJSONLineString      `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONLineString holds [longitude, latitude] or [longitude, latitude, elevation] positions
+type GeoJSONLineString struct {
+	Type        string      `json:"type"`
+	Coordinates [][]float64 `json:"coordinates"`
+}
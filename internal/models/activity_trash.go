@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// GetTrashQuery represents the query parameters for listing deleted activities
+type GetTrashQuery struct {
+	Limit  int `form:"limit" validate:"min=0,max=100"`
+	Offset int `form:"offset" validate:"min=0"`
+}
+
+// TrashedActivityResponse is a deleted activity and when it will be purged for good
+type TrashedActivityResponse struct {
+	ActivityResponse
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+const (
+	FollowStatusPending  = "pending"
+	FollowStatusAccepted = "accepted"
+
+	// Private activities are only seen by their owner, followers activities by accepted
+	// followers too and public activities by anyone
+	ActivityVisibilityPrivate   = "private"
+	ActivityVisibilityFollowers = "followers"
+	ActivityVisibilityPublic    = "public"
+
+	// DefaultActivityVisibility is the visibility of activities created without one
+	DefaultActivityVisibility = ActivityVisibilityFollowers
+
+	// DefaultFeedLimit and DefaultFollowListLimit are the page sizes of the feed and the
+	// follow lists when no limit is given
+	DefaultFeedLimit       = 20
+	DefaultFollowListLimit = 20
+)
+
+// Follow represents one user following another in the database. A follow of a private
+// account stays pending until the account accepts it.
+type Follow struct {
+	FollowerID uint   `gorm:"primaryKey"`
+	FolloweeID uint   `gorm:"primaryKey"`
+	Status     string `gorm:"not null"`
+	CreatedAt  time.Time
+	AcceptedAt *time.Time
+}
+
+// FollowUser is a follow together with the profile of the user on the other side
+type FollowUser struct {
+	UserID    uint
+	Name      string
+	ImageURI  string
+	Status    string
+	CreatedAt time.Time
+}
+
+// ListFollowsQuery represents the query parameters of the follower, following and
+// follow request lists
+type ListFollowsQuery struct {
+	Limit  int `form:"limit" validate:"min=0,max=100"`
+	Offset int `form:"offset" validate:"min=0"`
+}
+
+// FollowResponse represents the state of a follow after following a user
+type FollowResponse struct {
+	UserID uint   `json:"userId"`
+	Status string `json:"status"`
+}
+
+// FollowUserResponse represents a user in the follower, following and follow request
+// lists. Since is when the follow was requested.
+type FollowUserResponse struct {
+	UserID   uint    `json:"userId"`
+	Name     *string `json:"name"`
+	ImageURI *string `json:"imageUri"`
+	Status   string  `json:"status"`
+	Since    string  `json:"since"`
+}
+
+// GetFeedQuery represents the query parameters of the activity feed and of the
+// activities of another user
+type GetFeedQuery struct {
+	Limit  int    `form:"limit" validate:"min=0,max=100"`
+	Cursor string `form:"cursor" validate:"max=512"`
+	// Keyset position decoded from Cursor: activities done strictly before (BeforeDoneAt, BeforeID)
+	BeforeDoneAt time.Time `form:"-"`
+	BeforeID     uint      `form:"-"`
+}
+
+// FeedCursor is the position of the last activity on a feed page, encoded into the
+// opaque cursor handed to clients
+type FeedCursor struct {
+	DoneAt string `json:"d"`
+	ID     uint   `json:"i"`
+}
+
+// FeedUserResponse represents the user who did an activity in the feed
+type FeedUserResponse struct {
+	UserID   uint    `json:"userId"`
+	Name     *string `json:"name"`
+	ImageURI *string `json:"imageUri"`
+}
+
+// FeedItemResponse represents an activity in the feed together with who did it
+type FeedItemResponse struct {
+	User     FeedUserResponse `json:"user"`
+	Activity ActivityResponse `json:"activity"`
+}
+
+// UserActivitiesPage is one page of the activities of a user someone else may see, most
+// recent activities first
+type UserActivitiesPage struct {
+	Items      []ActivityResponse
+	NextCursor *string
+}
+
+// FeedPage is one page of the activity feed, most recent activities first
+type FeedPage struct {
+	Items      []FeedItemResponse
+	NextCursor *string
+}
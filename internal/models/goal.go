@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	GoalMetricActiveMinutes = "ACTIVE_MINUTES"
+	GoalMetricCalories      = "CALORIES"
+	GoalMetricSessions      = "SESSIONS"
+
+	GoalPeriodWeekly  = "WEEKLY"
+	GoalPeriodMonthly = "MONTHLY"
+)
+
+// Goal represents a weekly or monthly fitness target in the database.
+// When ActivityType is set, only activities of that type count towards the goal.
+type Goal struct {
+	gorm.Model
+	GoalID       string `json:"goalId" gorm:"uniqueIndex;not null"`
+	UserID       uint   `json:"-" gorm:"not null;index"`
+	Metric       string `json:"metric" gorm:"not null"`
+	ActivityType string `json:"activityType"`
+	Period       string `json:"period" gorm:"not null"`
+	Target       int    `json:"target" gorm:"not null"`
+}
+
+// CreateGoalRequest represents the request body for creating a goal
+type CreateGoalRequest struct {
+	Metric       string `json:"metric" validate:"required,oneof=ACTIVE_MINUTES CALORIES SESSIONS"`
+	ActivityType string `json:"activityType" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+	Period       string `json:"period" validate:"required,oneof=WEEKLY MONTHLY"`
+	Target       int    `json:"target" validate:"required,min=1"`
+}
+
+// UpdateGoalRequest represents the request body for updating a goal
+type UpdateGoalRequest struct {
+	Metric       *string `json:"metric,omitempty" validate:"omitempty,oneof=ACTIVE_MINUTES CALORIES SESSIONS"`
+	ActivityType *string `json:"activityType,omitempty" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+	Period       *string `json:"period,omitempty" validate:"omitempty,oneof=WEEKLY MONTHLY"`
+	Target       *int    `json:"target,omitempty" validate:"omitempty,min=1"`
+}
+
+// GoalResponse represents the response format for goal operations
+type GoalResponse struct {
+	GoalID       string    `json:"goalId"`
+	Metric       string    `json:"metric"`
+	ActivityType *string   `json:"activityType"`
+	Period       string    `json:"period"`
+	Target       int       `json:"target"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// GetGoalsProgressQuery represents the query parameters of goal progress. Weeks start
+// on Monday and months on the 1st in TimeZone, which defaults to UTC.
+type GetGoalsProgressQuery struct {
+	TimeZone string `form:"tz" validate:"omitempty,timezone"`
+}
+
+// GoalPeriodProgress represents the progress of a goal within a single period
+type GoalPeriodProgress struct {
+	PeriodStart string  `json:"periodStart"`
+	PeriodEnd   string  `json:"periodEnd"`
+	Value       int     `json:"value"`
+	Percent     float64 `json:"percent"`
+	Completed   bool    `json:"completed"`
+}
+
+// GoalProgressResponse represents the current progress and completion history of a goal
+type GoalProgressResponse struct {
+	GoalResponse
+	Current GoalPeriodProgress   `json:"current"`
+	History []GoalPeriodProgress `json:"history"`
+}
+
+// ActivityPeriodTotals represents the aggregated activity metrics of the week or month
+// starting on PeriodStart, a date in the time zone the periods were cut in
+type ActivityPeriodTotals struct {
+	PeriodStart    string
+	Sessions       int
+	ActiveMinutes  int
+	CaloriesBurned int
+}
@@ -0,0 +1,133 @@
+package models
+
+import "time"
+
+const (
+	// DefaultHeartRatePoints is how many points a heart rate series is downsampled to for
+	// display when the client does not ask for a number
+	DefaultHeartRatePoints = 500
+
+	MaxHeartRateSourceProfile = "profile"
+	MaxHeartRateSourceAge     = "age"
+)
+
+// ActivityHeartRate is the heart rate recorded during an activity. Samples are stored in
+// the compact encoding of heartrate.Encode with their range and summary alongside, so
+// lists can show them without decoding anything.
+type ActivityHeartRate struct {
+	ID          uint      `gorm:"primarykey"`
+	ActivityID  string    `gorm:"uniqueIndex;not null"`
+	UserID      uint      `gorm:"not null"`
+	SampleCount int       `gorm:"not null"`
+	Samples     []byte    `gorm:"not null"`
+	StartTime   time.Time `gorm:"not null"`
+	EndTime     time.Time `gorm:"not null"`
+	MinBPM      int       `gorm:"column:min_bpm;not null"`
+	AvgBPM      int       `gorm:"column:avg_bpm;not null"`
+	MaxBPM      int       `gorm:"column:max_bpm;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// SetHeartRateRequest represents the request body for uploading the heart rate of an
+// activity as readings taken at a fixed interval from StartTime, which defaults to when
+// the activity was done. A reading of 0 marks a moment the sensor lost contact.
+type SetHeartRateRequest struct {
+	StartTime       *string `json:"startTime,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IntervalSeconds int     `json:"intervalSeconds" validate:"required,min=1,max=60"`
+	Samples         []int   `json:"samples" validate:"required,min=2,max=86400,dive,min=0,max=250"`
+}
+
+// GetHeartRateQuery represents the query parameters for fetching an activity's heart rate
+type GetHeartRateQuery struct {
+	Points int `form:"points" validate:"min=0,max=5000"`
+}
+
+// GetWeeklyHeartRateZonesQuery represents the query parameters of the weekly zone
+// distribution. Weeks start on Monday in TimeZone, which defaults to UTC.
+type GetWeeklyHeartRateZonesQuery struct {
+	From     string `form:"from" validate:"required,datetime=2006-01-02"`
+	To       string `form:"to" validate:"required,datetime=2006-01-02"`
+	TimeZone string `form:"tz" validate:"omitempty,timezone"`
+}
+
+// ValidateQuery checks that the range is in order and not too long
+func (q GetWeeklyHeartRateZonesQuery) ValidateQuery() map[string]string {
+	from, _ := time.Parse(DateLayout, q.From)
+	to, _ := time.Parse(DateLayout, q.To)
+
+	if from.After(to) {
+		return map[string]string{"from": "from must not be after to"}
+	}
+	if to.Sub(from) >= MaxCalendarDays*24*time.Hour {
+		return map[string]string{"to": "the range must not be longer than 366 days"}
+	}
+	return nil
+}
+
+// HeartRatePointResponse is a point of a heart rate series, OffsetSeconds after its start
+type HeartRatePointResponse struct {
+	OffsetSeconds int `json:"offsetSeconds"`
+	BPM           int `json:"bpm"`
+}
+
+// HeartRateSeriesResponse represents an activity's heart rate, downsampled for display
+type HeartRateSeriesResponse struct {
+	ActivityID  string                   `json:"activityId"`
+	StartTime   time.Time                `json:"startTime"`
+	EndTime     time.Time                `json:"endTime"`
+	SampleCount int                      `json:"sampleCount"`
+	MinBPM      int                      `json:"minBpm"`
+	AvgBPM      int                      `json:"avgBpm"`
+	MaxBPM      int                      `json:"maxBpm"`
+	Points      []HeartRatePointResponse `json:"points"`
+}
+
+// HeartRateZoneResponse represents the time spent in a heart rate zone
+type HeartRateZoneResponse struct {
+	Zone    int     `json:"zone"`
+	MinBPM  int     `json:"minBpm"`
+	MaxBPM  int     `json:"maxBpm"`
+	Seconds int     `json:"seconds"`
+	Percent float64 `json:"percent"`
+}
+
+// HeartRateZoneDistribution represents how the recorded time splits over the zones.
+// BelowZonesSeconds is the time spent under the first zone.
+type HeartRateZoneDistribution struct {
+	TotalSeconds      int                     `json:"totalSeconds"`
+	BelowZonesSeconds int                     `json:"belowZonesSeconds"`
+	Zones             []HeartRateZoneResponse `json:"zones"`
+}
+
+// ActivityHeartRateZonesResponse represents the zone distribution of an activity.
+// MaxHeartRateSource tells whether the maximum heart rate came from the profile or was
+// estimated from the age.
+type ActivityHeartRateZonesResponse struct {
+	ActivityID         string `json:"activityId"`
+	MaxHeartRate       int    `json:"maxHeartRate"`
+	MaxHeartRateSource string `json:"maxHeartRateSource"`
+	HeartRateZoneDistribution
+}
+
+// HeartRateZoneWeek represents the zone distribution of the activities done in a week
+type HeartRateZoneWeek struct {
+	WeekStart  string `json:"weekStart"`
+	Activities int    `json:"activities"`
+	HeartRateZoneDistribution
+}
+
+// WeeklyHeartRateZonesResponse represents the zone distribution week by week, every week
+// with zones worked out from the current maximum heart rate
+type WeeklyHeartRateZonesResponse struct {
+	MaxHeartRate       int                 `json:"maxHeartRate"`
+	MaxHeartRateSource string              `json:"maxHeartRateSource"`
+	Weeks              []HeartRateZoneWeek `json:"weeks"`
+}
+
+// ActivityHeartRateRecord is a stored heart rate series together with when its activity
+// was done
+type ActivityHeartRateRecord struct {
+	ActivityHeartRate
+	DoneAt time.Time
+}
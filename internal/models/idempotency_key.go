@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotencyKey records a mutating request made with an Idempotency-Key header so
+// that retries replay the original response. A zero ResponseStatus means the first
+// request is still being processed.
+type IdempotencyKey struct {
+	ID                  uint   `gorm:"primarykey"`
+	UserID              uint   `gorm:"not null"`
+	Key                 string `gorm:"column:idempotency_key;not null"`
+	RequestHash         string `gorm:"not null"`
+	ResponseStatus      int    `gorm:"not null;default:0"`
+	ResponseContentType string
+	ResponseBody        []byte
+	CreatedAt           time.Time
+	ExpiresAt           time.Time `gorm:"not null;index"`
+}
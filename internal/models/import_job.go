@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ImportJobStatusPending   = "PENDING"
+	ImportJobStatusRunning   = "RUNNING"
+	ImportJobStatusCompleted = "COMPLETED"
+	ImportJobStatusFailed    = "FAILED"
+
+	ImportJobSourceAppleHealth = "APPLE_HEALTH"
+)
+
+// ImportJob tracks a background activity import and its progress
+type ImportJob struct {
+	gorm.Model
+	JobID          string     `json:"jobId" gorm:"uniqueIndex;not null"`
+	UserID         uint       `json:"-" gorm:"not null;index"`
+	Source         string     `json:"source" gorm:"not null"`
+	Status         string     `json:"status" gorm:"not null"`
+	BytesTotal     int64      `json:"bytesTotal"`
+	BytesProcessed int64      `json:"bytesProcessed"`
+	Processed      int        `json:"processed"`
+	Imported       int        `json:"imported"`
+	Skipped        int        `json:"skipped"`
+	Error          string     `json:"error"`
+	FinishedAt     *time.Time `json:"finishedAt"`
+}
+
+// ImportJobResponse represents the progress of an import job
+type ImportJobResponse struct {
+	JobID      string     `json:"jobId"`
+	Source     string     `json:"source"`
+	Status     string     `json:"status"`
+	Progress   float64    `json:"progress"`
+	Processed  int        `json:"processed"`
+	Imported   int        `json:"imported"`
+	Skipped    int        `json:"skipped"`
+	Error      *string    `json:"error"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
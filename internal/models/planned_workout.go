@@ -0,0 +1,117 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DateLayout is the format of calendar dates in planned workout and calendar requests
+const DateLayout = "2006-01-02"
+
+// MaxCalendarDays is the longest date range the calendar returns at once
+const MaxCalendarDays = 366
+
+// PlannedWorkout represents a session scheduled for a day in the database. With a
+// Recurrence rule it repeats from ScheduledDate on; LastDate is its final occurrence,
+// or nil when it repeats forever. EnrollmentID is set on the workouts scheduled by a
+// training program.
+type PlannedWorkout struct {
+	gorm.Model
+	PlannedWorkoutID        string     `json:"plannedWorkoutId" gorm:"uniqueIndex;not null"`
+	UserID                  uint       `json:"-" gorm:"not null;index"`
+	ActivityType            string     `json:"activityType" gorm:"not null"`
+	ScheduledDate           time.Time  `json:"date" gorm:"type:date;not null"`
+	TargetDurationInMinutes int        `json:"targetDurationInMinutes" gorm:"not null"`
+	TargetCalories          int        `json:"targetCalories" gorm:"not null"`
+	Recurrence              *string    `json:"recurrence"`
+	LastDate                *time.Time `json:"-" gorm:"type:date"`
+	Notes                   *string    `json:"notes"`
+	EnrollmentID            *string    `json:"enrollmentId" gorm:"index"`
+}
+
+// PlannedWorkoutCompletion records the activity that completed one occurrence of a
+// planned workout
+type PlannedWorkoutCompletion struct {
+	ID               uint      `gorm:"primarykey"`
+	PlannedWorkoutID string    `gorm:"not null"`
+	UserID           uint      `gorm:"not null"`
+	OccurrenceDate   time.Time `gorm:"type:date;not null"`
+	ActivityID       string    `gorm:"not null"`
+	CreatedAt        time.Time
+}
+
+// CreatePlannedWorkoutRequest represents the request body for scheduling a workout.
+// TargetCalories defaults to the estimate for the activity type and duration.
+type CreatePlannedWorkoutRequest struct {
+	ActivityType            string  `json:"activityType" validate:"required,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+	Date                    string  `json:"date" validate:"required,datetime=2006-01-02"`
+	TargetDurationInMinutes int     `json:"targetDurationInMinutes" validate:"required,min=1"`
+	TargetCalories          *int    `json:"targetCalories,omitempty" validate:"omitempty,min=1"`
+	Recurrence              *string `json:"recurrence,omitempty" validate:"omitempty,max=255"`
+}
+
+// UpdatePlannedWorkoutRequest represents the request body for updating a planned workout.
+// An empty Recurrence stops the workout from repeating.
+type UpdatePlannedWorkoutRequest struct {
+	ActivityType            *string `json:"activityType,omitempty" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+	Date                    *string `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	TargetDurationInMinutes *int    `json:"targetDurationInMinutes,omitempty" validate:"omitempty,min=1"`
+	TargetCalories          *int    `json:"targetCalories,omitempty" validate:"omitempty,min=1"`
+	Recurrence              *string `json:"recurrence,omitempty" validate:"omitempty,max=255"`
+}
+
+// PlannedWorkoutResponse represents the response format for planned workout operations
+type PlannedWorkoutResponse struct {
+	PlannedWorkoutID        string    `json:"plannedWorkoutId"`
+	ActivityType            string    `json:"activityType"`
+	Date                    string    `json:"date"`
+	TargetDurationInMinutes int       `json:"targetDurationInMinutes"`
+	TargetCalories          int       `json:"targetCalories"`
+	Recurrence              *string   `json:"recurrence"`
+	Notes                   *string   `json:"notes"`
+	EnrollmentID            *string   `json:"enrollmentId"`
+	CreatedAt               time.Time `json:"createdAt"`
+	UpdatedAt               time.Time `json:"updatedAt"`
+}
+
+// GetCalendarQuery represents the query parameters of the calendar. Completed
+// activities are placed on days in TimeZone, which defaults to UTC.
+type GetCalendarQuery struct {
+	From     string `form:"from" validate:"required,datetime=2006-01-02"`
+	To       string `form:"to" validate:"required,datetime=2006-01-02"`
+	TimeZone string `form:"tz" validate:"omitempty,timezone"`
+}
+
+// ValidateQuery checks that the calendar range is in order and not too long
+func (q GetCalendarQuery) ValidateQuery() map[string]string {
+	from, _ := time.Parse(DateLayout, q.From)
+	to, _ := time.Parse(DateLayout, q.To)
+
+	if from.After(to) {
+		return map[string]string{"from": "from must not be after to"}
+	}
+	if to.Sub(from) >= MaxCalendarDays*24*time.Hour {
+		return map[string]string{"to": "the range must not be longer than 366 days"}
+	}
+	return nil
+}
+
+// PlannedSession is one occurrence of a planned workout on the calendar. ActivityID is
+// the activity that completed it.
+type PlannedSession struct {
+	PlannedWorkoutID        string  `json:"plannedWorkoutId"`
+	ActivityType            string  `json:"activityType"`
+	TargetDurationInMinutes int     `json:"targetDurationInMinutes"`
+	TargetCalories          int     `json:"targetCalories"`
+	Recurring               bool    `json:"recurring"`
+	Completed               bool    `json:"completed"`
+	ActivityID              *string `json:"activityId"`
+}
+
+// CalendarDay represents the planned and completed sessions of one day
+type CalendarDay struct {
+	Date      string             `json:"date"`
+	Planned   []PlannedSession   `json:"planned"`
+	Completed []ActivityResponse `json:"completed"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type RegisterResponse struct {
 	Token string `json:"token"`
@@ -23,6 +27,17 @@ type Profile struct {
 	HeightUnit string  `json:"heightUnit" validate:"omitempty,oneof=CM INCH"`
 	Weight     float64 `json:"weight" validate:"omitempty,min=10,max=1000"`
 	Height     float64 `json:"height" validate:"omitempty,min=3,max=250"`
+	// PreferredUnits is the unit system distances are shown in, empty until the user
+	// picks one
+	PreferredUnits string `json:"preferredUnits" validate:"omitempty,oneof=METRIC IMPERIAL"`
+	// MaxHeartRate and BirthDate set the heart rate zones. Without a maximum heart rate
+	// one is estimated from the age.
+	MaxHeartRate     *int       `json:"maxHeartRate"`
+	BirthDate        *time.Time `json:"birthDate" gorm:"type:date"`
+	RestingHeartRate *int       `json:"restingHeartRate"`
+	// Following a private account needs the account to accept the request
+	IsPrivate bool `json:"isPrivate" gorm:"not null;default:false"`
+	Version   int  `json:"-" gorm:"not null;default:1"`
 }
 
 type PatchProfileRequest struct {
@@ -33,6 +48,14 @@ type PatchProfileRequest struct {
 	Height     float64 `json:"height" validate:"required,min=3,max=250"`
 	Name       string  `json:"name" validate:"required,min=2,max=60"`
 	ImageURI   string  `json:"imageUri" validate:"required,uri"`
+	// Left out, distances stay in the units they are shown in now
+	PreferredUnits *string `json:"preferredUnits,omitempty" validate:"omitempty,oneof=METRIC IMPERIAL"`
+	// Left out, the heart rate settings keep their current values; null clears them
+	MaxHeartRate     *int    `json:"maxHeartRate,omitempty" validate:"omitempty,min=100,max=230"`
+	BirthDate        *string `json:"birthDate,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	RestingHeartRate *int    `json:"restingHeartRate,omitempty" validate:"omitempty,min=30,max=120"`
+	// Left out, the account stays as private or public as it is
+	IsPrivate *bool `json:"isPrivate,omitempty"`
 }
 
 type ProfileResponse struct {
@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+// StrengthActivityType is the activity type whose sessions are logged as sets of exercises
+const StrengthActivityType = "StrengthTraining"
+
+// StrengthSet represents one set of a strength session in the database. ExerciseIndex
+// and SetIndex keep the order the exercises and their sets were done in.
+type StrengthSet struct {
+	ID            uint   `gorm:"primaryKey"`
+	ActivityID    string `gorm:"not null"`
+	UserID        uint   `gorm:"not null"`
+	ExerciseID    string `gorm:"not null"`
+	ExerciseIndex int    `gorm:"not null"`
+	SetIndex      int    `gorm:"not null"`
+	Reps          int    `gorm:"not null"`
+	WeightKg      *float64
+	RPE           *float64
+	CreatedAt     time.Time
+}
+
+// ExerciseSetRecord is a logged set of an exercise together with when its session was done
+type ExerciseSetRecord struct {
+	StrengthSet
+	DoneAt time.Time
+}
+
+// StrengthSetRequest is one set of an exercise. WeightKg is left out for bodyweight work.
+type StrengthSetRequest struct {
+	Reps     int      `json:"reps" validate:"required,min=1,max=1000"`
+	WeightKg *float64 `json:"weightKg,omitempty" validate:"omitempty,min=0,max=1000"`
+	RPE      *float64 `json:"rpe,omitempty" validate:"omitempty,min=1,max=10"`
+}
+
+// StrengthExerciseRequest is an exercise of a strength session with its sets
+type StrengthExerciseRequest struct {
+	ExerciseID string               `json:"exerciseId" validate:"required,max=100"`
+	Sets       []StrengthSetRequest `json:"sets" validate:"required,min=1,max=50,dive"`
+}
+
+// SetStrengthExercisesRequest represents the request body for logging the exercises of a
+// strength session. It replaces whatever was logged before; an empty list clears it.
+type SetStrengthExercisesRequest struct {
+	Exercises []StrengthExerciseRequest `json:"exercises" validate:"max=30,dive"`
+}
+
+// GetExerciseHistoryQuery represents the query parameters for an exercise's history
+type GetExerciseHistoryQuery struct {
+	Limit  int `form:"limit" validate:"min=0,max=100"`
+	Offset int `form:"offset" validate:"min=0"`
+}
+
+// StrengthSetResponse represents a logged set
+type StrengthSetResponse struct {
+	Reps                 int      `json:"reps"`
+	WeightKg             *float64 `json:"weightKg"`
+	RPE                  *float64 `json:"rpe"`
+	EstimatedOneRepMaxKg *float64 `json:"estimatedOneRepMaxKg"`
+}
+
+// StrengthExerciseResponse represents an exercise of a strength session with its volume
+// and the best one-rep max estimated from its sets
+type StrengthExerciseResponse struct {
+	ExerciseID           string                `json:"exerciseId"`
+	Name                 string                `json:"name"`
+	Sets                 []StrengthSetResponse `json:"sets"`
+	VolumeKg             float64               `json:"volumeKg"`
+	EstimatedOneRepMaxKg *float64              `json:"estimatedOneRepMaxKg"`
+}
+
+// StrengthSessionResponse represents the exercises of a strength session and its totals
+type StrengthSessionResponse struct {
+	ActivityID    string                     `json:"activityId"`
+	Exercises     []StrengthExerciseResponse `json:"exercises"`
+	TotalSets     int                        `json:"totalSets"`
+	TotalReps     int                        `json:"totalReps"`
+	TotalVolumeKg float64                    `json:"totalVolumeKg"`
+}
+
+// ExerciseSessionResponse summarises an exercise within one session
+type ExerciseSessionResponse struct {
+	ActivityID           string    `json:"activityId"`
+	DoneAt               time.Time `json:"doneAt"`
+	Sets                 int       `json:"sets"`
+	Reps                 int       `json:"reps"`
+	TopSetWeightKg       *float64  `json:"topSetWeightKg"`
+	VolumeKg             float64   `json:"volumeKg"`
+	EstimatedOneRepMaxKg *float64  `json:"estimatedOneRepMaxKg"`
+}
+
+// ExerciseHistoryResponse represents an exercise's sessions, most recent first, with the
+// best one-rep max estimated over all of them
+type ExerciseHistoryResponse struct {
+	ExerciseID               string                    `json:"exerciseId"`
+	Name                     string                    `json:"name"`
+	BestEstimatedOneRepMaxKg *float64                  `json:"bestEstimatedOneRepMaxKg"`
+	BestEstimatedOneRepMaxAt *time.Time                `json:"bestEstimatedOneRepMaxAt"`
+	TotalSessions            int                       `json:"totalSessions"`
+	Sessions                 []ExerciseSessionResponse `json:"sessions"`
+}
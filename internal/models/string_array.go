@@ -0,0 +1,99 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StringArray maps a Postgres text[] column to a string slice
+type StringArray []string
+
+// Value encodes the slice as a Postgres array literal, quoting every element
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, s := range a {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		for _, r := range s {
+			if r == '"' || r == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// Scan decodes a one-dimensional Postgres array literal such as {a,"b c",NULL}
+func (a *StringArray) Scan(src interface{}) error {
+	var literal string
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		literal = v
+	case []byte:
+		literal = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringArray", src)
+	}
+
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return errors.New("invalid array literal")
+	}
+	body := literal[1 : len(literal)-1]
+
+	result := StringArray{}
+	if body == "" {
+		*a = result
+		return nil
+	}
+
+	var (
+		current strings.Builder
+		quoted  bool
+		inQuote bool
+	)
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case inQuote && c == '\\' && i+1 < len(body):
+			i++
+			current.WriteByte(body[i])
+		case c == '"':
+			inQuote = !inQuote
+			quoted = true
+		case c == ',' && !inQuote:
+			result = appendArrayElement(result, current.String(), quoted)
+			current.Reset()
+			quoted = false
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if inQuote {
+		return errors.New("invalid array literal")
+	}
+	*a = appendArrayElement(result, current.String(), quoted)
+	return nil
+}
+
+// appendArrayElement adds an element, dropping unquoted NULLs which a string slice cannot hold
+func appendArrayElement(a StringArray, element string, quoted bool) StringArray {
+	if !quoted && element == "NULL" {
+		return a
+	}
+	return append(a, element)
+}
@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+const (
+	TrainingLoadMethodHeartRate = "heartRate"
+	TrainingLoadMethodDuration  = "duration"
+
+	// DefaultTrainingLoadDays is how many days the load covers when no range is given
+	DefaultTrainingLoadDays = 90
+)
+
+// ActivityTypeLoadPerMinute is the training load of a minute of each activity type at a
+// typical effort. It stands in for TRIMP when an activity has no heart rate and is scaled
+// to match it: a minute at 60% of heart rate reserve is a TRIMP of about 1.2.
+var ActivityTypeLoadPerMinute = map[string]float64{
+	"Walking":          0.5,
+	"Yoga":             0.3,
+	"Stretching":       0.2,
+	"Cycling":          1.1,
+	"Swimming":         1.1,
+	"Dancing":          0.9,
+	"Hiking":           0.8,
+	"Running":          1.3,
+	"HIIT":             1.8,
+	"JumpRope":         1.5,
+	"StrengthTraining": 0.7,
+}
+
+// TrainingLoadDay is the training load of a UTC day the user trained on, together with
+// the acute and chronic load at the end of it
+type TrainingLoadDay struct {
+	UserID        uint      `gorm:"primaryKey"`
+	Day           time.Time `gorm:"primaryKey;type:date"`
+	ActivityCount int       `gorm:"not null"`
+	Load          float64   `gorm:"not null"`
+	AcuteLoad     float64   `gorm:"not null"`
+	ChronicLoad   float64   `gorm:"not null"`
+}
+
+// TrainingLoadState records from which day the stored training load days are out of date.
+// StaleFrom is nil when they are current; Generation goes up with every change.
+type TrainingLoadState struct {
+	UserID     uint       `gorm:"primaryKey"`
+	StaleFrom  *time.Time `gorm:"type:date"`
+	Generation int64      `gorm:"not null"`
+}
+
+// ActivityLoadInput is what the training load of an activity is worked out from.
+// HeartRateSamples is empty when no heart rate was recorded.
+type ActivityLoadInput struct {
+	ActivityID        string
+	ActivityType      string
+	DoneAt            time.Time
+	DurationInMinutes int
+	HeartRateSamples  []byte
+}
+
+// GetTrainingLoadQuery represents the query parameters of the training load. The range
+// defaults to the last DefaultTrainingLoadDays days up to today.
+type GetTrainingLoadQuery struct {
+	From string `form:"from" validate:"omitempty,datetime=2006-01-02"`
+	To   string `form:"to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// Range returns the first and last day of the range, filling in the defaults
+func (q GetTrainingLoadQuery) Range(now time.Time) (time.Time, time.Time) {
+	year, month, day := now.UTC().Date()
+	to := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if q.To != "" {
+		to, _ = time.Parse(DateLayout, q.To)
+	}
+	from := to.AddDate(0, 0, 1-DefaultTrainingLoadDays)
+	if q.From != "" {
+		from, _ = time.Parse(DateLayout, q.From)
+	}
+	return from, to
+}
+
+// ValidateQuery checks that the range is in order and not too long
+func (q GetTrainingLoadQuery) ValidateQuery() map[string]string {
+	from, to := q.Range(time.Now())
+
+	if from.After(to) {
+		return map[string]string{"from": "from must not be after to"}
+	}
+	if to.Sub(from) >= MaxCalendarDays*24*time.Hour {
+		return map[string]string{"from": "the range must not be longer than 366 days"}
+	}
+	return nil
+}
+
+// TrainingLoadDayResponse represents the training load of a day. Balance is the training
+// stress balance going into the day: the chronic minus the acute load at the end of the
+// day before.
+type TrainingLoadDayResponse struct {
+	Date        string  `json:"date"`
+	Activities  int     `json:"activities"`
+	Load        float64 `json:"load"`
+	AcuteLoad   float64 `json:"acuteLoad"`
+	ChronicLoad float64 `json:"chronicLoad"`
+	Balance     float64 `json:"balance"`
+}
+
+// TrainingLoadResponse represents the daily training load over a range of UTC days
+type TrainingLoadResponse struct {
+	AcuteDays   int                       `json:"acuteDays"`
+	ChronicDays int                       `json:"chronicDays"`
+	Days        []TrainingLoadDayResponse `json:"days"`
+}
+
+// ActivityLoadResponse represents the training load of an activity and whether it came
+// from its heart rate or its duration
+type ActivityLoadResponse struct {
+	ActivityID string  `json:"activityId"`
+	Load       float64 `json:"load"`
+	Method     string  `json:"method"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	EnrollmentStatusActive    = "active"
+	EnrollmentStatusCompleted = "completed"
+	EnrollmentStatusCancelled = "cancelled"
+)
+
+// ProgramEnrollment represents a user following a version of a training program in the
+// database. CurrentWeek is the program week in progress, counted from 0, which started
+// on CurrentWeekStart and has been repeated WeekRepeats times in a row.
+type ProgramEnrollment struct {
+	gorm.Model
+	EnrollmentID     string     `json:"enrollmentId" gorm:"uniqueIndex;not null"`
+	UserID           uint       `json:"-" gorm:"not null;index"`
+	ProgramID        string     `json:"programId" gorm:"not null"`
+	ProgramVersion   int        `json:"programVersion" gorm:"not null"`
+	StartDate        time.Time  `json:"startDate" gorm:"type:date;not null"`
+	CurrentWeek      int        `json:"-" gorm:"not null"`
+	CurrentWeekStart time.Time  `json:"-" gorm:"type:date;not null"`
+	WeekRepeats      int        `json:"weekRepeats" gorm:"not null"`
+	Status           string     `json:"status" gorm:"not null"`
+	CompletedAt      *time.Time `json:"completedAt"`
+}
+
+// ProgramEnrollmentWeek records how a finished program week went. Repeated is set when
+// the week had to be done again.
+type ProgramEnrollmentWeek struct {
+	ID                uint      `gorm:"primarykey"`
+	EnrollmentID      string    `gorm:"not null"`
+	Week              int       `gorm:"not null"`
+	StartDate         time.Time `gorm:"type:date;not null"`
+	PlannedSessions   int       `gorm:"not null"`
+	CompletedSessions int       `gorm:"not null"`
+	Repeated          bool      `gorm:"not null"`
+	CreatedAt         time.Time
+}
+
+// GetTrainingProgramQuery selects a version of a program, the latest by default
+type GetTrainingProgramQuery struct {
+	Version int `form:"version" validate:"omitempty,min=1"`
+}
+
+// EnrollProgramRequest represents the request body for starting a training program.
+// Version defaults to the latest version of the program.
+type EnrollProgramRequest struct {
+	StartDate string `json:"startDate" validate:"required,datetime=2006-01-02"`
+	Version   *int   `json:"version,omitempty" validate:"omitempty,min=1"`
+}
+
+// TrainingProgramSummary describes a program in the catalog
+type TrainingProgramSummary struct {
+	ProgramID     string `json:"programId"`
+	Version       int    `json:"version"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Weeks         int    `json:"weeks"`
+	TotalSessions int    `json:"totalSessions"`
+}
+
+// ProgramEnrollmentResponse represents the response format for enrollment operations.
+// CurrentWeek is counted from 1; Adherence is only filled in for a single enrollment.
+type ProgramEnrollmentResponse struct {
+	EnrollmentID   string         `json:"enrollmentId"`
+	ProgramID      string         `json:"programId"`
+	ProgramVersion int            `json:"programVersion"`
+	ProgramName    string         `json:"programName"`
+	StartDate      string         `json:"startDate"`
+	Status         string         `json:"status"`
+	CurrentWeek    int            `json:"currentWeek"`
+	TotalWeeks     int            `json:"totalWeeks"`
+	WeekRepeats    int            `json:"weekRepeats"`
+	CompletedAt    *time.Time     `json:"completedAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	Adherence      *PlanAdherence `json:"adherence,omitempty"`
+}
+
+// PlanAdherence reports how many of the sessions due so far were completed, overall and
+// for every week attempted, the week in progress included
+type PlanAdherence struct {
+	PlannedSessions   int             `json:"plannedSessions"`
+	CompletedSessions int             `json:"completedSessions"`
+	MissedSessions    int             `json:"missedSessions"`
+	Rate              float64         `json:"rate"`
+	Weeks             []WeekAdherence `json:"weeks"`
+}
+
+// WeekAdherence reports one attempt at a program week. Week is counted from 1.
+type WeekAdherence struct {
+	Week              int     `json:"week"`
+	StartDate         string  `json:"startDate"`
+	PlannedSessions   int     `json:"plannedSessions"`
+	CompletedSessions int     `json:"completedSessions"`
+	Rate              float64 `json:"rate"`
+	Repeated          bool    `json:"repeated"`
+	InProgress        bool    `json:"inProgress"`
+}
@@ -0,0 +1,40 @@
+package models
+
+const (
+	UnitSystemMetric   = "METRIC"
+	UnitSystemImperial = "IMPERIAL"
+
+	DistanceUnitKilometers = "KM"
+	DistanceUnitMiles      = "MI"
+
+	ElevationUnitMeters = "M"
+	ElevationUnitFeet   = "FT"
+
+	PaceUnitSecondsPerKilometer = "SEC_PER_KM"
+	PaceUnitSecondsPerMile      = "SEC_PER_MI"
+	PaceUnitSecondsPer100Meters = "SEC_PER_100M"
+	PaceUnitSecondsPer100Yards  = "SEC_PER_100YD"
+
+	SpeedUnitKilometersPerHour = "KMH"
+	SpeedUnitMilesPerHour      = "MPH"
+
+	MetersPerMile = 1609.344
+	MetersPerFoot = 0.3048
+	MetersPerYard = 0.9144
+)
+
+// UnitSystemForProfile returns the unit system matching the user's profile.
+// The user's preferred units win; until they pick one, users who record their height
+// in inches get imperial distances and everyone else gets metric.
+func UnitSystemForProfile(profile *Profile) string {
+	if profile == nil {
+		return UnitSystemMetric
+	}
+	if profile.PreferredUnits != "" {
+		return profile.PreferredUnits
+	}
+	if profile.HeightUnit == "INCH" {
+		return UnitSystemImperial
+	}
+	return UnitSystemMetric
+}
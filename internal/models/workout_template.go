@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkoutTemplate represents a routine a user logs repeatedly in the database
+type WorkoutTemplate struct {
+	gorm.Model
+	TemplateID        string            `json:"templateId" gorm:"uniqueIndex;not null"`
+	UserID            uint              `json:"-" gorm:"not null;index"`
+	Name              string            `json:"name" gorm:"not null"`
+	ActivityType      string            `json:"activityType" gorm:"not null"`
+	DurationInMinutes int               `json:"durationInMinutes" gorm:"not null"`
+	Notes             *string           `json:"notes"`
+	Exercises         TemplateExercises `json:"exercises" gorm:"type:jsonb;not null;default:'[]'"`
+}
+
+// TemplateExercise is one exercise of a strength routine. Reps and weight are per set;
+// DurationInSeconds is used instead of reps for timed holds such as planks.
+type TemplateExercise struct {
+	Name              string   `json:"name" validate:"required,max=100"`
+	Sets              int      `json:"sets" validate:"required,min=1,max=100"`
+	Reps              *int     `json:"reps,omitempty" validate:"omitempty,min=1,max=1000"`
+	WeightKg          *float64 `json:"weightKg,omitempty" validate:"omitempty,min=0,max=1000"`
+	DurationInSeconds *int     `json:"durationInSeconds,omitempty" validate:"omitempty,min=1,max=86400"`
+}
+
+// TemplateExercises maps a list of exercises to a jsonb column
+type TemplateExercises []TemplateExercise
+
+func (e TemplateExercises) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (e *TemplateExercises) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*e = TemplateExercises{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	case []byte:
+		return json.Unmarshal(v, e)
+	default:
+		return fmt.Errorf("cannot scan %T into TemplateExercises", src)
+	}
+}
+
+// CreateWorkoutTemplateRequest represents the request body for creating a workout template
+type CreateWorkoutTemplateRequest struct {
+	Name              string             `json:"name" validate:"required,max=100"`
+	ActivityType      string             `json:"activityType" validate:"required,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+	DurationInMinutes int                `json:"durationInMinutes" validate:"required,min=1"`
+	Notes             *string            `json:"notes,omitempty" validate:"omitempty,max=2000"`
+	Exercises         []TemplateExercise `json:"exercises,omitempty" validate:"omitempty,max=50,dive"`
+}
+
+// Normalize trims the template and exercise names, so that validation sees a name made
+// only of whitespace as missing
+func (r *CreateWorkoutTemplateRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	trimExerciseNames(r.Exercises)
+}
+
+// UpdateWorkoutTemplateRequest represents the request body for updating a workout template.
+// Exercises replaces the whole list when given.
+type UpdateWorkoutTemplateRequest struct {
+	Name              *string             `json:"name,omitempty" validate:"omitempty,max=100"`
+	ActivityType      *string             `json:"activityType,omitempty" validate:"omitempty,oneof=Walking Yoga Stretching Cycling Swimming Dancing Hiking Running HIIT JumpRope StrengthTraining"`
+	DurationInMinutes *int                `json:"durationInMinutes,omitempty" validate:"omitempty,min=1"`
+	Notes             *string             `json:"notes,omitempty" validate:"omitempty,max=2000"`
+	Exercises         *[]TemplateExercise `json:"exercises,omitempty" validate:"omitempty,max=50,dive"`
+}
+
+// Normalize trims the template and exercise names that are given
+func (r *UpdateWorkoutTemplateRequest) Normalize() {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+	}
+	if r.Exercises != nil {
+		trimExerciseNames(*r.Exercises)
+	}
+}
+
+func trimExerciseNames(exercises []TemplateExercise) {
+	for i := range exercises {
+		exercises[i].Name = strings.TrimSpace(exercises[i].Name)
+	}
+}
+
+// WorkoutTemplateResponse represents the response format for workout template operations
+type WorkoutTemplateResponse struct {
+	TemplateID        string             `json:"templateId"`
+	Name              string             `json:"name"`
+	ActivityType      string             `json:"activityType"`
+	DurationInMinutes int                `json:"durationInMinutes"`
+	Notes             *string            `json:"notes"`
+	Exercises         []TemplateExercise `json:"exercises"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt"`
+}
+
+// CreateActivityFromTemplateRequest represents the optional overrides when logging an
+// activity from a template. DoneAt defaults to now and the duration to the template's.
+type CreateActivityFromTemplateRequest struct {
+	DoneAt            *string `json:"doneAt,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	DurationInMinutes *int    `json:"durationInMinutes,omitempty" validate:"omitempty,min=1"`
+}
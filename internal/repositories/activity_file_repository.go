@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errAttachmentLimit rolls back an attach that would exceed the limit
+var errAttachmentLimit = errors.New("activity attachment limit exceeded")
+
+type ActivityFileRepository interface {
+	AttachFiles(ctx context.Context, activityID string, userID uint, fileIDs []uint, limit int) (bool, error)
+	GetAttachments(ctx context.Context, userID uint, activityIDs []string) ([]models.ActivityAttachment, error)
+	GetAttachedFileIDs(ctx context.Context, fileIDs []uint) ([]uint, error)
+	DetachFile(ctx context.Context, activityID string, userID uint, fileID uint) error
+	PurgeAttachments(ctx context.Context, before time.Time) ([]string, error)
+}
+
+type activityFileRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityFileRepository(db *gorm.DB) ActivityFileRepository {
+	return &activityFileRepository{db: db}
+}
+
+// AttachFiles links the files to the activity and bumps its version, as the attachments
+// are part of the activity representation. It attaches nothing and returns false when the
+// activity would end up with more than limit files.
+func (r *activityFileRepository) AttachFiles(ctx context.Context, activityID string, userID uint, fileIDs []uint, limit int) (bool, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bumping the version locks the activity, so concurrent attaches are counted one after the other
+		result := tx.Model(&models.Activity{}).
+			Where("activity_id = ? AND user_id = ?", activityID, userID).
+			Updates(map[string]interface{}{"version": gorm.Expr("version + 1"), "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var count int64
+		if err := tx.Model(&models.ActivityFile{}).Where("activity_id = ?", activityID).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count)+len(fileIDs) > limit {
+			return errAttachmentLimit
+		}
+
+		files := make([]models.ActivityFile, len(fileIDs))
+		for i, fileID := range fileIDs {
+			files[i] = models.ActivityFile{ActivityID: activityID, FileID: fileID, UserID: userID}
+		}
+		return tx.Create(&files).Error
+	})
+	if errors.Is(err, errAttachmentLimit) {
+		return false, nil
+	}
+	if err != nil {
+		// A file attached by a concurrent request runs into the unique index on file_id
+		err = translateError(r.db, err)
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, gorm.ErrDuplicatedKey) {
+			log.Logger.Error().Err(err).Msg("Failed to attach files to activity")
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetAttachments returns the files attached to the given activities, oldest attachment first
+func (r *activityFileRepository) GetAttachments(ctx context.Context, userID uint, activityIDs []string) ([]models.ActivityAttachment, error) {
+	var attachments []models.ActivityAttachment
+	if len(activityIDs) == 0 {
+		return attachments, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("activity_files").
+		Select("activity_files.activity_id, activity_files.file_id, files.file_name, files.file_url, activity_files.created_at").
+		Joins("JOIN files ON files.id = activity_files.file_id").
+		Where("activity_files.user_id = ? AND activity_files.activity_id IN ?", userID, activityIDs).
+		Order("activity_files.created_at ASC, activity_files.id ASC").
+		Scan(&attachments).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity attachments")
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// GetAttachedFileIDs returns which of the given files are already attached to an activity
+func (r *activityFileRepository) GetAttachedFileIDs(ctx context.Context, fileIDs []uint) ([]uint, error) {
+	var attached []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.ActivityFile{}).
+		Where("file_id IN ?", fileIDs).
+		Pluck("file_id", &attached).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get attached file IDs")
+		return nil, err
+	}
+	return attached, nil
+}
+
+// DetachFile removes a file from the activity and bumps its version. The upload itself is kept.
+func (r *activityFileRepository) DetachFile(ctx context.Context, activityID string, userID uint, fileID uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("activity_id = ? AND user_id = ? AND file_id = ?", activityID, userID, fileID).
+			Delete(&models.ActivityFile{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.Activity{}).
+			Where("activity_id = ? AND user_id = ?", activityID, userID).
+			Updates(map[string]interface{}{"version": gorm.Expr("version + 1"), "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Logger.Error().Err(err).Msg("Failed to detach file from activity")
+		}
+		return err
+	}
+	return nil
+}
+
+// PurgeAttachments permanently removes the files attached to activities deleted before the
+// given time and returns their storage keys. Files still used as a profile image are only
+// detached.
+func (r *activityFileRepository) PurgeAttachments(ctx context.Context, before time.Time) ([]string, error) {
+	var keys []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var fileIDs []uint
+		err := tx.Raw(`
+			DELETE FROM activity_files af
+			USING activities a
+			WHERE a.activity_id = af.activity_id AND a.deleted_at IS NOT NULL AND a.deleted_at < ?
+			RETURNING af.file_id`, before).
+			Scan(&fileIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(fileIDs) == 0 {
+			return nil
+		}
+
+		return tx.Raw(`
+			DELETE FROM files f
+			WHERE f.id IN ? AND NOT EXISTS (SELECT 1 FROM profiles p WHERE p.image_uri = f.file_url)
+			RETURNING f.file_url`, fileIDs).
+			Scan(&keys).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to purge activity attachments")
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActivityHeartRateRepository interface {
+	SaveHeartRate(ctx context.Context, heartRate *models.ActivityHeartRate) error
+	GetHeartRateByActivityID(ctx context.Context, activityID string, userID uint) (*models.ActivityHeartRate, error)
+	DeleteHeartRate(ctx context.Context, activityID string, userID uint) error
+	GetHeartRatesInRange(ctx context.Context, userID uint, from, to time.Time) ([]models.ActivityHeartRateRecord, error)
+}
+
+type activityHeartRateRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityHeartRateRepository(db *gorm.DB) ActivityHeartRateRepository {
+	return &activityHeartRateRepository{db: db}
+}
+
+// SaveHeartRate stores the heart rate of an activity, replacing any recorded before
+func (r *activityHeartRateRepository) SaveHeartRate(ctx context.Context, heartRate *models.ActivityHeartRate) error {
+	err := dbFromContext(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "activity_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"sample_count", "samples", "start_time", "end_time", "min_bpm", "avg_bpm", "max_bpm", "updated_at",
+		}),
+	}).Create(heartRate).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to save activity heart rate")
+		return err
+	}
+	return nil
+}
+
+func (r *activityHeartRateRepository) GetHeartRateByActivityID(ctx context.Context, activityID string, userID uint) (*models.ActivityHeartRate, error) {
+	var heartRate models.ActivityHeartRate
+	err := dbFromContext(ctx, r.db).Where("activity_id = ? AND user_id = ?", activityID, userID).First(&heartRate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get activity heart rate")
+		return nil, err
+	}
+	return &heartRate, nil
+}
+
+func (r *activityHeartRateRepository) DeleteHeartRate(ctx context.Context, activityID string, userID uint) error {
+	result := dbFromContext(ctx, r.db).
+		Where("activity_id = ? AND user_id = ?", activityID, userID).
+		Delete(&models.ActivityHeartRate{})
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to delete activity heart rate")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetHeartRatesInRange returns the heart rate of the user's activities done between from
+// and to that are not in the trash, in the order they were done
+func (r *activityHeartRateRepository) GetHeartRatesInRange(ctx context.Context, userID uint, from, to time.Time) ([]models.ActivityHeartRateRecord, error) {
+	var records []models.ActivityHeartRateRecord
+	err := dbFromContext(ctx, r.db).
+		Table("activity_heart_rates").
+		Select("activity_heart_rates.*, activities.done_at").
+		Joins("JOIN activities ON activities.activity_id = activity_heart_rates.activity_id AND activities.deleted_at IS NULL").
+		Where("activity_heart_rates.user_id = ? AND activities.done_at >= ? AND activities.done_at < ?", userID, from, to).
+		Order("activities.done_at ASC").
+		Scan(&records).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity heart rates")
+		return nil, err
+	}
+	return records, nil
+}
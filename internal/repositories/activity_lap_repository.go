@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type ActivityLapRepository interface {
+	GetLapsByActivityID(ctx context.Context, activityID string) ([]models.ActivityLap, error)
+}
+
+type activityLapRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityLapRepository(db *gorm.DB) ActivityLapRepository {
+	return &activityLapRepository{db: db}
+}
+
+func (r *activityLapRepository) GetLapsByActivityID(ctx context.Context, activityID string) ([]models.ActivityLap, error) {
+	var laps []models.ActivityLap
+	err := r.db.WithContext(ctx).
+		Where("activity_id = ?", activityID).
+		Order("lap_index ASC").
+		Find(&laps).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity laps")
+		return nil, err
+	}
+	return laps, nil
+}
@@ -6,18 +6,39 @@ import (
    "FitByte/pkg/log"
    "context"
    "errors"
+   "fmt"
+   "time"
 
 
    "gorm.io/gorm"
+   "gorm.io/gorm/clause"
 )
 
 
 type ActivityRepository interface {
    CreateActivity(ctx context.Context, activity models.Activity) error
+   CreateImportedActivity(ctx context.Context, activity models.Activity, track *models.ActivityTrack, heartRate *models.ActivityHeartRate, laps []models.ActivityLap) error
+   CreateActivities(ctx context.Context, activities []models.Activity) error
    GetActivitiesByUserID(ctx context.Context, userID uint, query models.GetActivitiesQuery) ([]models.Activity, error)
+   CountActivitiesByUserID(ctx context.Context, userID uint, query models.GetActivitiesQuery) (int64, error)
+   StreamActivitiesByUserID(ctx context.Context, userID uint, query models.GetActivitiesQuery, chunkSize int, fn func([]models.Activity) error) error
    GetActivityByID(ctx context.Context, activityID string, userID uint) (*models.Activity, error)
-   UpdateActivity(ctx context.Context, activityID string, userID uint, updates map[string]interface{}) error
+   GetActivityForUpdate(ctx context.Context, activityID string, userID uint) (*models.Activity, error)
+   UpdateActivity(ctx context.Context, activityID string, userID uint, expectedVersion int, updates map[string]interface{}) error
    DeleteActivity(ctx context.Context, activityID string, userID uint) error
+   GetDeletedActivities(ctx context.Context, userID uint, limit, offset int) ([]models.Activity, error)
+   RestoreActivity(ctx context.Context, activityID string, userID uint) error
+   PurgeDeletedActivities(ctx context.Context, before time.Time) (int64, error)
+   GetActivityTotalsByPeriod(ctx context.Context, userID uint, from, to time.Time, activityType, period, timeZone string) ([]models.ActivityPeriodTotals, error)
+   GetExistingSourceUUIDs(ctx context.Context, userID uint, sourceUUIDs []string) (map[string]bool, error)
+   GetTagUsage(ctx context.Context, userID uint) ([]models.TagUsage, error)
+   GetOverlappingActivities(ctx context.Context, userID uint, start, end time.Time, excludeActivityID string) ([]models.Activity, error)
+   GetOverlappingPairs(ctx context.Context, userID uint, threshold float64, limit int) ([]models.ActivityOverlap, error)
+   GetActivitiesByIDs(ctx context.Context, userID uint, activityIDs []string) ([]models.Activity, error)
+   GetActivitiesForUpdate(ctx context.Context, userID uint, activityIDs []string) ([]models.Activity, error)
+   MergeActivities(ctx context.Context, userID uint, primaryActivityID string, updates map[string]interface{}, mergedActivityIDs []string, attachmentLimit int) (bool, error)
+   GetFeedActivities(ctx context.Context, userID uint, query models.GetFeedQuery) ([]models.Activity, error)
+   GetVisibleActivities(ctx context.Context, userID uint, visibilities []string, query models.GetFeedQuery) ([]models.Activity, error)
 }
 
 
@@ -32,23 +53,181 @@ func NewActivityRepository(db *gorm.DB) ActivityRepository {
 
 
 func (r *activityRepository) CreateActivity(ctx context.Context, activity models.Activity) error {
-   err := r.db.WithContext(ctx).Create(&activity).Error
+   err := dbFromContext(ctx, r.db).Create(&activity).Error
    if err != nil {
        log.Logger.Error().Err(err).Msg("Failed to create activity")
+       return translateError(r.db, err)
+   }
+   return nil
+}
+
+
+// CreateImportedActivity stores an activity together with the GPS route, heart rate and
+// device laps of the file it was imported from, or nothing at all. track and heartRate
+// may be nil.
+func (r *activityRepository) CreateImportedActivity(ctx context.Context, activity models.Activity, track *models.ActivityTrack, heartRate *models.ActivityHeartRate, laps []models.ActivityLap) error {
+   err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+       if err := tx.Create(&activity).Error; err != nil {
+           return err
+       }
+       if track != nil {
+           if err := tx.Create(track).Error; err != nil {
+               return err
+           }
+       }
+       if heartRate != nil {
+           if err := tx.Create(heartRate).Error; err != nil {
+               return err
+           }
+       }
+       if len(laps) > 0 {
+           return tx.Create(&laps).Error
+       }
+       return nil
+   })
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to create imported activity")
        return err
    }
    return nil
 }
 
 
+func (r *activityRepository) CreateActivities(ctx context.Context, activities []models.Activity) error {
+   if len(activities) == 0 {
+       return nil
+   }
+
+
+   // A single multi-row INSERT inside a transaction, so either every row is stored or none are
+   err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+       return tx.Create(&activities).Error
+   })
+   if err != nil {
+       log.Logger.Error().Err(err).Int("count", len(activities)).Msg("Failed to create activities")
+       return translateError(r.db, err)
+   }
+   return nil
+}
+
+
 func (r *activityRepository) GetActivitiesByUserID(ctx context.Context, userID uint, query models.GetActivitiesQuery) ([]models.Activity, error) {
    var activities []models.Activity
 
 
-   db := r.db.WithContext(ctx).Where("user_id = ?", userID)
+   db := applyActivityFilters(dbFromContext(ctx, r.db), userID, query)
+
+
+   sort := query.Sort
+   if sort == "" {
+       sort = models.DefaultActivitySort
+   }
+   column, descending, ok := models.ParseActivitySort(sort)
+   if !ok {
+       column, descending, _ = models.ParseActivitySort(models.DefaultActivitySort)
+   }
+   direction, comparison := "ASC", ">"
+   if descending {
+       direction, comparison = "DESC", "<"
+   }
+
+
+   // Keyset pagination continues after the last row of the previous page; the id
+   // tie-breaker keeps rows with equal sort values from being skipped or repeated
+   if query.AfterID != 0 {
+       db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), query.AfterValue, query.AfterID)
+   } else if query.Offset > 0 {
+       db = db.Offset(query.Offset)
+   }
+
+
+   if query.Limit > 0 {
+       db = db.Limit(query.Limit)
+   }
+
+
+   db = db.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+
+
+   err := db.Find(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get activities by user ID")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
+
+
+func (r *activityRepository) CountActivitiesByUserID(ctx context.Context, userID uint, query models.GetActivitiesQuery) (int64, error) {
+   var count int64
+
+
+   err := applyActivityFilters(dbFromContext(ctx, r.db).Model(&models.Activity{}), userID, query).Count(&count).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to count activities by user ID")
+       return 0, err
+   }
+
+
+   return count, nil
+}
+
+
+func (r *activityRepository) StreamActivitiesByUserID(ctx context.Context, userID uint, query models.GetActivitiesQuery, chunkSize int, fn func([]models.Activity) error) error {
+   var (
+       lastDoneAt time.Time
+       lastID     uint
+   )
+
+
+   for {
+       var activities []models.Activity
+
+
+       db := applyActivityFilters(dbFromContext(ctx, r.db), userID, query)
+
+
+       // Keyset pagination on (done_at, id) so every chunk is an index range scan
+       if lastID != 0 {
+           db = db.Where("(done_at, id) < (?, ?)", lastDoneAt, lastID)
+       }
+
+
+       err := db.Order("done_at DESC, id DESC").Limit(chunkSize).Find(&activities).Error
+       if err != nil {
+           log.Logger.Error().Err(err).Msg("Failed to stream activities by user ID")
+           return err
+       }
+
+
+       if len(activities) == 0 {
+           return nil
+       }
+
+
+       if err := fn(activities); err != nil {
+           return err
+       }
+
+
+       if len(activities) < chunkSize {
+           return nil
+       }
+
+
+       last := activities[len(activities)-1]
+       lastDoneAt, lastID = last.DoneAt, last.ID
+   }
+}
+
+
+// applyActivityFilters scopes a query to the user's activities matching the list filters
+func applyActivityFilters(db *gorm.DB, userID uint, query models.GetActivitiesQuery) *gorm.DB {
+   db = db.Where("user_id = ?", userID)
 
 
-   // Apply filters
    if query.ActivityType != "" {
        db = db.Where("activity_type = ?", query.ActivityType)
    }
@@ -74,33 +253,33 @@ func (r *activityRepository) GetActivitiesByUserID(ctx context.Context, userID u
    }
 
 
-   // Apply pagination
-   if query.Limit > 0 {
-       db = db.Limit(query.Limit)
+   if query.DistanceMetersMin > 0 {
+       db = db.Where("distance_meters >= ?", query.DistanceMetersMin)
    }
-   if query.Offset > 0 {
-       db = db.Offset(query.Offset)
+
+
+   if query.DistanceMetersMax > 0 {
+       db = db.Where("distance_meters <= ?", query.DistanceMetersMax)
    }
 
 
-   // Order by done_at descending (most recent first)
-   db = db.Order("done_at DESC")
+   if len(query.Tags) > 0 {
+       db = db.Where("tags @> ?", models.StringArray(query.Tags))
+   }
 
 
-   err := db.Find(&activities).Error
-   if err != nil {
-       log.Logger.Error().Err(err).Msg("Failed to get activities by user ID")
-       return nil, err
+   if query.Search != "" {
+       db = db.Where("search_vector @@ websearch_to_tsquery('simple', ?)", query.Search)
    }
 
 
-   return activities, nil
+   return db
 }
 
 
 func (r *activityRepository) GetActivityByID(ctx context.Context, activityID string, userID uint) (*models.Activity, error) {
    var activity models.Activity
-   err := r.db.WithContext(ctx).Where("activity_id = ? AND user_id = ?", activityID, userID).First(&activity).Error
+   err := dbFromContext(ctx, r.db).Where("activity_id = ? AND user_id = ?", activityID, userID).First(&activity).Error
    if err != nil {
        if errors.Is(err, gorm.ErrRecordNotFound) {
            return nil, nil
@@ -112,11 +291,38 @@ func (r *activityRepository) GetActivityByID(ctx context.Context, activityID str
 }
 
 
-func (r *activityRepository) UpdateActivity(ctx context.Context, activityID string, userID uint, updates map[string]interface{}) error {
-   result := r.db.WithContext(ctx).
-       Model(&models.Activity{}).
+// GetActivityForUpdate reads the activity and locks it until the transaction the context
+// carries ends, so a change made in that transaction replaces exactly the row read here
+func (r *activityRepository) GetActivityForUpdate(ctx context.Context, activityID string, userID uint) (*models.Activity, error) {
+   var activity models.Activity
+   err := dbFromContext(ctx, r.db).
+       Clauses(clause.Locking{Strength: "UPDATE"}).
        Where("activity_id = ? AND user_id = ?", activityID, userID).
-       Updates(updates)
+       First(&activity).Error
+   if err != nil {
+       if errors.Is(err, gorm.ErrRecordNotFound) {
+           return nil, nil
+       }
+       log.Logger.Error().Err(err).Msg("Failed to get activity for update")
+       return nil, err
+   }
+   return &activity, nil
+}
+
+
+// UpdateActivity applies the updates and bumps the activity version. When expectedVersion
+// is set, the update only applies while the activity is still at that version.
+func (r *activityRepository) UpdateActivity(ctx context.Context, activityID string, userID uint, expectedVersion int, updates map[string]interface{}) error {
+   db := dbFromContext(ctx, r.db).
+       Model(&models.Activity{}).
+       Where("activity_id = ? AND user_id = ?", activityID, userID)
+   if expectedVersion > 0 {
+       db = db.Where("version = ?", expectedVersion)
+   }
+
+
+   updates["version"] = gorm.Expr("version + 1")
+   result := db.Updates(updates)
 
 
    if result.Error != nil {
@@ -135,7 +341,7 @@ func (r *activityRepository) UpdateActivity(ctx context.Context, activityID stri
 
 
 func (r *activityRepository) DeleteActivity(ctx context.Context, activityID string, userID uint) error {
-   result := r.db.WithContext(ctx).
+   result := dbFromContext(ctx, r.db).
        Where("activity_id = ? AND user_id = ?", activityID, userID).
        Delete(&models.Activity{})
 
@@ -153,3 +359,445 @@ func (r *activityRepository) DeleteActivity(ctx context.Context, activityID stri
 
    return nil
 }
+
+
+// GetDeletedActivities returns the user's activities in the trash, most recently deleted first
+func (r *activityRepository) GetDeletedActivities(ctx context.Context, userID uint, limit, offset int) ([]models.Activity, error) {
+   var activities []models.Activity
+
+
+   err := dbFromContext(ctx, r.db).
+       Unscoped().
+       Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+       Order("deleted_at DESC, id DESC").
+       Limit(limit).
+       Offset(offset).
+       Find(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get deleted activities")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
+
+
+// RestoreActivity takes an activity out of the trash
+func (r *activityRepository) RestoreActivity(ctx context.Context, activityID string, userID uint) error {
+   result := dbFromContext(ctx, r.db).
+       Unscoped().
+       Model(&models.Activity{}).
+       Where("activity_id = ? AND user_id = ? AND deleted_at IS NOT NULL", activityID, userID).
+       Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now(), "version": gorm.Expr("version + 1")})
+
+
+   if result.Error != nil {
+       log.Logger.Error().Err(result.Error).Msg("Failed to restore activity")
+       return result.Error
+   }
+
+
+   if result.RowsAffected == 0 {
+       return gorm.ErrRecordNotFound
+   }
+
+
+   return nil
+}
+
+
+// PurgeDeletedActivities permanently removes the activities deleted before the given
+// time, together with their tracks, laps and plan completions
+func (r *activityRepository) PurgeDeletedActivities(ctx context.Context, before time.Time) (int64, error) {
+   result := dbFromContext(ctx, r.db).
+       Unscoped().
+       Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+       Delete(&models.Activity{})
+   if result.Error != nil {
+       log.Logger.Error().Err(result.Error).Msg("Failed to purge deleted activities")
+       return 0, result.Error
+   }
+
+
+   return result.RowsAffected, nil
+}
+
+
+// GetActivityTotalsByPeriod sums the activities done from from to to for every week
+// (starting on Monday) or month they fall in, as seen in the given time zone. Periods
+// without activities are left out.
+func (r *activityRepository) GetActivityTotalsByPeriod(ctx context.Context, userID uint, from, to time.Time, activityType, period, timeZone string) ([]models.ActivityPeriodTotals, error) {
+   var totals []models.ActivityPeriodTotals
+
+
+   unit := "week"
+   if period == models.GoalPeriodMonthly {
+       unit = "month"
+   }
+
+
+   db := dbFromContext(ctx, r.db).
+       Model(&models.Activity{}).
+       Select("to_char(date_trunc(?, done_at AT TIME ZONE ?), 'YYYY-MM-DD') AS period_start, COUNT(*) AS sessions, COALESCE(SUM(duration_in_minutes), 0) AS active_minutes, COALESCE(SUM(calories_burned), 0) AS calories_burned", unit, timeZone).
+       Where("user_id = ? AND done_at >= ? AND done_at < ?", userID, from, to)
+
+
+   if activityType != "" {
+       db = db.Where("activity_type = ?", activityType)
+   }
+
+
+   err := db.Group("period_start").Scan(&totals).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get activity totals by period")
+       return nil, err
+   }
+
+
+   return totals, nil
+}
+
+
+func (r *activityRepository) GetExistingSourceUUIDs(ctx context.Context, userID uint, sourceUUIDs []string) (map[string]bool, error) {
+   existing := make(map[string]bool)
+   if len(sourceUUIDs) == 0 {
+       return existing, nil
+   }
+
+
+   var found []string
+
+
+   // Unscoped so that activities the user deleted are not imported again
+   err := dbFromContext(ctx, r.db).
+       Unscoped().
+       Model(&models.Activity{}).
+       Where("user_id = ? AND source_uuid IN ?", userID, sourceUUIDs).
+       Pluck("source_uuid", &found).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get existing source UUIDs")
+       return nil, err
+   }
+
+
+   for _, sourceUUID := range found {
+       existing[sourceUUID] = true
+   }
+
+
+   return existing, nil
+}
+
+
+func (r *activityRepository) GetTagUsage(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+   var usage []models.TagUsage
+
+
+   err := dbFromContext(ctx, r.db).
+       Raw(`SELECT tag, COUNT(*) AS count
+           FROM activities, unnest(tags) AS tag
+           WHERE user_id = ? AND deleted_at IS NULL
+           GROUP BY tag
+           ORDER BY count DESC, tag ASC`, userID).
+       Scan(&usage).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get tag usage")
+       return nil, err
+   }
+
+
+   return usage, nil
+}
+
+
+// activityEndExpr is the SQL expression for when an activity finished
+const activityEndExpr = "done_at + duration_in_minutes * INTERVAL '1 minute'"
+
+
+func (r *activityRepository) GetOverlappingActivities(ctx context.Context, userID uint, start, end time.Time, excludeActivityID string) ([]models.Activity, error) {
+   var activities []models.Activity
+
+
+   db := dbFromContext(ctx, r.db).
+       Where("user_id = ? AND done_at < ? AND "+activityEndExpr+" > ?", userID, end, start)
+
+
+   if excludeActivityID != "" {
+       db = db.Where("activity_id <> ?", excludeActivityID)
+   }
+
+
+   err := db.Order("done_at ASC, id ASC").Find(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get overlapping activities")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
+
+
+// GetOverlappingPairs finds pairs of the user's activities where at least threshold of
+// the shorter activity is covered by the other one, most recent first
+func (r *activityRepository) GetOverlappingPairs(ctx context.Context, userID uint, threshold float64, limit int) ([]models.ActivityOverlap, error) {
+   var overlaps []models.ActivityOverlap
+
+
+   err := dbFromContext(ctx, r.db).
+       Raw(`SELECT first_activity_id, second_activity_id, overlap_minutes, shorter_minutes
+           FROM (
+               SELECT a.activity_id AS first_activity_id,
+                   b.activity_id AS second_activity_id,
+                   EXTRACT(EPOCH FROM LEAST(a.done_at + a.duration_in_minutes * INTERVAL '1 minute', b.done_at + b.duration_in_minutes * INTERVAL '1 minute')
+                       - GREATEST(a.done_at, b.done_at)) / 60 AS overlap_minutes,
+                   LEAST(a.duration_in_minutes, b.duration_in_minutes) AS shorter_minutes,
+                   GREATEST(a.done_at, b.done_at) AS overlap_start
+               FROM activities a
+               JOIN activities b ON b.user_id = a.user_id AND b.id > a.id
+               WHERE a.user_id = ? AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+                   AND a.done_at < b.done_at + b.duration_in_minutes * INTERVAL '1 minute'
+                   AND b.done_at < a.done_at + a.duration_in_minutes * INTERVAL '1 minute'
+           ) pairs
+           WHERE overlap_minutes >= ? * shorter_minutes
+           ORDER BY overlap_start DESC
+           LIMIT ?`, userID, threshold, limit).
+       Scan(&overlaps).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get overlapping activity pairs")
+       return nil, err
+   }
+
+
+   return overlaps, nil
+}
+
+
+func (r *activityRepository) GetActivitiesByIDs(ctx context.Context, userID uint, activityIDs []string) ([]models.Activity, error) {
+   var activities []models.Activity
+   if len(activityIDs) == 0 {
+       return activities, nil
+   }
+
+
+   err := dbFromContext(ctx, r.db).
+       Where("user_id = ? AND activity_id IN ?", userID, activityIDs).
+       Order("done_at ASC, id ASC").
+       Find(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get activities by IDs")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
+
+
+// GetActivitiesForUpdate is GetActivitiesByIDs locking the activities until the
+// transaction the context carries ends. They are locked in a fixed order, so two
+// transactions locking some of the same activities cannot deadlock.
+func (r *activityRepository) GetActivitiesForUpdate(ctx context.Context, userID uint, activityIDs []string) ([]models.Activity, error) {
+   var activities []models.Activity
+   if len(activityIDs) == 0 {
+       return activities, nil
+   }
+
+
+   err := dbFromContext(ctx, r.db).
+       Clauses(clause.Locking{Strength: "UPDATE"}).
+       Where("user_id = ? AND activity_id IN ?", userID, activityIDs).
+       Order("done_at ASC, id ASC").
+       Find(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get activities for update")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
+
+
+// MergeActivities applies the combined values to the primary activity and deletes the
+// merged ones in a single transaction. When the primary activity has no GPS track, heart
+// rate or laps of its own, it takes over those of a merged activity. It merges nothing
+// and returns false when the photos of all activities exceed attachmentLimit.
+func (r *activityRepository) MergeActivities(ctx context.Context, userID uint, primaryActivityID string, updates map[string]interface{}, mergedActivityIDs []string, attachmentLimit int) (bool, error) {
+   updates["version"] = gorm.Expr("version + 1")
+   err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+       result := tx.Model(&models.Activity{}).
+           Where("activity_id = ? AND user_id = ?", primaryActivityID, userID).
+           Updates(updates)
+       if result.Error != nil {
+           return result.Error
+       }
+       if result.RowsAffected == 0 {
+           return gorm.ErrRecordNotFound
+       }
+
+
+       var trackCount int64
+       if err := tx.Model(&models.ActivityTrack{}).Where("activity_id = ?", primaryActivityID).Count(&trackCount).Error; err != nil {
+           return err
+       }
+       if trackCount == 0 {
+           // The most detailed route wins when several merged activities have one
+           var track models.ActivityTrack
+           err := tx.Where("activity_id IN ?", mergedActivityIDs).Order("point_count DESC").Limit(1).Find(&track).Error
+           if err != nil {
+               return err
+           }
+           if track.ID != 0 {
+               if err := tx.Model(&track).Update("activity_id", primaryActivityID).Error; err != nil {
+                   return err
+               }
+           }
+       }
+
+
+       var heartRateCount int64
+       if err := tx.Model(&models.ActivityHeartRate{}).Where("activity_id = ?", primaryActivityID).Count(&heartRateCount).Error; err != nil {
+           return err
+       }
+       if heartRateCount == 0 {
+           var heartRate models.ActivityHeartRate
+           err := tx.Where("activity_id IN ?", mergedActivityIDs).Order("sample_count DESC").Limit(1).Find(&heartRate).Error
+           if err != nil {
+               return err
+           }
+           if heartRate.ID != 0 {
+               if err := tx.Model(&heartRate).Update("activity_id", primaryActivityID).Error; err != nil {
+                   return err
+               }
+           }
+       }
+
+
+       var lapCount int64
+       if err := tx.Model(&models.ActivityLap{}).Where("activity_id = ?", primaryActivityID).Count(&lapCount).Error; err != nil {
+           return err
+       }
+       if lapCount == 0 {
+           var lap models.ActivityLap
+           err := tx.Where("activity_id IN ?", mergedActivityIDs).Order("id ASC").Limit(1).Find(&lap).Error
+           if err != nil {
+               return err
+           }
+           if lap.ID != 0 {
+               err := tx.Model(&models.ActivityLap{}).Where("activity_id = ?", lap.ActivityID).Update("activity_id", primaryActivityID).Error
+               if err != nil {
+                   return err
+               }
+           }
+       }
+
+
+       result = tx.Where("activity_id IN ? AND user_id = ?", mergedActivityIDs, userID).Delete(&models.Activity{})
+       if result.Error != nil {
+           return result.Error
+       }
+       if result.RowsAffected != int64(len(mergedActivityIDs)) {
+           return gorm.ErrRecordNotFound
+       }
+
+
+       // Photos of every merged activity stay with the combined one. All activities are
+       // locked by now, so no attach can run between the count and the move.
+       var fileCount int64
+       err := tx.Model(&models.ActivityFile{}).
+           Where("activity_id IN ?", append([]string{primaryActivityID}, mergedActivityIDs...)).
+           Count(&fileCount).Error
+       if err != nil {
+           return err
+       }
+       if int(fileCount) > attachmentLimit {
+           return errAttachmentLimit
+       }
+
+
+       return tx.Model(&models.ActivityFile{}).
+           Where("activity_id IN ?", mergedActivityIDs).
+           Update("activity_id", primaryActivityID).Error
+   })
+   if errors.Is(err, errAttachmentLimit) {
+       return false, nil
+   }
+   if err != nil {
+       if !errors.Is(err, gorm.ErrRecordNotFound) {
+           log.Logger.Error().Err(err).Msg("Failed to merge activities")
+       }
+       return false, err
+   }
+   return true, nil
+}
+
+
+// GetFeedActivities returns the most recent shared activities of the users the user
+// follows, newest first. Each followed user contributes at most a page of their latest
+// activities from the feed index, so the cost grows with the number of followed users
+// times the page size rather than with how many activities they have.
+func (r *activityRepository) GetFeedActivities(ctx context.Context, userID uint, query models.GetFeedQuery) ([]models.Activity, error) {
+   var activities []models.Activity
+
+
+   keyset, args := "", []interface{}{}
+   if query.BeforeID != 0 {
+       keyset = "AND (activities.done_at, activities.id) < (?, ?)"
+       args = append(args, query.BeforeDoneAt, query.BeforeID)
+   }
+   args = append(args, query.Limit, userID, models.FollowStatusAccepted, query.Limit)
+
+
+   err := dbFromContext(ctx, r.db).Raw(fmt.Sprintf(`
+       SELECT feed.* FROM follows
+       CROSS JOIN LATERAL (
+           SELECT * FROM activities
+           WHERE activities.user_id = follows.followee_id
+               AND activities.deleted_at IS NULL
+               AND activities.visibility <> 'private'
+               %s
+           ORDER BY activities.done_at DESC, activities.id DESC
+           LIMIT ?
+       ) AS feed
+       WHERE follows.follower_id = ? AND follows.status = ?
+       ORDER BY feed.done_at DESC, feed.id DESC
+       LIMIT ?`, keyset), args...).
+       Scan(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get feed activities")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
+
+
+// GetVisibleActivities returns the most recent activities of the user with one of the
+// given visibilities, newest first
+func (r *activityRepository) GetVisibleActivities(ctx context.Context, userID uint, visibilities []string, query models.GetFeedQuery) ([]models.Activity, error) {
+   var activities []models.Activity
+
+
+   db := dbFromContext(ctx, r.db).
+       Where("user_id = ? AND visibility IN ?", userID, visibilities)
+
+
+   if query.BeforeID != 0 {
+       db = db.Where("(done_at, id) < (?, ?)", query.BeforeDoneAt, query.BeforeID)
+   }
+
+
+   err := db.Order("done_at DESC, id DESC").
+       Limit(query.Limit).
+       Find(&activities).Error
+   if err != nil {
+       log.Logger.Error().Err(err).Msg("Failed to get visible activities")
+       return nil, err
+   }
+
+
+   return activities, nil
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type ActivityRevisionRepository interface {
+	CreateRevisions(ctx context.Context, revisions []models.ActivityRevision) error
+	GetRevisions(ctx context.Context, activityID string, userID uint) ([]models.ActivityRevision, error)
+	GetRevision(ctx context.Context, activityID string, userID uint, revision int) (*models.ActivityRevision, error)
+}
+
+type activityRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRevisionRepository(db *gorm.DB) ActivityRevisionRepository {
+	return &activityRevisionRepository{db: db}
+}
+
+// CreateRevisions numbers and saves revisions. The first revision of a new activity is
+// numbered 1; later ones lock their activity so that concurrent changes are numbered
+// one after the other.
+func (r *activityRevisionRepository) CreateRevisions(ctx context.Context, revisions []models.ActivityRevision) error {
+	err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var created []models.ActivityRevision
+		for _, revision := range revisions {
+			if revision.Operation == models.RevisionOperationCreate {
+				revision.Revision = 1
+				created = append(created, revision)
+				continue
+			}
+
+			err := tx.Exec("SELECT 1 FROM activities WHERE activity_id = ? FOR UPDATE", revision.ActivityID).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.ActivityRevision{}).
+				Select("COALESCE(MAX(revision), 0) + 1").
+				Where("activity_id = ?", revision.ActivityID).
+				Scan(&revision.Revision).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&revision).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(created) > 0 {
+			return tx.CreateInBatches(&created, 500).Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create activity revisions")
+		return err
+	}
+	return nil
+}
+
+func (r *activityRevisionRepository) GetRevisions(ctx context.Context, activityID string, userID uint) ([]models.ActivityRevision, error) {
+	var revisions []models.ActivityRevision
+	err := dbFromContext(ctx, r.db).
+		Where("activity_id = ? AND user_id = ?", activityID, userID).
+		Order("revision ASC").
+		Find(&revisions).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity revisions")
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *activityRevisionRepository) GetRevision(ctx context.Context, activityID string, userID uint, revision int) (*models.ActivityRevision, error) {
+	var found models.ActivityRevision
+	err := dbFromContext(ctx, r.db).
+		Where("activity_id = ? AND user_id = ? AND revision = ?", activityID, userID, revision).
+		First(&found).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get activity revision")
+		return nil, err
+	}
+	return &found, nil
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type ActivityTrackRepository interface {
+	GetTrackByActivityID(ctx context.Context, activityID string, userID uint) (*models.ActivityTrack, error)
+}
+
+type activityTrackRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityTrackRepository(db *gorm.DB) ActivityTrackRepository {
+	return &activityTrackRepository{db: db}
+}
+
+func (r *activityTrackRepository) GetTrackByActivityID(ctx context.Context, activityID string, userID uint) (*models.ActivityTrack, error) {
+	var track models.ActivityTrack
+	err := r.db.WithContext(ctx).Where("activity_id = ? AND user_id = ?", activityID, userID).First(&track).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get activity track")
+		return nil, err
+	}
+	return &track, nil
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// translateError turns a driver error into the matching gorm error, so that callers can
+// recognise a unique index violation as gorm.ErrDuplicatedKey
+func translateError(db *gorm.DB, err error) error {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return translator.Translate(err)
+	}
+	return err
+}
@@ -4,12 +4,16 @@ import (
 	"FitByte/internal/models"
 	"FitByte/pkg/log"
 	"context"
+	"errors"
 
 	"gorm.io/gorm"
 )
 
 type FileRepository interface {
-	Insert(ctx context.Context, file models.File) error
+	Insert(ctx context.Context, file *models.File) error
+	GetByID(ctx context.Context, fileID uint) (*models.File, error)
+	GetByIDs(ctx context.Context, fileIDs []uint) ([]models.File, error)
+	Delete(ctx context.Context, fileID uint) error
 }
 
 type fileRepository struct {
@@ -22,11 +26,43 @@ func NewFileRepository(db *gorm.DB) FileRepository {
 	}
 }
 
-func (r *fileRepository) Insert(ctx context.Context, file models.File) error {
-	err := r.db.Table("files").WithContext(ctx).Create(&file).Error
+func (r *fileRepository) Insert(ctx context.Context, file *models.File) error {
+	err := r.db.Table("files").WithContext(ctx).Create(file).Error
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("Failed to insert file")
 		return err
 	}
 	return nil
 }
+
+func (r *fileRepository) GetByID(ctx context.Context, fileID uint) (*models.File, error) {
+	var file models.File
+	err := r.db.Table("files").WithContext(ctx).Where("id = ?", fileID).First(&file).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get file by ID")
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *fileRepository) GetByIDs(ctx context.Context, fileIDs []uint) ([]models.File, error) {
+	var files []models.File
+	err := r.db.Table("files").WithContext(ctx).Where("id IN ?", fileIDs).Find(&files).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get files by IDs")
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *fileRepository) Delete(ctx context.Context, fileID uint) error {
+	err := r.db.Table("files").WithContext(ctx).Unscoped().Where("id = ?", fileID).Delete(&models.File{}).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to delete file")
+		return err
+	}
+	return nil
+}
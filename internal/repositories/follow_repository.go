@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository interface {
+	CreateFollow(ctx context.Context, follow *models.Follow) (bool, error)
+	GetFollow(ctx context.Context, followerID, followeeID uint) (*models.Follow, error)
+	DeleteFollow(ctx context.Context, followerID, followeeID uint, status string) error
+	AcceptFollow(ctx context.Context, followerID, followeeID uint) error
+	AcceptPendingFollows(ctx context.Context, followeeID uint) (int64, error)
+	GetFollowers(ctx context.Context, followeeID uint, status string, limit, offset int) ([]models.FollowUser, error)
+	GetFollowing(ctx context.Context, followerID uint, limit, offset int) ([]models.FollowUser, error)
+}
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+// CreateFollow stores the follow unless the follower already follows or asked to follow
+// the followee, and reports whether it did
+func (r *followRepository) CreateFollow(ctx context.Context, follow *models.Follow) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to create follow")
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *followRepository) GetFollow(ctx context.Context, followerID, followeeID uint) (*models.Follow, error) {
+	var follow models.Follow
+	err := r.db.WithContext(ctx).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).First(&follow).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get follow")
+		return nil, err
+	}
+	return &follow, nil
+}
+
+// DeleteFollow removes the follow, only while it has the given status unless status is
+// empty
+func (r *followRepository) DeleteFollow(ctx context.Context, followerID, followeeID uint, status string) error {
+	db := r.db.WithContext(ctx).Where("follower_id = ? AND followee_id = ?", followerID, followeeID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	result := db.Delete(&models.Follow{})
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to delete follow")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AcceptFollow turns a pending follow request into a follow
+func (r *followRepository) AcceptFollow(ctx context.Context, followerID, followeeID uint) error {
+	result := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Where("follower_id = ? AND followee_id = ? AND status = ?", followerID, followeeID, models.FollowStatusPending).
+		Updates(map[string]interface{}{"status": models.FollowStatusAccepted, "accepted_at": time.Now()})
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to accept follow")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AcceptPendingFollows accepts every follow request to the followee and returns how many
+// there were
+func (r *followRepository) AcceptPendingFollows(ctx context.Context, followeeID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Where("followee_id = ? AND status = ?", followeeID, models.FollowStatusPending).
+		Updates(map[string]interface{}{"status": models.FollowStatusAccepted, "accepted_at": time.Now()})
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to accept pending follows")
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetFollowers returns the users following the followee with the given status, most
+// recent first
+func (r *followRepository) GetFollowers(ctx context.Context, followeeID uint, status string, limit, offset int) ([]models.FollowUser, error) {
+	var users []models.FollowUser
+	err := r.followUsers(ctx, "follows.follower_id").
+		Where("follows.followee_id = ? AND follows.status = ?", followeeID, status).
+		Order("follows.created_at DESC, follows.follower_id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&users).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get followers")
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetFollowing returns the users the follower follows or asked to follow, most recent
+// first
+func (r *followRepository) GetFollowing(ctx context.Context, followerID uint, limit, offset int) ([]models.FollowUser, error) {
+	var users []models.FollowUser
+	err := r.followUsers(ctx, "follows.followee_id").
+		Where("follows.follower_id = ?", followerID).
+		Order("follows.created_at DESC, follows.followee_id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&users).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get followed users")
+		return nil, err
+	}
+	return users, nil
+}
+
+// followUsers selects follows together with the profile of the user in the given column
+func (r *followRepository) followUsers(ctx context.Context, userColumn string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("follows").
+		Select("profiles.id AS user_id, profiles.name, profiles.image_uri, follows.status, follows.created_at").
+		Joins("JOIN profiles ON profiles.id = " + userColumn + " AND profiles.deleted_at IS NULL")
+}
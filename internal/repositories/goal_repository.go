@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type GoalRepository interface {
+	CreateGoal(ctx context.Context, goal models.Goal) error
+	GetGoalsByUserID(ctx context.Context, userID uint) ([]models.Goal, error)
+	GetGoalByID(ctx context.Context, goalID string, userID uint) (*models.Goal, error)
+	UpdateGoal(ctx context.Context, goalID string, userID uint, updates map[string]interface{}) error
+	DeleteGoal(ctx context.Context, goalID string, userID uint) error
+}
+
+type goalRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalRepository(db *gorm.DB) GoalRepository {
+	return &goalRepository{db: db}
+}
+
+func (r *goalRepository) CreateGoal(ctx context.Context, goal models.Goal) error {
+	err := r.db.WithContext(ctx).Create(&goal).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create goal")
+		return err
+	}
+	return nil
+}
+
+func (r *goalRepository) GetGoalsByUserID(ctx context.Context, userID uint) ([]models.Goal, error) {
+	var goals []models.Goal
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&goals).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get goals by user ID")
+		return nil, err
+	}
+	return goals, nil
+}
+
+func (r *goalRepository) GetGoalByID(ctx context.Context, goalID string, userID uint) (*models.Goal, error) {
+	var goal models.Goal
+	err := r.db.WithContext(ctx).Where("goal_id = ? AND user_id = ?", goalID, userID).First(&goal).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get goal by ID")
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *goalRepository) UpdateGoal(ctx context.Context, goalID string, userID uint, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Goal{}).
+		Where("goal_id = ? AND user_id = ?", goalID, userID).
+		Updates(updates)
+
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to update goal")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *goalRepository) DeleteGoal(ctx context.Context, goalID string, userID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("goal_id = ? AND user_id = ?", goalID, userID).
+		Delete(&models.Goal{})
+
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to delete goal")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
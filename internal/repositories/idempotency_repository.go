@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, userID uint, key string) (*models.IdempotencyKey, error)
+	UpdateIdempotencyKey(ctx context.Context, id uint, updates map[string]interface{}) error
+	DeleteIdempotencyKey(ctx context.Context, id uint) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// CreateIdempotencyKey inserts the key unless the user already has a row for it, relying
+// on the unique index so that only one of several concurrent requests wins. It reports
+// whether the row was inserted.
+func (r *idempotencyRepository) CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to create idempotency key")
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *idempotencyRepository) GetIdempotencyKey(ctx context.Context, userID uint, key string) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	err := r.db.WithContext(ctx).Where("user_id = ? AND idempotency_key = ?", userID, key).First(&idempotencyKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get idempotency key")
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+func (r *idempotencyRepository) UpdateIdempotencyKey(ctx context.Context, id uint, updates map[string]interface{}) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to update idempotency key")
+		return err
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteIdempotencyKey(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Delete(&models.IdempotencyKey{}, id).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to delete idempotency key")
+		return err
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to delete expired idempotency keys")
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	CreateImportJob(ctx context.Context, job *models.ImportJob) error
+	GetImportJobByID(ctx context.Context, jobID string, userID uint) (*models.ImportJob, error)
+	UpdateImportJob(ctx context.Context, jobID string, updates map[string]interface{}) error
+	FailUnfinishedImportJobs(ctx context.Context, message string) (int64, error)
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db: db}
+}
+
+func (r *importJobRepository) CreateImportJob(ctx context.Context, job *models.ImportJob) error {
+	err := r.db.WithContext(ctx).Create(job).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create import job")
+		return err
+	}
+	return nil
+}
+
+func (r *importJobRepository) GetImportJobByID(ctx context.Context, jobID string, userID uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.WithContext(ctx).Where("job_id = ? AND user_id = ?", jobID, userID).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get import job by ID")
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *importJobRepository) UpdateImportJob(ctx context.Context, jobID string, updates map[string]interface{}) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.ImportJob{}).
+		Where("job_id = ?", jobID).
+		Updates(updates).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to update import job")
+		return err
+	}
+	return nil
+}
+
+// FailUnfinishedImportJobs marks every job that is still pending or running as failed
+// with the given message and returns how many there were
+func (r *importJobRepository) FailUnfinishedImportJobs(ctx context.Context, message string) (int64, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.ImportJob{}).
+		Where("status IN ?", []string{models.ImportJobStatusPending, models.ImportJobStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ImportJobStatusFailed,
+			"error":       message,
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to fail unfinished import jobs")
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -4,12 +4,15 @@ import (
 	"FitByte/internal/models"
 	"FitByte/pkg/log"
 	"context"
+	"io"
 
 	"github.com/minio/minio-go/v7"
 )
 
 type MinioRepository interface {
 	UploadFile(ctx context.Context, fileMetadata models.UploadFile) (string, error)
+	GetFile(ctx context.Context, key string) (io.ReadCloser, error)
+	RemoveFile(ctx context.Context, key string) error
 }
 
 type minioRepository struct {
@@ -43,3 +46,23 @@ func (r *minioRepository) UploadFile(ctx context.Context, fileMetadata models.Up
 
 	return info.Key, nil
 }
+
+func (r *minioRepository) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := r.client.GetObject(ctx, r.bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		log.Logger.Error().Err(err).Str("key", key).Msg("failed to get file")
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (r *minioRepository) RemoveFile(ctx context.Context, key string) error {
+	err := r.client.RemoveObject(ctx, r.bucketName, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		log.Logger.Error().Err(err).Str("key", key).Msg("failed to remove file")
+		return err
+	}
+
+	return nil
+}
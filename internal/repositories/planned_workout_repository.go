@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PlannedWorkoutRepository interface {
+	CreatePlannedWorkout(ctx context.Context, workout models.PlannedWorkout) error
+	GetPlannedWorkoutsByUserID(ctx context.Context, userID uint) ([]models.PlannedWorkout, error)
+	GetPlannedWorkoutsInRange(ctx context.Context, userID uint, from, to time.Time, activityType string) ([]models.PlannedWorkout, error)
+	GetPlannedWorkoutByID(ctx context.Context, plannedWorkoutID string, userID uint) (*models.PlannedWorkout, error)
+	UpdatePlannedWorkout(ctx context.Context, plannedWorkoutID string, userID uint, updates map[string]interface{}) error
+	DeletePlannedWorkout(ctx context.Context, plannedWorkoutID string, userID uint) error
+	CreateCompletion(ctx context.Context, completion *models.PlannedWorkoutCompletion) (bool, error)
+	GetCompletionsInRange(ctx context.Context, userID uint, from, to time.Time) ([]models.PlannedWorkoutCompletion, error)
+}
+
+type plannedWorkoutRepository struct {
+	db *gorm.DB
+}
+
+func NewPlannedWorkoutRepository(db *gorm.DB) PlannedWorkoutRepository {
+	return &plannedWorkoutRepository{db: db}
+}
+
+func (r *plannedWorkoutRepository) CreatePlannedWorkout(ctx context.Context, workout models.PlannedWorkout) error {
+	err := dbFromContext(ctx, r.db).Create(&workout).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create planned workout")
+		return err
+	}
+	return nil
+}
+
+func (r *plannedWorkoutRepository) GetPlannedWorkoutsByUserID(ctx context.Context, userID uint) ([]models.PlannedWorkout, error) {
+	var workouts []models.PlannedWorkout
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("scheduled_date ASC, id ASC").
+		Find(&workouts).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workouts by user ID")
+		return nil, err
+	}
+	return workouts, nil
+}
+
+// GetPlannedWorkoutsInRange returns the workouts that may occur between from and to,
+// that is those starting by to and not ending before from. activityType is optional.
+func (r *plannedWorkoutRepository) GetPlannedWorkoutsInRange(ctx context.Context, userID uint, from, to time.Time, activityType string) ([]models.PlannedWorkout, error) {
+	var workouts []models.PlannedWorkout
+
+	db := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND scheduled_date <= ? AND (last_date IS NULL OR last_date >= ?)", userID, to, from)
+
+	if activityType != "" {
+		db = db.Where("activity_type = ?", activityType)
+	}
+
+	err := db.Order("scheduled_date ASC, id ASC").Find(&workouts).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workouts in range")
+		return nil, err
+	}
+	return workouts, nil
+}
+
+func (r *plannedWorkoutRepository) GetPlannedWorkoutByID(ctx context.Context, plannedWorkoutID string, userID uint) (*models.PlannedWorkout, error) {
+	var workout models.PlannedWorkout
+	err := dbFromContext(ctx, r.db).Where("planned_workout_id = ? AND user_id = ?", plannedWorkoutID, userID).First(&workout).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get planned workout by ID")
+		return nil, err
+	}
+	return &workout, nil
+}
+
+func (r *plannedWorkoutRepository) UpdatePlannedWorkout(ctx context.Context, plannedWorkoutID string, userID uint, updates map[string]interface{}) error {
+	result := dbFromContext(ctx, r.db).
+		Model(&models.PlannedWorkout{}).
+		Where("planned_workout_id = ? AND user_id = ?", plannedWorkoutID, userID).
+		Updates(updates)
+
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to update planned workout")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *plannedWorkoutRepository) DeletePlannedWorkout(ctx context.Context, plannedWorkoutID string, userID uint) error {
+	result := dbFromContext(ctx, r.db).
+		Where("planned_workout_id = ? AND user_id = ?", plannedWorkoutID, userID).
+		Delete(&models.PlannedWorkout{})
+
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to delete planned workout")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateCompletion marks an occurrence as completed unless another activity already
+// completed it, and reports whether it did. An occurrence whose activity was deleted
+// since is taken over.
+func (r *plannedWorkoutRepository) CreateCompletion(ctx context.Context, completion *models.PlannedWorkoutCompletion) (bool, error) {
+	result := dbFromContext(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "planned_workout_id"}, {Name: "occurrence_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"activity_id", "created_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{
+			SQL: "NOT EXISTS (SELECT 1 FROM activities a WHERE a.activity_id = planned_workout_completions.activity_id AND a.deleted_at IS NULL)",
+		}}},
+	}).Create(completion)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to create planned workout completion")
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetCompletionsInRange returns the completions of occurrences between from and to whose
+// activity has not been deleted since
+func (r *plannedWorkoutRepository) GetCompletionsInRange(ctx context.Context, userID uint, from, to time.Time) ([]models.PlannedWorkoutCompletion, error) {
+	var completions []models.PlannedWorkoutCompletion
+	err := dbFromContext(ctx, r.db).
+		Table("planned_workout_completions AS c").
+		Select("c.*").
+		Joins("JOIN activities a ON a.activity_id = c.activity_id AND a.deleted_at IS NULL").
+		Where("c.user_id = ? AND c.occurrence_date BETWEEN ? AND ?", userID, from, to).
+		Order("c.occurrence_date ASC, c.id ASC").
+		Scan(&completions).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workout completions")
+		return nil, err
+	}
+	return completions, nil
+}
@@ -13,8 +13,9 @@ import (
 type ProfileRepository interface {
 	CreateUser(ctx context.Context, profile *models.Profile) error
 	GetProfileByEmail(ctx context.Context, email string) (*models.Profile, error)
-	UpdateUser(ctx context.Context, userID uint, updates map[string]interface{}) error
+	UpdateUser(ctx context.Context, userID uint, expectedVersion int, updates map[string]interface{}) error
 	GetProfileByID(ctx context.Context, userID uint) (*models.Profile, error)
+	GetProfilesByIDs(ctx context.Context, userIDs []uint) ([]models.Profile, error)
 }
 
 type profileRepository struct {
@@ -26,7 +27,7 @@ func NewProfileRepository(db *gorm.DB) ProfileRepository {
 }
 
 func (r *profileRepository) CreateUser(ctx context.Context, profile *models.Profile) error {
-	err := r.db.WithContext(ctx).Create(profile).Error
+	err := dbFromContext(ctx, r.db).Create(profile).Error
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("Failed to create profile")
 		return err
@@ -36,7 +37,7 @@ func (r *profileRepository) CreateUser(ctx context.Context, profile *models.Prof
 
 func (r *profileRepository) GetProfileByEmail(ctx context.Context, email string) (*models.Profile, error) {
 	var profile models.Profile
-	err := r.db.Table("profiles").WithContext(ctx).Where("email = ?", email).First(&profile).Error
+	err := dbFromContext(ctx, r.db).Table("profiles").Where("email = ?", email).First(&profile).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -47,17 +48,28 @@ func (r *profileRepository) GetProfileByEmail(ctx context.Context, email string)
 	return &profile, nil
 }
 
-func (r *profileRepository) UpdateUser(ctx context.Context, userID uint, updates map[string]interface{}) error {
-	err := r.db.Table("profiles").WithContext(ctx).Where("id = ?", userID).Updates(updates).Error
-	if err != nil {
-		log.Logger.Error().Err(err).Msg("Failed to update profile user")
-		return err
+// UpdateUser applies the updates and bumps the profile version. When expectedVersion is
+// set, the update only applies while the profile is still at that version.
+func (r *profileRepository) UpdateUser(ctx context.Context, userID uint, expectedVersion int, updates map[string]interface{}) error {
+	db := dbFromContext(ctx, r.db).Table("profiles").Where("id = ?", userID)
+	if expectedVersion > 0 {
+		db = db.Where("version = ?", expectedVersion)
+	}
+
+	updates["version"] = gorm.Expr("version + 1")
+	result := db.Updates(updates)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to update profile user")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 func (r *profileRepository) GetProfileByID(ctx context.Context, userID uint ) (*models.Profile, error) {
 	var profile models.Profile
-	err := r.db.Table("profiles").WithContext(ctx).Where("id = ?", userID).First(&profile).Error
+	err := dbFromContext(ctx, r.db).Table("profiles").Where("id = ?", userID).First(&profile).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -66,4 +78,18 @@ func (r *profileRepository) GetProfileByID(ctx context.Context, userID uint ) (*
 		return nil, err
 	}
 	return &profile, nil
-}
\ No newline at end of file
+}
+
+func (r *profileRepository) GetProfilesByIDs(ctx context.Context, userIDs []uint) ([]models.Profile, error) {
+	var profiles []models.Profile
+	if len(userIDs) == 0 {
+		return profiles, nil
+	}
+
+	err := dbFromContext(ctx, r.db).Table("profiles").Where("id IN ?", userIDs).Find(&profiles).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get profiles by IDs")
+		return nil, err
+	}
+	return profiles, nil
+}
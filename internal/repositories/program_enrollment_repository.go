@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProgramEnrollmentRepository interface {
+	CreateEnrollment(ctx context.Context, enrollment models.ProgramEnrollment, workouts []models.PlannedWorkout) error
+	GetEnrollmentsByUserID(ctx context.Context, userID uint) ([]models.ProgramEnrollment, error)
+	GetEnrollmentByID(ctx context.Context, enrollmentID string, userID uint) (*models.ProgramEnrollment, error)
+	GetActiveEnrollment(ctx context.Context, userID uint, programID string) (*models.ProgramEnrollment, error)
+	GetActiveEnrollments(ctx context.Context) ([]models.ProgramEnrollment, error)
+	GetEnrollmentWeeks(ctx context.Context, enrollmentID string) ([]models.ProgramEnrollmentWeek, error)
+	CountSessions(ctx context.Context, enrollmentID string, from, to time.Time) (planned int, completed int, err error)
+	AdvanceEnrollment(ctx context.Context, enrollment models.ProgramEnrollment, updates map[string]interface{}, week models.ProgramEnrollmentWeek, rescheduleFrom *time.Time, workouts []models.PlannedWorkout) (bool, error)
+	CancelEnrollment(ctx context.Context, enrollmentID string, userID uint, from time.Time) error
+}
+
+type programEnrollmentRepository struct {
+	db *gorm.DB
+}
+
+func NewProgramEnrollmentRepository(db *gorm.DB) ProgramEnrollmentRepository {
+	return &programEnrollmentRepository{db: db}
+}
+
+// CreateEnrollment saves an enrollment together with the planned workouts of its schedule
+func (r *programEnrollmentRepository) CreateEnrollment(ctx context.Context, enrollment models.ProgramEnrollment, workouts []models.PlannedWorkout) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		if len(workouts) > 0 {
+			return tx.Create(&workouts).Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create program enrollment")
+		return translateError(r.db, err)
+	}
+	return nil
+}
+
+func (r *programEnrollmentRepository) GetEnrollmentsByUserID(ctx context.Context, userID uint) ([]models.ProgramEnrollment, error) {
+	var enrollments []models.ProgramEnrollment
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("start_date DESC, id DESC").
+		Find(&enrollments).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollments by user ID")
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func (r *programEnrollmentRepository) GetEnrollmentByID(ctx context.Context, enrollmentID string, userID uint) (*models.ProgramEnrollment, error) {
+	var enrollment models.ProgramEnrollment
+	err := r.db.WithContext(ctx).Where("enrollment_id = ? AND user_id = ?", enrollmentID, userID).First(&enrollment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollment by ID")
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func (r *programEnrollmentRepository) GetActiveEnrollment(ctx context.Context, userID uint, programID string) (*models.ProgramEnrollment, error) {
+	var enrollment models.ProgramEnrollment
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND program_id = ? AND status = ?", userID, programID, models.EnrollmentStatusActive).
+		First(&enrollment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get active program enrollment")
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func (r *programEnrollmentRepository) GetActiveEnrollments(ctx context.Context) ([]models.ProgramEnrollment, error) {
+	var enrollments []models.ProgramEnrollment
+	err := r.db.WithContext(ctx).
+		Where("status = ?", models.EnrollmentStatusActive).
+		Order("id ASC").
+		Find(&enrollments).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get active program enrollments")
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func (r *programEnrollmentRepository) GetEnrollmentWeeks(ctx context.Context, enrollmentID string) ([]models.ProgramEnrollmentWeek, error) {
+	var weeks []models.ProgramEnrollmentWeek
+	err := r.db.WithContext(ctx).
+		Where("enrollment_id = ?", enrollmentID).
+		Order("start_date ASC").
+		Find(&weeks).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollment weeks")
+		return nil, err
+	}
+	return weeks, nil
+}
+
+// CountSessions counts the enrollment's planned workouts between from and to, both
+// inclusive, and how many of them were completed by an activity that still exists
+func (r *programEnrollmentRepository) CountSessions(ctx context.Context, enrollmentID string, from, to time.Time) (int, int, error) {
+	var counts struct {
+		Planned   int
+		Completed int
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) AS planned, COUNT(c.id) AS completed
+		FROM planned_workouts p
+		LEFT JOIN planned_workout_completions c
+			ON c.planned_workout_id = p.planned_workout_id
+			AND c.occurrence_date = p.scheduled_date
+			AND EXISTS (SELECT 1 FROM activities a WHERE a.activity_id = c.activity_id AND a.deleted_at IS NULL)
+		WHERE p.enrollment_id = ? AND p.deleted_at IS NULL AND p.scheduled_date BETWEEN ? AND ?`,
+		enrollmentID, from, to).Scan(&counts).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to count program sessions")
+		return 0, 0, err
+	}
+	return counts.Planned, counts.Completed, nil
+}
+
+// AdvanceEnrollment records a finished week and applies updates to the enrollment. With
+// rescheduleFrom, the sessions planned from that date on that were not completed are
+// replaced by workouts. It reports false without changing anything when the enrollment
+// has moved on from the given state in the meantime.
+func (r *programEnrollmentRepository) AdvanceEnrollment(ctx context.Context, enrollment models.ProgramEnrollment, updates map[string]interface{}, week models.ProgramEnrollmentWeek, rescheduleFrom *time.Time, workouts []models.PlannedWorkout) (bool, error) {
+	advanced := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ProgramEnrollment{}).
+			Where("enrollment_id = ? AND status = ? AND current_week = ? AND current_week_start = ? AND week_repeats = ?",
+				enrollment.EnrollmentID, models.EnrollmentStatusActive, enrollment.CurrentWeek, enrollment.CurrentWeekStart, enrollment.WeekRepeats).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&week).Error; err != nil {
+			return err
+		}
+
+		if rescheduleFrom != nil {
+			if err := deleteUncompletedSessions(tx, enrollment.EnrollmentID, *rescheduleFrom); err != nil {
+				return err
+			}
+			if len(workouts) > 0 {
+				if err := tx.Create(&workouts).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		advanced = true
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to advance program enrollment")
+		return false, err
+	}
+	return advanced, nil
+}
+
+// CancelEnrollment stops an active enrollment and removes its sessions planned from
+// from on that were not completed
+func (r *programEnrollmentRepository) CancelEnrollment(ctx context.Context, enrollmentID string, userID uint, from time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ProgramEnrollment{}).
+			Where("enrollment_id = ? AND user_id = ? AND status = ?", enrollmentID, userID, models.EnrollmentStatusActive).
+			Updates(map[string]interface{}{"status": models.EnrollmentStatusCancelled, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return deleteUncompletedSessions(tx, enrollmentID, from)
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Logger.Error().Err(err).Msg("Failed to cancel program enrollment")
+	}
+	return err
+}
+
+func deleteUncompletedSessions(tx *gorm.DB, enrollmentID string, from time.Time) error {
+	return tx.
+		Where("enrollment_id = ? AND scheduled_date >= ?", enrollmentID, from).
+		Where("NOT EXISTS (SELECT 1 FROM planned_workout_completions c WHERE c.planned_workout_id = planned_workouts.planned_workout_id)").
+		Delete(&models.PlannedWorkout{}).Error
+}
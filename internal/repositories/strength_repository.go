@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type StrengthRepository interface {
+	ReplaceSets(ctx context.Context, activityID string, sets []models.StrengthSet) error
+	GetSets(ctx context.Context, activityID string, userID uint) ([]models.StrengthSet, error)
+	GetExerciseSets(ctx context.Context, userID uint, exerciseID string) ([]models.ExerciseSetRecord, error)
+}
+
+type strengthRepository struct {
+	db *gorm.DB
+}
+
+func NewStrengthRepository(db *gorm.DB) StrengthRepository {
+	return &strengthRepository{db: db}
+}
+
+// ReplaceSets swaps the logged sets of a session for the given ones in a single transaction
+func (r *strengthRepository) ReplaceSets(ctx context.Context, activityID string, sets []models.StrengthSet) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&models.StrengthSet{}).Error; err != nil {
+			return err
+		}
+		if len(sets) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&sets, 500).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to replace strength sets")
+		return err
+	}
+	return nil
+}
+
+func (r *strengthRepository) GetSets(ctx context.Context, activityID string, userID uint) ([]models.StrengthSet, error) {
+	var sets []models.StrengthSet
+	err := r.db.WithContext(ctx).
+		Where("activity_id = ? AND user_id = ?", activityID, userID).
+		Order("exercise_index ASC, set_index ASC").
+		Find(&sets).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get strength sets")
+		return nil, err
+	}
+	return sets, nil
+}
+
+// GetExerciseSets returns every set the user logged of an exercise in sessions that are
+// not in the trash, most recent session first
+func (r *strengthRepository) GetExerciseSets(ctx context.Context, userID uint, exerciseID string) ([]models.ExerciseSetRecord, error) {
+	var records []models.ExerciseSetRecord
+	err := r.db.WithContext(ctx).
+		Table("strength_sets").
+		Select("strength_sets.*, activities.done_at").
+		Joins("JOIN activities ON activities.activity_id = strength_sets.activity_id AND activities.deleted_at IS NULL").
+		Where("strength_sets.user_id = ? AND strength_sets.exercise_id = ?", userID, exerciseID).
+		Order("activities.done_at DESC, strength_sets.activity_id, strength_sets.exercise_index, strength_sets.set_index").
+		Scan(&records).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get exercise sets")
+		return nil, err
+	}
+	return records, nil
+}
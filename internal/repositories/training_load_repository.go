@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TrainingLoadRepository interface {
+	MarkStale(ctx context.Context, userID uint, day time.Time) error
+	GetState(ctx context.Context, userID uint) (*models.TrainingLoadState, error)
+	GetActivityLoadInputs(ctx context.Context, userID uint, from time.Time) ([]models.ActivityLoadInput, error)
+	GetActivityLoadInput(ctx context.Context, userID uint, activityID string) (*models.ActivityLoadInput, error)
+	GetLastDayBefore(ctx context.Context, userID uint, day time.Time) (*models.TrainingLoadDay, error)
+	GetDays(ctx context.Context, userID uint, from, to time.Time) ([]models.TrainingLoadDay, error)
+	ReplaceDays(ctx context.Context, userID uint, from time.Time, generation int64, days []models.TrainingLoadDay) (bool, error)
+}
+
+type trainingLoadRepository struct {
+	db *gorm.DB
+}
+
+func NewTrainingLoadRepository(db *gorm.DB) TrainingLoadRepository {
+	return &trainingLoadRepository{db: db}
+}
+
+// MarkStale moves the day from which the user's stored load is out of date back to day,
+// if it is not earlier already. A user whose load was never computed stays stale from the
+// very beginning.
+func (r *trainingLoadRepository) MarkStale(ctx context.Context, userID uint, day time.Time) error {
+	err := dbFromContext(ctx, r.db).Exec(`
+		INSERT INTO training_load_states (user_id, stale_from, generation) VALUES (?, ?, 1)
+		ON CONFLICT (user_id) DO UPDATE SET
+			stale_from = LEAST(COALESCE(training_load_states.stale_from, ?::date), ?::date),
+			generation = training_load_states.generation + 1`,
+		userID, time.Time{}, day, day).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to mark training load stale")
+		return err
+	}
+	return nil
+}
+
+func (r *trainingLoadRepository) GetState(ctx context.Context, userID uint) (*models.TrainingLoadState, error) {
+	var state models.TrainingLoadState
+	err := dbFromContext(ctx, r.db).Where("user_id = ?", userID).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get training load state")
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetActivityLoadInputs returns what the load of the user's activities done since from
+// is worked out from, skipping activities in the trash, in the order they were done
+func (r *trainingLoadRepository) GetActivityLoadInputs(ctx context.Context, userID uint, from time.Time) ([]models.ActivityLoadInput, error) {
+	var inputs []models.ActivityLoadInput
+	err := r.activityLoadInputs(ctx).
+		Where("activities.user_id = ? AND activities.done_at >= ?", userID, from).
+		Order("activities.done_at ASC").
+		Scan(&inputs).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity load inputs")
+		return nil, err
+	}
+	return inputs, nil
+}
+
+func (r *trainingLoadRepository) GetActivityLoadInput(ctx context.Context, userID uint, activityID string) (*models.ActivityLoadInput, error) {
+	var inputs []models.ActivityLoadInput
+	err := r.activityLoadInputs(ctx).
+		Where("activities.user_id = ? AND activities.activity_id = ?", userID, activityID).
+		Limit(1).
+		Scan(&inputs).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity load input")
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	return &inputs[0], nil
+}
+
+func (r *trainingLoadRepository) activityLoadInputs(ctx context.Context) *gorm.DB {
+	return dbFromContext(ctx, r.db).
+		Table("activities").
+		Select("activities.activity_id, activities.activity_type, activities.done_at, activities.duration_in_minutes, activity_heart_rates.samples AS heart_rate_samples").
+		Joins("LEFT JOIN activity_heart_rates ON activity_heart_rates.activity_id = activities.activity_id").
+		Where("activities.deleted_at IS NULL")
+}
+
+func (r *trainingLoadRepository) GetLastDayBefore(ctx context.Context, userID uint, day time.Time) (*models.TrainingLoadDay, error) {
+	var days []models.TrainingLoadDay
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND day < ?", userID, day).
+		Order("day DESC").
+		Limit(1).
+		Find(&days).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get previous training load day")
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, nil
+	}
+	return &days[0], nil
+}
+
+func (r *trainingLoadRepository) GetDays(ctx context.Context, userID uint, from, to time.Time) ([]models.TrainingLoadDay, error) {
+	var days []models.TrainingLoadDay
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND day >= ? AND day <= ?", userID, from, to).
+		Order("day ASC").
+		Find(&days).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get training load days")
+		return nil, err
+	}
+	return days, nil
+}
+
+// ReplaceDays swaps the stored days from the given day on for the recomputed ones and
+// marks the load current, provided nothing changed since it was found stale at generation.
+// It reports whether it did; otherwise the load stays stale and is recomputed next time.
+func (r *trainingLoadRepository) ReplaceDays(ctx context.Context, userID uint, from time.Time, generation int64, days []models.TrainingLoadDay) (bool, error) {
+	replaced := false
+	err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Lock the state so that concurrent recomputations take turns
+		state := models.TrainingLoadState{UserID: userID, StaleFrom: &time.Time{}}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&state).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&state).Error
+		if err != nil {
+			return err
+		}
+		if state.Generation != generation || state.StaleFrom == nil {
+			return nil
+		}
+
+		if err := tx.Where("user_id = ? AND day >= ?", userID, from).Delete(&models.TrainingLoadDay{}).Error; err != nil {
+			return err
+		}
+		if len(days) > 0 {
+			if err := tx.CreateInBatches(&days, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		replaced = true
+		return tx.Model(&models.TrainingLoadState{}).Where("user_id = ?", userID).Update("stale_from", nil).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to replace training load days")
+		return false, err
+	}
+	return replaced, nil
+}
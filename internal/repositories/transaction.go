@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which Transactor keeps the open transaction
+type txKey struct{}
+
+// Transactor runs a function in a database transaction. Every repository call made with
+// the context the function is given uses that transaction, so writes through several
+// repositories are committed or rolled back together.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithTransaction commits when fn returns nil and rolls back otherwise. Called inside
+// another transaction it runs fn in a savepoint, so a failure only undoes fn's writes.
+func (t *transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbFromContext(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// dbFromContext returns the transaction the context carries, or db outside of one
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"FitByte/internal/models"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type WorkoutTemplateRepository interface {
+	CreateTemplate(ctx context.Context, template models.WorkoutTemplate) error
+	GetTemplatesByUserID(ctx context.Context, userID uint) ([]models.WorkoutTemplate, error)
+	GetTemplateByID(ctx context.Context, templateID string, userID uint) (*models.WorkoutTemplate, error)
+	GetTemplateByName(ctx context.Context, name string, userID uint) (*models.WorkoutTemplate, error)
+	UpdateTemplate(ctx context.Context, templateID string, userID uint, updates map[string]interface{}) error
+	DeleteTemplate(ctx context.Context, templateID string, userID uint) error
+}
+
+type workoutTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkoutTemplateRepository(db *gorm.DB) WorkoutTemplateRepository {
+	return &workoutTemplateRepository{db: db}
+}
+
+func (r *workoutTemplateRepository) CreateTemplate(ctx context.Context, template models.WorkoutTemplate) error {
+	err := r.db.WithContext(ctx).Create(&template).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create workout template")
+		return translateError(r.db, err)
+	}
+	return nil
+}
+
+func (r *workoutTemplateRepository) GetTemplatesByUserID(ctx context.Context, userID uint) ([]models.WorkoutTemplate, error) {
+	var templates []models.WorkoutTemplate
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&templates).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get workout templates by user ID")
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *workoutTemplateRepository) GetTemplateByID(ctx context.Context, templateID string, userID uint) (*models.WorkoutTemplate, error) {
+	var template models.WorkoutTemplate
+	err := r.db.WithContext(ctx).Where("template_id = ? AND user_id = ?", templateID, userID).First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get workout template by ID")
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *workoutTemplateRepository) GetTemplateByName(ctx context.Context, name string, userID uint) (*models.WorkoutTemplate, error) {
+	var template models.WorkoutTemplate
+	err := r.db.WithContext(ctx).Where("name = ? AND user_id = ?", name, userID).First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Logger.Error().Err(err).Msg("Failed to get workout template by name")
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *workoutTemplateRepository) UpdateTemplate(ctx context.Context, templateID string, userID uint, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.WorkoutTemplate{}).
+		Where("template_id = ? AND user_id = ?", templateID, userID).
+		Updates(updates)
+
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to update workout template")
+		return translateError(r.db, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *workoutTemplateRepository) DeleteTemplate(ctx context.Context, templateID string, userID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("template_id = ? AND user_id = ?", templateID, userID).
+		Delete(&models.WorkoutTemplate{})
+
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to delete workout template")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"FitByte/internal/constant"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ActivityAttachmentService interface {
+	AttachFiles(ctx context.Context, userID uint, activityID string, req models.AttachFilesRequest) ([]models.AttachmentResponse, error)
+	GetAttachments(ctx context.Context, userID uint, activityID string) ([]models.AttachmentResponse, error)
+	GetAttachmentsByActivity(ctx context.Context, userID uint, activityIDs []string) (map[string][]models.AttachmentResponse, error)
+	DetachFile(ctx context.Context, userID uint, activityID string, fileID uint) error
+	PurgeAttachments(ctx context.Context, before time.Time) error
+}
+
+type activityAttachmentService struct {
+	activityRepo     repositories.ActivityRepository
+	activityFileRepo repositories.ActivityFileRepository
+	fileRepo         repositories.FileRepository
+	minioRepo        repositories.MinioRepository
+}
+
+func NewActivityAttachmentService(activityRepo repositories.ActivityRepository, activityFileRepo repositories.ActivityFileRepository, fileRepo repositories.FileRepository, minioRepo repositories.MinioRepository) ActivityAttachmentService {
+	return &activityAttachmentService{
+		activityRepo:     activityRepo,
+		activityFileRepo: activityFileRepo,
+		fileRepo:         fileRepo,
+		minioRepo:        minioRepo,
+	}
+}
+
+// AttachFiles attaches the user's uploaded images to one of their activities and returns
+// all of the activity's attachments
+func (s *activityAttachmentService) AttachFiles(ctx context.Context, userID uint, activityID string, req models.AttachFilesRequest) ([]models.AttachmentResponse, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity to attach files to")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	fileIDs := make([]uint, 0, len(req.FileIDs))
+	seen := make(map[uint]bool, len(req.FileIDs))
+	for _, fileID := range req.FileIDs {
+		if !seen[fileID] {
+			seen[fileID] = true
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+
+	files, err := s.fileRepo.GetByIDs(ctx, fileIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) != len(fileIDs) {
+		return nil, customErrors.ErrFileNotFound
+	}
+	for _, file := range files {
+		if uint(file.UserID) != userID {
+			log.Logger.Warn().Uint("userID", userID).Uint("fileID", file.ID).Msg("attempt to attach another user's file")
+			return nil, customErrors.ErrFileNotOwned
+		}
+		if !constant.AllowedExts[strings.ToLower(filepath.Ext(file.FileName))] {
+			return nil, customErrors.ErrFileNotImage
+		}
+	}
+
+	attached, err := s.activityFileRepo.GetAttachedFileIDs(ctx, fileIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(attached) > 0 {
+		return nil, customErrors.ErrFileAlreadyAttached
+	}
+
+	ok, err := s.activityFileRepo.AttachFiles(ctx, activityID, userID, fileIDs, constant.MaxActivityAttachments)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customErrors.ErrActivityNotFound
+		}
+		if err == gorm.ErrDuplicatedKey {
+			// Another request attached one of the files since they were checked
+			return nil, customErrors.ErrFileAlreadyAttached
+		}
+		return nil, err
+	}
+	if !ok {
+		return nil, customErrors.ErrTooManyAttachments
+	}
+
+	return s.GetAttachments(ctx, userID, activityID)
+}
+
+// GetAttachments returns the files attached to one of the user's activities
+func (s *activityAttachmentService) GetAttachments(ctx context.Context, userID uint, activityID string) ([]models.AttachmentResponse, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity for attachments")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	byActivity, err := s.GetAttachmentsByActivity(ctx, userID, []string{activityID})
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := byActivity[activityID]
+	if attachments == nil {
+		attachments = []models.AttachmentResponse{}
+	}
+	return attachments, nil
+}
+
+// GetAttachmentsByActivity returns the attachments of several activities keyed by activity ID
+func (s *activityAttachmentService) GetAttachmentsByActivity(ctx context.Context, userID uint, activityIDs []string) (map[string][]models.AttachmentResponse, error) {
+	attachments, err := s.activityFileRepo.GetAttachments(ctx, userID, activityIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byActivity := make(map[string][]models.AttachmentResponse)
+	for _, attachment := range attachments {
+		byActivity[attachment.ActivityID] = append(byActivity[attachment.ActivityID], models.AttachmentResponse{
+			FileID:     attachment.FileID,
+			FileName:   attachment.FileName,
+			URL:        attachment.FileURL,
+			AttachedAt: attachment.CreatedAt,
+		})
+	}
+	return byActivity, nil
+}
+
+// DetachFile removes a file from an activity. The upload stays available to its owner.
+func (s *activityAttachmentService) DetachFile(ctx context.Context, userID uint, activityID string, fileID uint) error {
+	err := s.activityFileRepo.DetachFile(ctx, activityID, userID, fileID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customErrors.ErrAttachmentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// PurgeAttachments deletes the files attached to activities that were deleted before the
+// given time, in the database and in storage. It runs ahead of the activity purge, which
+// would otherwise drop the links to those files.
+func (s *activityAttachmentService) PurgeAttachments(ctx context.Context, before time.Time) error {
+	keys, err := s.activityFileRepo.PurgeAttachments(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		// An object left behind in storage is harmless, so a failure does not stop the purge
+		if err := s.minioRepo.RemoveFile(ctx, key); err != nil {
+			log.Logger.Error().Err(err).Str("key", key).Msg("Failed to remove attachment from storage")
+		}
+	}
+	if len(keys) > 0 {
+		log.Logger.Info().Int("files", len(keys)).Msg("Purged attachments of trashed activities")
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"FitByte/internal/constant"
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/geo"
+	"FitByte/pkg/log"
+	"FitByte/pkg/workout"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportChunkSize is the number of activities fetched from the database per round trip during export
+const exportChunkSize = 500
+
+type ActivityExportService interface {
+	ExportActivities(ctx context.Context, userID uint, query models.GetActivitiesQuery, format string, w io.Writer) error
+}
+
+type activityExportService struct {
+	activityRepo repositories.ActivityRepository
+	profileRepo  repositories.ProfileRepository
+	trackRepo    repositories.ActivityTrackRepository
+	fileRepo     repositories.FileRepository
+	minioRepo    repositories.MinioRepository
+}
+
+func NewActivityExportService(activityRepo repositories.ActivityRepository, profileRepo repositories.ProfileRepository, trackRepo repositories.ActivityTrackRepository, fileRepo repositories.FileRepository, minioRepo repositories.MinioRepository) ActivityExportService {
+	return &activityExportService{
+		activityRepo: activityRepo,
+		profileRepo:  profileRepo,
+		trackRepo:    trackRepo,
+		fileRepo:     fileRepo,
+		minioRepo:    minioRepo,
+	}
+}
+
+// ExportActivities writes every activity matching the query to w in the given format.
+// Activities are read in chunks and written as they arrive, so the export is never held in memory.
+func (s *activityExportService) ExportActivities(ctx context.Context, userID uint, query models.GetActivitiesQuery, format string, w io.Writer) error {
+	switch format {
+	case constant.ExportFormatCSV:
+		return s.exportCSV(ctx, userID, query, w)
+	case constant.ExportFormatNDJSON:
+		return s.exportNDJSON(ctx, userID, query, w)
+	case constant.ExportFormatGPX:
+		return s.exportGPX(ctx, userID, query, w)
+	}
+	return customErrors.ErrUnsupportedExportFormat
+}
+
+func (s *activityExportService) exportCSV(ctx context.Context, userID uint, query models.GetActivitiesQuery, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"activityId", "activityType", "doneAt", "durationInMinutes", "caloriesBurned", "distanceMeters", "elevationGainMeters", "poolLengthMeters", "notes", "tags", "createdAt", "updatedAt"})
+	if err != nil {
+		return err
+	}
+
+	err = s.activityRepo.StreamActivitiesByUserID(ctx, userID, query, exportChunkSize, func(activities []models.Activity) error {
+		for _, activity := range activities {
+			err := writer.Write([]string{
+				activity.ActivityID,
+				activity.ActivityType,
+				activity.DoneAt.Format(time.RFC3339),
+				strconv.Itoa(activity.DurationInMinutes),
+				strconv.Itoa(activity.CaloriesBurned),
+				formatOptionalFloat(activity.DistanceMeters),
+				formatOptionalFloat(activity.ElevationGainMeters),
+				formatOptionalFloat(activity.PoolLengthMeters),
+				formatOptionalString(activity.Notes),
+				strings.Join(activity.Tags, ";"),
+				activity.CreatedAt.Format(time.RFC3339),
+				activity.UpdatedAt.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to export activities as CSV")
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatOptionalFloat leaves a CSV cell empty for a value that was not recorded
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatOptionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func (s *activityExportService) exportNDJSON(ctx context.Context, userID uint, query models.GetActivitiesQuery, w io.Writer) error {
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+
+	err = s.activityRepo.StreamActivitiesByUserID(ctx, userID, query, exportChunkSize, func(activities []models.Activity) error {
+		for _, activity := range activities {
+			if err := encoder.Encode(toActivityResponse(activity, units)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to export activities as NDJSON")
+		return err
+	}
+
+	return nil
+}
+
+type gpxExportTrack struct {
+	XMLName  xml.Name           `xml:"trk"`
+	Name     string             `xml:"name"`
+	Type     string             `xml:"type"`
+	Segments []gpxExportSegment `xml:"trkseg"`
+}
+
+type gpxExportSegment struct {
+	Points []gpxExportPoint `xml:"trkpt"`
+}
+
+type gpxExportPoint struct {
+	Lat       float64  `xml:"lat,attr"`
+	Lon       float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele,omitempty"`
+	Time      string   `xml:"time,omitempty"`
+}
+
+// exportGPX writes one track per activity that has GPS data; activities without a
+// recorded route cannot be represented in GPX and are left out.
+func (s *activityExportService) exportGPX(ctx context.Context, userID uint, query models.GetActivitiesQuery, w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header+`<gpx version="1.1" creator="FitByte" xmlns="http://www.topografix.com/GPX/1/1">`+"\n")
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = s.activityRepo.StreamActivitiesByUserID(ctx, userID, query, exportChunkSize, func(activities []models.Activity) error {
+		for _, activity := range activities {
+			points, err := s.loadTrack(ctx, activity)
+			if err != nil {
+				return err
+			}
+			if len(points) == 0 {
+				continue
+			}
+
+			track := gpxExportTrack{
+				Name: activity.ActivityType + " " + activity.DoneAt.Format(time.RFC3339),
+				Type: strings.ToLower(activity.ActivityType),
+			}
+
+			segment := gpxExportSegment{Points: make([]gpxExportPoint, 0, len(points))}
+			for _, p := range points {
+				point := gpxExportPoint{Lat: p.Latitude, Lon: p.Longitude}
+				if p.HasElevation {
+					elevation := p.Elevation
+					point.Elevation = &elevation
+				}
+				if !p.Time.IsZero() {
+					point.Time = p.Time.UTC().Format(time.RFC3339)
+				}
+				segment.Points = append(segment.Points, point)
+			}
+			track.Segments = []gpxExportSegment{segment}
+
+			if err := encoder.Encode(track); err != nil {
+				return err
+			}
+		}
+		return encoder.Flush()
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to export activities as GPX")
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n</gpx>\n")
+	return err
+}
+
+// loadTrack returns the stored GPS route of an activity
+func (s *activityExportService) loadTrack(ctx context.Context, activity models.Activity) ([]geo.Point, error) {
+	track, err := s.trackRepo.GetTrackByActivityID(ctx, activity.ActivityID, activity.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if track == nil {
+		// Activities imported before routes were stored only have their source file
+		return s.loadTrackFromFile(ctx, activity)
+	}
+
+	points, err := geo.DecodeTrack(track.Points)
+	if err != nil {
+		log.Logger.Warn().Err(err).Str("activityId", activity.ActivityID).Msg("Failed to decode track for export")
+		return nil, nil
+	}
+	return points, nil
+}
+
+// loadTrackFromFile returns the positioned points of the workout file an activity was imported from
+func (s *activityExportService) loadTrackFromFile(ctx context.Context, activity models.Activity) ([]geo.Point, error) {
+	if activity.FileID == nil {
+		return nil, nil
+	}
+
+	file, err := s.fileRepo.GetByID(ctx, *activity.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	format, exists := constant.WorkoutFileFormats[strings.ToLower(filepath.Ext(file.FileName))]
+	if !exists {
+		return nil, nil
+	}
+
+	object, err := s.minioRepo.GetFile(ctx, file.FileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	parsed, err := workout.Parse(format, object)
+	if err != nil {
+		// A source file that no longer parses should not abort the whole export
+		log.Logger.Warn().Err(err).Str("activityId", activity.ActivityID).Msg("Failed to parse workout file for export")
+		return nil, nil
+	}
+
+	return workoutRoute(parsed.Points), nil
+}
@@ -0,0 +1,489 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/applehealth"
+	"FitByte/pkg/log"
+	"FitByte/pkg/workout"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// appleHealthBatchSize is the number of workouts checked for duplicates and inserted together
+const appleHealthBatchSize = 200
+
+type ActivityImportService interface {
+	ImportActivity(ctx context.Context, userID uint, format string, activityType string, file models.UploadFile) (*models.ImportActivityResponse, error)
+	StartAppleHealthImport(ctx context.Context, userID uint, format string, r io.Reader) (*models.ImportJobResponse, error)
+	GetImportJob(ctx context.Context, userID uint, jobID string) (*models.ImportJobResponse, error)
+	FailInterruptedImportJobs(ctx context.Context) error
+}
+
+type activityImportService struct {
+	activityRepo    repositories.ActivityRepository
+	importJobRepo   repositories.ImportJobRepository
+	profileRepo     repositories.ProfileRepository
+	transactor      repositories.Transactor
+	plannedSvc      PlannedWorkoutService
+	revisionSvc     ActivityRevisionService
+	fileSvc         FileService
+	trainingLoadSvc TrainingLoadService
+}
+
+func NewActivityImportService(activityRepo repositories.ActivityRepository, importJobRepo repositories.ImportJobRepository, profileRepo repositories.ProfileRepository, transactor repositories.Transactor, fileService FileService, plannedWorkoutService PlannedWorkoutService, revisionService ActivityRevisionService, trainingLoadService TrainingLoadService) ActivityImportService {
+	return &activityImportService{
+		activityRepo:    activityRepo,
+		importJobRepo:   importJobRepo,
+		profileRepo:     profileRepo,
+		transactor:      transactor,
+		plannedSvc:      plannedWorkoutService,
+		revisionSvc:     revisionService,
+		fileSvc:         fileService,
+		trainingLoadSvc: trainingLoadService,
+	}
+}
+
+// ImportActivity parses a workout file, keeps the original in storage and creates
+// an activity linked to it. activityType overrides the sport recorded in the file when set.
+func (s *activityImportService) ImportActivity(ctx context.Context, userID uint, format string, activityType string, file models.UploadFile) (*models.ImportActivityResponse, error) {
+	parsed, err := workout.Parse(format, file.FileData)
+	if err != nil {
+		log.Logger.Warn().Err(err).Str("format", format).Msg("Failed to parse workout file")
+		return nil, customErrors.ErrInvalidWorkoutFile
+	}
+
+	if activityType == "" {
+		var exists bool
+		activityType, exists = models.ActivityTypeFromSport(parsed.Sport)
+		if !exists {
+			log.Logger.Warn().Str("sport", parsed.Sport).Msg("Unknown workout sport")
+			return nil, customErrors.ErrUnknownSport
+		}
+	}
+
+	if parsed.StartTime.IsZero() {
+		return nil, customErrors.ErrInvalidWorkoutFile
+	}
+
+	caloriesPerMinute, exists := models.ActivityTypeCalories[activityType]
+	if !exists {
+		return nil, customErrors.ErrInvalidActivityType
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewind so the original file can be stored after parsing
+	if _, err := file.FileData.Seek(0, io.SeekStart); err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to rewind workout file")
+		return nil, err
+	}
+
+	saved, err := s.fileSvc.SaveFile(ctx, int64(userID), file)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to save workout file")
+		return nil, err
+	}
+
+	durationInMinutes := int(math.Round(parsed.Duration.Minutes()))
+	if durationInMinutes < 1 {
+		durationInMinutes = 1
+	}
+
+	activity := models.Activity{
+		ActivityID:        uuid.New().String(),
+		UserID:            userID,
+		ActivityType:      activityType,
+		DoneAt:            parsed.StartTime,
+		DurationInMinutes: durationInMinutes,
+		CaloriesBurned:    caloriesPerMinute * durationInMinutes,
+		FileID:            &saved.ID,
+		Visibility:        models.DefaultActivityVisibility,
+	}
+	if models.EnduranceActivityTypes[activityType] {
+		activity.DistanceMeters = positiveOrNil(parsed.DistanceMeters)
+		activity.ElevationGainMeters = positiveOrNil(parsed.ElevationGainMeters)
+	}
+
+	track := newActivityTrack(activity, parsed.Points)
+	heartRate := newActivityHeartRate(activity, workoutHeartRate(parsed.Points))
+	laps := newActivityLaps(activity, parsed.Laps)
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.activityRepo.CreateImportedActivity(ctx, activity, track, heartRate, laps); err != nil {
+			return err
+		}
+		if err := s.revisionSvc.RecordCreated(ctx, userID, models.RevisionSourceImport, activity); err != nil {
+			return err
+		}
+		if err := markLoadStale(ctx, s.trainingLoadSvc, userID, activity); err != nil {
+			return err
+		}
+		return s.plannedSvc.CompleteMatchingSlot(ctx, userID, activity)
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create imported activity")
+		// Without the activity nothing points to the stored original
+		s.fileSvc.DeleteFile(ctx, saved)
+		return nil, err
+	}
+
+	return &models.ImportActivityResponse{
+		Activity:            newActivityResponse(activity, time.Now(), units),
+		FileURI:             saved.FileURL,
+		StartTime:           parsed.StartTime.Format(time.RFC3339),
+		DurationInSeconds:   int(parsed.Duration.Seconds()),
+		DistanceMeters:      parsed.DistanceMeters,
+		ElevationGainMeters: parsed.ElevationGainMeters,
+		Trackpoints:         len(parsed.Points),
+	}, nil
+}
+
+// StartAppleHealthImport spools an Apple Health export.zip or export.xml to a temporary
+// file and imports its workouts in the background. The returned job reports progress.
+func (s *activityImportService) StartAppleHealthImport(ctx context.Context, userID uint, format string, r io.Reader) (*models.ImportJobResponse, error) {
+	// Create the job before spooling the upload, which can take longer than the request deadline
+	job := models.ImportJob{
+		JobID:  uuid.New().String(),
+		UserID: userID,
+		Source: models.ImportJobSourceAppleHealth,
+		Status: models.ImportJobStatusPending,
+	}
+
+	err := s.importJobRepo.CreateImportJob(ctx, &job)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create Apple Health import job")
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "apple-health-*."+format)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create temporary file for Apple Health import")
+		s.failImportJob(job.JobID, err)
+		return nil, err
+	}
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Logger.Error().Err(err).Msg("Failed to store Apple Health export")
+		s.failImportJob(job.JobID, err)
+		return nil, err
+	}
+
+	go s.runAppleHealthImport(job.JobID, userID, format, tmp.Name())
+
+	response := toImportJobResponse(job)
+	return &response, nil
+}
+
+func (s *activityImportService) GetImportJob(ctx context.Context, userID uint, jobID string) (*models.ImportJobResponse, error) {
+	job, err := s.importJobRepo.GetImportJobByID(ctx, jobID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get import job")
+		return nil, err
+	}
+	if job == nil {
+		return nil, customErrors.ErrImportJobNotFound
+	}
+
+	response := toImportJobResponse(*job)
+	return &response, nil
+}
+
+// FailInterruptedImportJobs is called at startup. Jobs run inside the server process and
+// their uploads are spooled to temporary files, so a job still pending or running then was
+// cut off by a restart or crash and can never finish.
+func (s *activityImportService) FailInterruptedImportJobs(ctx context.Context) error {
+	failed, err := s.importJobRepo.FailUnfinishedImportJobs(ctx, "import was interrupted by a server restart, upload the export again")
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		log.Logger.Warn().Int64("jobs", failed).Msg("Failed import jobs interrupted by a restart")
+	}
+	return nil
+}
+
+// runAppleHealthImport streams the stored export and imports its workouts in batches,
+// skipping workouts whose source UUID has already been imported for the user.
+func (s *activityImportService) runAppleHealthImport(jobID string, userID uint, format string, path string) {
+	// The request that started the job is gone by now, so the job runs on its own context
+	ctx := context.Background()
+	logger := log.Logger.With().Str("jobId", jobID).Logger()
+
+	defer func() {
+		if err := os.Remove(path); err != nil {
+			logger.Error().Err(err).Msg("Failed to remove Apple Health export")
+		}
+	}()
+
+	var (
+		processed, imported, skipped int
+		bytesRead                    int64
+	)
+
+	fail := func(err error) {
+		logger.Error().Err(err).Msg("Apple Health import failed")
+		s.updateImportJob(ctx, jobID, map[string]interface{}{
+			"status":      models.ImportJobStatusFailed,
+			"error":       err.Error(),
+			"processed":   processed,
+			"imported":    imported,
+			"skipped":     skipped,
+			"finished_at": time.Now(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			fail(fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	var (
+		export io.ReadCloser
+		total  int64
+	)
+	if format == "zip" {
+		archive, err := applehealth.OpenArchive(path)
+		if err != nil {
+			fail(err)
+			return
+		}
+		export, total = archive, archive.Size
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			fail(err)
+			return
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			fail(err)
+			return
+		}
+		export, total = f, info.Size()
+	}
+	defer export.Close()
+
+	s.updateImportJob(ctx, jobID, map[string]interface{}{
+		"status":      models.ImportJobStatusRunning,
+		"bytes_total": total,
+	})
+
+	reader := &countingReader{r: export, n: &bytesRead}
+	batch := make([]applehealth.Workout, 0, appleHealthBatchSize)
+
+	flush := func() error {
+		created, duplicates, unsupported, err := s.importAppleHealthBatch(ctx, userID, batch)
+		if err != nil {
+			return err
+		}
+		processed += len(batch)
+		imported += created
+		skipped += duplicates + unsupported
+		batch = batch[:0]
+
+		s.updateImportJob(ctx, jobID, map[string]interface{}{
+			"processed":       processed,
+			"imported":        imported,
+			"skipped":         skipped,
+			"bytes_processed": bytesRead,
+		})
+		return nil
+	}
+
+	err := applehealth.ReadWorkouts(reader, func(w applehealth.Workout) error {
+		batch = append(batch, w)
+		if len(batch) < appleHealthBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	s.updateImportJob(ctx, jobID, map[string]interface{}{
+		"status":          models.ImportJobStatusCompleted,
+		"bytes_processed": total,
+		"finished_at":     time.Now(),
+	})
+	logger.Info().Int("imported", imported).Int("skipped", skipped).Msg("Apple Health import completed")
+}
+
+// importAppleHealthBatch inserts the workouts of a batch that are neither already
+// imported nor of an unsupported type, returning how many fell into each group.
+func (s *activityImportService) importAppleHealthBatch(ctx context.Context, userID uint, batch []applehealth.Workout) (int, int, int, error) {
+	sourceUUIDs := make([]string, len(batch))
+	for i, w := range batch {
+		sourceUUIDs[i] = w.UUID
+	}
+
+	existing, err := s.activityRepo.GetExistingSourceUUIDs(ctx, userID, sourceUUIDs)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var duplicates, unsupported int
+	activities := make([]models.Activity, 0, len(batch))
+	for _, w := range batch {
+		if existing[w.UUID] {
+			duplicates++
+			continue
+		}
+
+		activityType, exists := models.ActivityTypeFromSport(w.Sport)
+		if !exists {
+			unsupported++
+			continue
+		}
+
+		durationInMinutes := int(math.Round(w.Duration.Minutes()))
+		if durationInMinutes < 1 {
+			durationInMinutes = 1
+		}
+
+		req := models.CreateActivityRequest{
+			ActivityType:      activityType,
+			DoneAt:            w.StartDate.Format(time.RFC3339),
+			DurationInMinutes: durationInMinutes,
+		}
+		if models.EnduranceActivityTypes[activityType] {
+			req.DistanceMeters = positiveOrNil(w.DistanceMeters)
+		}
+
+		activity, err := newActivity(userID, req)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		sourceUUID := w.UUID
+		activity.SourceUUID = &sourceUUID
+
+		// The same workout can appear twice in one export
+		existing[w.UUID] = true
+		activities = append(activities, activity)
+	}
+
+	// The batch is saved together with the first revision of every activity in it and the
+	// training load marker
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		err := s.activityRepo.CreateActivities(ctx, activities)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			// Another import of the same workouts got some of them in first. Insert the
+			// batch one by one, each in a savepoint so that a duplicate only undoes its own
+			// insert, and count the ones that are already there as duplicates.
+			created := activities[:0]
+			for _, activity := range activities {
+				err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+					return s.activityRepo.CreateActivity(ctx, activity)
+				})
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					duplicates++
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				created = append(created, activity)
+			}
+			activities, err = created, nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.revisionSvc.RecordCreated(ctx, userID, models.RevisionSourceImport, activities...); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, activities...)
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return len(activities), duplicates, unsupported, nil
+}
+
+func (s *activityImportService) failImportJob(jobID string, err error) {
+	s.updateImportJob(context.Background(), jobID, map[string]interface{}{
+		"status":      models.ImportJobStatusFailed,
+		"error":       err.Error(),
+		"finished_at": time.Now(),
+	})
+}
+
+func (s *activityImportService) updateImportJob(ctx context.Context, jobID string, updates map[string]interface{}) {
+	updates["updated_at"] = time.Now()
+	if err := s.importJobRepo.UpdateImportJob(ctx, jobID, updates); err != nil {
+		log.Logger.Error().Err(err).Str("jobId", jobID).Msg("Failed to update import job progress")
+	}
+}
+
+func toImportJobResponse(job models.ImportJob) models.ImportJobResponse {
+	var progress float64
+	if job.Status == models.ImportJobStatusCompleted {
+		progress = 100
+	} else if job.BytesTotal > 0 {
+		progress = math.Min(100, float64(job.BytesProcessed)/float64(job.BytesTotal)*100)
+	}
+
+	var jobError *string
+	if job.Error != "" {
+		jobError = &job.Error
+	}
+
+	return models.ImportJobResponse{
+		JobID:      job.JobID,
+		Source:     job.Source,
+		Status:     job.Status,
+		Progress:   progress,
+		Processed:  job.Processed,
+		Imported:   job.Imported,
+		Skipped:    job.Skipped,
+		Error:      jobError,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+// positiveOrNil treats a zero measurement as not recorded
+func positiveOrNil(value float64) *float64 {
+	if value <= 0 {
+		return nil
+	}
+	return &value
+}
+
+// countingReader tracks how many bytes have been read through it
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
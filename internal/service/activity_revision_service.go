@@ -0,0 +1,285 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ActivityRevisionService keeps the history of every activity. The Record methods are
+// called in the transaction that saves the change, so that no change is saved without
+// its revision.
+type ActivityRevisionService interface {
+	RecordCreated(ctx context.Context, actorID uint, source string, activities ...models.Activity) error
+	RecordChanged(ctx context.Context, actorID uint, operation string, before, after models.Activity) error
+	RecordDeleted(ctx context.Context, actorID uint, activities ...models.Activity) error
+	RecordRestored(ctx context.Context, actorID uint, activity models.Activity) error
+	GetHistory(ctx context.Context, userID uint, activityID string) ([]models.ActivityRevisionResponse, error)
+	RevertActivity(ctx context.Context, userID uint, activityID string, req models.RevertActivityRequest) (*models.ActivityResponse, error)
+}
+
+type activityRevisionService struct {
+	revisionRepo    repositories.ActivityRevisionRepository
+	activityRepo    repositories.ActivityRepository
+	profileRepo     repositories.ProfileRepository
+	transactor      repositories.Transactor
+	trainingLoadSvc TrainingLoadService
+}
+
+func NewActivityRevisionService(revisionRepo repositories.ActivityRevisionRepository, activityRepo repositories.ActivityRepository, profileRepo repositories.ProfileRepository, transactor repositories.Transactor, trainingLoadService TrainingLoadService) ActivityRevisionService {
+	return &activityRevisionService{
+		revisionRepo:    revisionRepo,
+		activityRepo:    activityRepo,
+		profileRepo:     profileRepo,
+		transactor:      transactor,
+		trainingLoadSvc: trainingLoadService,
+	}
+}
+
+func (s *activityRevisionService) RecordCreated(ctx context.Context, actorID uint, source string, activities ...models.Activity) error {
+	revisions := make([]models.ActivityRevision, len(activities))
+	for i, activity := range activities {
+		snapshot := models.NewActivitySnapshot(activity)
+		revisions[i] = newActivityRevision(ctx, activity, models.RevisionOperationCreate, actorID, source, snapshotChanges(nil, &snapshot), snapshot)
+	}
+	return s.record(ctx, revisions)
+}
+
+// RecordChanged records an update, merge or revert. before has to be the row the change
+// replaced, read with a lock in the same transaction. Nothing is recorded when no field
+// changed.
+func (s *activityRevisionService) RecordChanged(ctx context.Context, actorID uint, operation string, before, after models.Activity) error {
+	revision, changed := newChangeRevision(ctx, actorID, operation, before, after)
+	if !changed {
+		return nil
+	}
+	return s.record(ctx, []models.ActivityRevision{revision})
+}
+
+func (s *activityRevisionService) RecordDeleted(ctx context.Context, actorID uint, activities ...models.Activity) error {
+	revisions := make([]models.ActivityRevision, len(activities))
+	for i, activity := range activities {
+		changes := models.ActivityChanges{"deleted": {Old: false, New: true}}
+		revisions[i] = newActivityRevision(ctx, activity, models.RevisionOperationDelete, actorID, models.RevisionSourceAPI, changes, models.NewActivitySnapshot(activity))
+	}
+	return s.record(ctx, revisions)
+}
+
+func (s *activityRevisionService) RecordRestored(ctx context.Context, actorID uint, activity models.Activity) error {
+	changes := models.ActivityChanges{"deleted": {Old: true, New: false}}
+	revision := newActivityRevision(ctx, activity, models.RevisionOperationRestore, actorID, models.RevisionSourceAPI, changes, models.NewActivitySnapshot(activity))
+	return s.record(ctx, []models.ActivityRevision{revision})
+}
+
+func (s *activityRevisionService) record(ctx context.Context, revisions []models.ActivityRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	err := s.revisionRepo.CreateRevisions(ctx, revisions)
+	if err != nil {
+		log.Logger.Error().Err(err).Str("activityId", revisions[0].ActivityID).Int("revisions", len(revisions)).Msg("Failed to record activity revisions")
+		return err
+	}
+	return nil
+}
+
+// GetHistory lists the revisions of an activity, oldest first. Deleted activities keep
+// their history until they are purged from the trash.
+func (s *activityRevisionService) GetHistory(ctx context.Context, userID uint, activityID string) ([]models.ActivityRevisionResponse, error) {
+	revisions, err := s.revisionRepo.GetRevisions(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity history")
+		return nil, err
+	}
+
+	// Activities created before history was kept have no revisions yet
+	if len(revisions) == 0 {
+		activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to get activity for history")
+			return nil, err
+		}
+		if activity == nil {
+			return nil, customErrors.ErrActivityNotFound
+		}
+	}
+
+	responses := make([]models.ActivityRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = models.ActivityRevisionResponse{
+			Revision:           revision.Revision,
+			Operation:          revision.Operation,
+			Changes:            revision.Changes,
+			ActorID:            revision.ActorID,
+			Source:             revision.Source,
+			RequestID:          revision.RequestID,
+			RevertedToRevision: revision.RevertedToRevision,
+			CreatedAt:          revision.CreatedAt,
+		}
+	}
+	return responses, nil
+}
+
+// RevertActivity puts the fields of an activity back to how they were after the given
+// revision, and records that as a new revision
+func (s *activityRevisionService) RevertActivity(ctx context.Context, userID uint, activityID string, req models.RevertActivityRequest) (*models.ActivityResponse, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity to revert")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	revision, err := s.revisionRepo.GetRevision(ctx, activityID, userID, req.Revision)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity revision to revert to")
+		return nil, err
+	}
+	if revision == nil {
+		return nil, customErrors.ErrRevisionNotFound
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := revision.Snapshot
+	current := models.NewActivitySnapshot(*activity)
+	if target.Visibility == "" {
+		target.Visibility = current.Visibility
+	}
+	if len(snapshotChanges(&current, &target)) == 0 {
+		response := toActivityResponse(*activity, units)
+		return &response, nil
+	}
+
+	updates := map[string]interface{}{
+		"activity_type":         target.ActivityType,
+		"done_at":               target.DoneAt,
+		"duration_in_minutes":   target.DurationInMinutes,
+		"calories_burned":       target.CaloriesBurned,
+		"distance_meters":       target.DistanceMeters,
+		"elevation_gain_meters": target.ElevationGainMeters,
+		"pool_length_meters":    target.PoolLengthMeters,
+		"notes":                 target.Notes,
+		"tags":                  target.Tags,
+		"visibility":            target.Visibility,
+		"updated_at":            time.Now(),
+	}
+
+	var replaced, reverted *models.Activity
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		replaced, err = s.activityRepo.GetActivityForUpdate(ctx, activityID, userID)
+		if err != nil {
+			return err
+		}
+		if replaced == nil {
+			return customErrors.ErrActivityNotFound
+		}
+
+		if err := s.activityRepo.UpdateActivity(ctx, activityID, userID, 0, updates); err != nil {
+			return err
+		}
+
+		reverted, err = s.activityRepo.GetActivityByID(ctx, activityID, userID)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			return customErrors.ErrActivityNotFound
+		}
+
+		record, changed := newChangeRevision(ctx, userID, models.RevisionOperationRevert, *replaced, *reverted)
+		if changed {
+			record.RevertedToRevision = &revision.Revision
+			if err := s.record(ctx, []models.ActivityRevision{record}); err != nil {
+				return err
+			}
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, *replaced, *reverted)
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound || err == customErrors.ErrActivityNotFound {
+			return nil, customErrors.ErrActivityNotFound
+		}
+		log.Logger.Error().Err(err).Msg("Failed to revert activity")
+		return nil, err
+	}
+
+	response := toActivityResponse(*reverted, units)
+	return &response, nil
+}
+
+func newChangeRevision(ctx context.Context, actorID uint, operation string, before, after models.Activity) (models.ActivityRevision, bool) {
+	oldSnapshot, newSnapshot := models.NewActivitySnapshot(before), models.NewActivitySnapshot(after)
+	changes := snapshotChanges(&oldSnapshot, &newSnapshot)
+	if len(changes) == 0 {
+		return models.ActivityRevision{}, false
+	}
+	return newActivityRevision(ctx, after, operation, actorID, models.RevisionSourceAPI, changes, newSnapshot), true
+}
+
+func newActivityRevision(ctx context.Context, activity models.Activity, operation string, actorID uint, source string, changes models.ActivityChanges, snapshot models.ActivitySnapshot) models.ActivityRevision {
+	return models.ActivityRevision{
+		ActivityID: activity.ActivityID,
+		UserID:     activity.UserID,
+		Operation:  operation,
+		Changes:    changes,
+		Snapshot:   snapshot,
+		ActorID:    actorID,
+		Source:     source,
+		RequestID:  requestIDFromContext(ctx),
+	}
+}
+
+// snapshotChanges compares two snapshots field by field under their JSON names. A nil
+// before counts every field that is set in after as changed.
+func snapshotChanges(before, after *models.ActivitySnapshot) models.ActivityChanges {
+	oldFields, newFields := snapshotFields(before), snapshotFields(after)
+
+	changes := make(models.ActivityChanges)
+	for name, newValue := range newFields {
+		oldValue := oldFields[name]
+		if before == nil && newValue == nil {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes[name] = models.FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	return changes
+}
+
+func snapshotFields(snapshot *models.ActivitySnapshot) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if snapshot == nil {
+		return fields
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// requestIDFromContext returns the ID the request logger gave the current request, or
+// nil outside of a request
+func requestIDFromContext(ctx context.Context) *string {
+	requestID, ok := ctx.Value("requestID").(string)
+	if !ok || requestID == "" {
+		return nil
+	}
+	return &requestID
+}
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"FitByte/internal/constant"
 	"FitByte/internal/models"
 	"FitByte/internal/repositories"
 	"FitByte/pkg/log"
 	customErrors "FitByte/internal/errors"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,70 +20,358 @@ import (
 )
 
 type ActivityService interface {
-	CreateActivity(ctx context.Context, userID uint, req models.CreateActivityRequest) (*models.ActivityResponse, error)
-	GetActivities(ctx context.Context, userID uint, query models.GetActivitiesQuery) ([]models.ActivityResponse, error)
-	UpdateActivity(ctx context.Context, userID uint, activityID string, req models.UpdateActivityRequest) (*models.ActivityResponse, error)
+	CreateActivity(ctx context.Context, userID uint, req models.CreateActivityRequest, conflictPolicy string) (*models.ActivityResponse, error)
+	CreateActivities(ctx context.Context, userID uint, reqs []models.CreateActivityRequest) ([]models.ActivityResponse, error)
+	DetectOverlaps(ctx context.Context, userID uint, reqs []models.CreateActivityRequest) ([][]string, error)
+	GetActivities(ctx context.Context, userID uint, query models.GetActivitiesQuery) (*models.ActivityPage, error)
+	GetActivity(ctx context.Context, userID uint, activityID string) (*models.ActivityResponse, error)
+	UpdateActivity(ctx context.Context, userID uint, activityID string, req models.UpdateActivityRequest, conflictPolicy string, expectedVersion int) (*models.ActivityResponse, error)
 	DeleteActivity(ctx context.Context, userID uint, activityID string) error
+	GetTags(ctx context.Context, userID uint) ([]models.TagUsage, error)
+	GetDuplicateCandidates(ctx context.Context, userID uint, query models.GetDuplicatesQuery) ([]models.DuplicateCandidate, error)
+	MergeActivities(ctx context.Context, userID uint, req models.MergeActivitiesRequest) (*models.MergeActivitiesResponse, error)
+}
+
+// ActivityOverlapError is returned under the reject conflict policy and lists the
+// activities the new time span overlaps
+type ActivityOverlapError struct {
+	ActivityIDs []string
+}
+
+func (e *ActivityOverlapError) Error() string {
+	return customErrors.ErrActivityOverlap.Error()
+}
+
+func (e *ActivityOverlapError) Unwrap() error {
+	return customErrors.ErrActivityOverlap
 }
 
 type activityService struct {
-	activityRepo repositories.ActivityRepository
+	activityRepo      repositories.ActivityRepository
+	profileRepo       repositories.ProfileRepository
+	transactor        repositories.Transactor
+	plannedWorkoutSvc PlannedWorkoutService
+	revisionSvc       ActivityRevisionService
+	attachmentSvc     ActivityAttachmentService
+	trainingLoadSvc   TrainingLoadService
 }
 
-func NewActivityService(activityRepo repositories.ActivityRepository) ActivityService {
+func NewActivityService(activityRepo repositories.ActivityRepository, profileRepo repositories.ProfileRepository, transactor repositories.Transactor, plannedWorkoutService PlannedWorkoutService, revisionService ActivityRevisionService, attachmentService ActivityAttachmentService, trainingLoadService TrainingLoadService) ActivityService {
 	return &activityService{
-		activityRepo: activityRepo,
+		activityRepo:      activityRepo,
+		profileRepo:       profileRepo,
+		transactor:        transactor,
+		plannedWorkoutSvc: plannedWorkoutService,
+		revisionSvc:       revisionService,
+		attachmentSvc:     attachmentService,
+		trainingLoadSvc:   trainingLoadService,
+	}
+}
+
+func (s *activityService) CreateActivity(ctx context.Context, userID uint, req models.CreateActivityRequest, conflictPolicy string) (*models.ActivityResponse, error) {
+	activity, err := newActivity(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	overlaps, err := s.checkOverlaps(ctx, userID, activity.DoneAt, activity.DurationInMinutes, "", conflictPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first revision, the training load marker and the planned slot the activity
+	// fulfils are saved with it
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.activityRepo.CreateActivity(ctx, activity); err != nil {
+			return err
+		}
+		if err := s.revisionSvc.RecordCreated(ctx, userID, models.RevisionSourceAPI, activity); err != nil {
+			return err
+		}
+		if err := markLoadStale(ctx, s.trainingLoadSvc, userID, activity); err != nil {
+			return err
+		}
+		return s.plannedWorkoutSvc.CompleteMatchingSlot(ctx, userID, activity)
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create activity")
+		return nil, err
 	}
+
+	// Return the created activity with timestamps
+	response := newActivityResponse(activity, time.Now(), units)
+	response.OverlapsWith = overlaps
+	return &response, nil
 }
 
-func (s *activityService) CreateActivity(ctx context.Context, userID uint, req models.CreateActivityRequest) (*models.ActivityResponse, error) {
+func (s *activityService) CreateActivities(ctx context.Context, userID uint, reqs []models.CreateActivityRequest) ([]models.ActivityResponse, error) {
+	activities := make([]models.Activity, len(reqs))
+	for i, req := range reqs {
+		activity, err := newActivity(userID, req)
+		if err != nil {
+			return nil, err
+		}
+		activities[i] = activity
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.activityRepo.CreateActivities(ctx, activities); err != nil {
+			return err
+		}
+		if err := s.revisionSvc.RecordCreated(ctx, userID, models.RevisionSourceAPI, activities...); err != nil {
+			return err
+		}
+		if err := markLoadStale(ctx, s.trainingLoadSvc, userID, activities...); err != nil {
+			return err
+		}
+		for _, activity := range activities {
+			if err := s.plannedWorkoutSvc.CompleteMatchingSlot(ctx, userID, activity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create activities")
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]models.ActivityResponse, len(activities))
+	for i, activity := range activities {
+		responses[i] = newActivityResponse(activity, now, units)
+	}
+
+	return responses, nil
+}
+
+// DetectOverlaps returns, for every request, the existing activities its time span overlaps
+func (s *activityService) DetectOverlaps(ctx context.Context, userID uint, reqs []models.CreateActivityRequest) ([][]string, error) {
+	overlaps := make([][]string, len(reqs))
+	for i, req := range reqs {
+		doneAt, err := time.Parse(time.RFC3339, req.DoneAt)
+		if err != nil {
+			return nil, err
+		}
+		overlaps[i], err = s.overlappingActivityIDs(ctx, userID, doneAt, req.DurationInMinutes, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return overlaps, nil
+}
+
+// checkOverlaps applies the conflict policy to an activity about to be saved with the
+// given time span. It returns the overlapped activities under the flag policy and an
+// ActivityOverlapError under the reject policy.
+func (s *activityService) checkOverlaps(ctx context.Context, userID uint, doneAt time.Time, durationInMinutes int, excludeActivityID string, conflictPolicy string) ([]string, error) {
+	overlaps, err := s.overlappingActivityIDs(ctx, userID, doneAt, durationInMinutes, excludeActivityID)
+	if err != nil {
+		return nil, err
+	}
+	if len(overlaps) > 0 && conflictPolicy == models.ConflictPolicyReject {
+		return nil, &ActivityOverlapError{ActivityIDs: overlaps}
+	}
+	return overlaps, nil
+}
+
+// overlappingActivityIDs finds the user's activities that share at least
+// ActivityOverlapThreshold of the shorter time span with the given one
+func (s *activityService) overlappingActivityIDs(ctx context.Context, userID uint, doneAt time.Time, durationInMinutes int, excludeActivityID string) ([]string, error) {
+	endsAt := doneAt.Add(time.Duration(durationInMinutes) * time.Minute)
+
+	candidates, err := s.activityRepo.GetOverlappingActivities(ctx, userID, doneAt, endsAt, excludeActivityID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to check for overlapping activities")
+		return nil, err
+	}
+
+	var overlaps []string
+	for _, candidate := range candidates {
+		if models.OverlapRatio(doneAt, endsAt, candidate.DoneAt, candidate.EndsAt()) >= models.ActivityOverlapThreshold {
+			overlaps = append(overlaps, candidate.ActivityID)
+		}
+	}
+	return overlaps, nil
+}
+
+// newActivity builds an activity from a create request, calculating calories burned
+func newActivity(userID uint, req models.CreateActivityRequest) (models.Activity, error) {
 	// Parse the doneAt time
 	doneAt, err := time.Parse(time.RFC3339, req.DoneAt)
 	if err != nil {
 		log.Logger.Error().Err(err).Str("doneAt", req.DoneAt).Msg("Failed to parse doneAt time")
-		return nil, err
+		return models.Activity{}, err
 	}
 
 	// Calculate calories burned
 	caloriesPerMinute, exists := models.ActivityTypeCalories[req.ActivityType]
 	if !exists {
 		log.Logger.Error().Str("activityType", req.ActivityType).Msg("Invalid activity type")
-		return nil, err
+		return models.Activity{}, customErrors.ErrInvalidActivityType
 	}
 	caloriesBurned := caloriesPerMinute * req.DurationInMinutes
 
-	// Generate unique activity ID
-	activityID := uuid.New().String()
-
-	activity := models.Activity{
-		ActivityID:        activityID,
-		UserID:            userID,
-		ActivityType:      req.ActivityType,
-		DoneAt:            doneAt,
-		DurationInMinutes: req.DurationInMinutes,
-		CaloriesBurned:    caloriesBurned,
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.DefaultActivityVisibility
 	}
 
-	err = s.activityRepo.CreateActivity(ctx, activity)
+	err = ValidateActivityMetrics(req.ActivityType, req.DistanceMeters, req.ElevationGainMeters, req.PoolLengthMeters)
 	if err != nil {
-		log.Logger.Error().Err(err).Msg("Failed to create activity")
-		return nil, err
+		return models.Activity{}, err
 	}
 
-	// Return the created activity with timestamps
-	now := time.Now()
-	return &models.ActivityResponse{
-		ActivityID:        activityID,
-		ActivityType:      req.ActivityType,
-		DoneAt:            doneAt.Format(time.RFC3339),
-		DurationInMinutes: req.DurationInMinutes,
-		CaloriesBurned:    caloriesBurned,
-		CreatedAt:         now,
-		UpdatedAt:         now,
+	return models.Activity{
+		// Generate unique activity ID
+		ActivityID:          uuid.New().String(),
+		UserID:              userID,
+		ActivityType:        req.ActivityType,
+		DoneAt:              doneAt,
+		DurationInMinutes:   req.DurationInMinutes,
+		CaloriesBurned:      caloriesBurned,
+		DistanceMeters:      req.DistanceMeters,
+		ElevationGainMeters: req.ElevationGainMeters,
+		PoolLengthMeters:    req.PoolLengthMeters,
+		Notes:               normalizeNotes(req.Notes),
+		Tags:                models.NormalizeTags(req.Tags),
+		Visibility:          visibility,
 	}, nil
 }
 
-func (s *activityService) GetActivities(ctx context.Context, userID uint, query models.GetActivitiesQuery) ([]models.ActivityResponse, error) {
+// normalizeNotes trims notes, treating blank notes as no notes at all
+func normalizeNotes(notes *string) *string {
+	if notes == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*notes)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// ValidateActivityMetrics checks that distance and elevation gain are only recorded for
+// endurance activities and pool length only for swimming
+func ValidateActivityMetrics(activityType string, distanceMeters, elevationGainMeters, poolLengthMeters *float64) error {
+	if (distanceMeters != nil || elevationGainMeters != nil) && !models.EnduranceActivityTypes[activityType] {
+		return customErrors.ErrDistanceNotSupported
+	}
+	if poolLengthMeters != nil && activityType != "Swimming" {
+		return customErrors.ErrPoolLengthNotSupported
+	}
+	return nil
+}
+
+// unitSystemForUser looks up the unit system responses should be converted to
+func unitSystemForUser(ctx context.Context, profileRepo repositories.ProfileRepository, userID uint) (string, error) {
+	profile, err := profileRepo.GetProfileByID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get profile for unit preferences")
+		return "", err
+	}
+	return models.UnitSystemForProfile(profile), nil
+}
+
+// toActivityResponse builds the response for a stored activity
+func toActivityResponse(activity models.Activity, units string) models.ActivityResponse {
+	response := models.ActivityResponse{
+		ActivityID:          activity.ActivityID,
+		ActivityType:        activity.ActivityType,
+		DoneAt:              activity.DoneAt.Format(time.RFC3339),
+		DurationInMinutes:   activity.DurationInMinutes,
+		CaloriesBurned:      activity.CaloriesBurned,
+		DistanceMeters:      activity.DistanceMeters,
+		ElevationGainMeters: activity.ElevationGainMeters,
+		PoolLengthMeters:    activity.PoolLengthMeters,
+		Notes:               activity.Notes,
+		Tags:                activity.Tags,
+		Visibility:          activity.Visibility,
+		Version:             activity.Version,
+		CreatedAt:           activity.CreatedAt,
+		UpdatedAt:           activity.UpdatedAt,
+	}
+	setActivityPerformance(&response, units)
+	return response
+}
+
+// newActivityResponse builds the response for a freshly created activity
+func newActivityResponse(activity models.Activity, now time.Time, units string) models.ActivityResponse {
+	activity.CreatedAt = now
+	activity.UpdatedAt = now
+	activity.Version = 1
+	return toActivityResponse(activity, units)
+}
+
+// setActivityPerformance fills in distance and elevation in the given unit system and
+// derives pace and speed from the distance and duration
+func setActivityPerformance(response *models.ActivityResponse, units string) {
+	imperial := units == models.UnitSystemImperial
+
+	if response.ElevationGainMeters != nil {
+		elevation, unit := *response.ElevationGainMeters, models.ElevationUnitMeters
+		if imperial {
+			elevation, unit = elevation/models.MetersPerFoot, models.ElevationUnitFeet
+		}
+		elevation = roundTo(elevation, 1)
+		response.ElevationGain, response.ElevationUnit = &elevation, &unit
+	}
+
+	if response.DistanceMeters == nil || *response.DistanceMeters <= 0 {
+		return
+	}
+	meters := *response.DistanceMeters
+	seconds := float64(response.DurationInMinutes * 60)
+
+	distance, distanceUnit := meters/1000, models.DistanceUnitKilometers
+	speedUnit := models.SpeedUnitKilometersPerHour
+	if imperial {
+		distance, distanceUnit = meters/models.MetersPerMile, models.DistanceUnitMiles
+		speedUnit = models.SpeedUnitMilesPerHour
+	}
+	speed := roundTo(distance/(seconds/3600), 2)
+	distance = roundTo(distance, 2)
+	response.Distance, response.DistanceUnit = &distance, &distanceUnit
+	response.Speed, response.SpeedUnit = &speed, &speedUnit
+
+	// Cyclists think in speed rather than pace
+	if response.ActivityType == "Cycling" {
+		return
+	}
+
+	// Swimming pace is per 100 meters or yards, everything else per kilometer or mile
+	var pace float64
+	var paceUnit string
+	switch {
+	case response.ActivityType == "Swimming" && imperial:
+		pace, paceUnit = seconds/(meters/(100*models.MetersPerYard)), models.PaceUnitSecondsPer100Yards
+	case response.ActivityType == "Swimming":
+		pace, paceUnit = seconds/(meters/100), models.PaceUnitSecondsPer100Meters
+	case imperial:
+		pace, paceUnit = seconds/(meters/models.MetersPerMile), models.PaceUnitSecondsPerMile
+	default:
+		pace, paceUnit = seconds/(meters/1000), models.PaceUnitSecondsPerKilometer
+	}
+	pace = math.Round(pace)
+	response.Pace, response.PaceUnit = &pace, &paceUnit
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
+
+func (s *activityService) GetActivities(ctx context.Context, userID uint, query models.GetActivitiesQuery) (*models.ActivityPage, error) {
 	// Set default pagination if not provided
 	if query.Limit <= 0 {
 		query.Limit = 5
@@ -84,6 +379,26 @@ func (s *activityService) GetActivities(ctx context.Context, userID uint, query
 	if query.Offset < 0 {
 		query.Offset = 0
 	}
+	if _, _, ok := models.ParseActivitySort(query.Sort); !ok {
+		query.Sort = models.DefaultActivitySort
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeActivityCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+		query.AfterValue, query.AfterID = cursor.value, cursor.id
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to find out whether there is a next page
+	limit := query.Limit
+	query.Limit = limit + 1
 
 	activities, err := s.activityRepo.GetActivitiesByUserID(ctx, userID, query)
 	if err != nil {
@@ -91,23 +406,135 @@ func (s *activityService) GetActivities(ctx context.Context, userID uint, query
 		return nil, err
 	}
 
-	responses := make([]models.ActivityResponse, len(activities))
+	page := &models.ActivityPage{}
+	if len(activities) > limit {
+		activities = activities[:limit]
+		nextCursor := encodeActivityCursor(activities[limit-1], query.Sort)
+		page.NextCursor = &nextCursor
+	}
+
+	page.Activities = make([]models.ActivityResponse, len(activities))
 	for i, activity := range activities {
-		responses[i] = models.ActivityResponse{
-			ActivityID:        activity.ActivityID,
-			ActivityType:      activity.ActivityType,
-			DoneAt:            activity.DoneAt.Format(time.RFC3339),
-			DurationInMinutes: activity.DurationInMinutes,
-			CaloriesBurned:    activity.CaloriesBurned,
-			CreatedAt:         activity.CreatedAt,
-			UpdatedAt:         activity.UpdatedAt,
+		page.Activities[i] = toActivityResponse(activity, units)
+	}
+	if err := s.setAttachments(ctx, userID, page.Activities); err != nil {
+		return nil, err
+	}
+
+	if query.IncludeTotal {
+		total, err := s.activityRepo.CountActivitiesByUserID(ctx, userID, query)
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to count activities")
+			return nil, err
 		}
+		page.TotalCount = &total
 	}
 
-	return responses, nil
+	return page, nil
+}
+
+type activityCursor struct {
+	value interface{}
+	id    uint
+}
+
+// encodeActivityCursor builds the opaque cursor pointing just past the given activity
+func encodeActivityCursor(activity models.Activity, sort string) string {
+	column, _, _ := models.ParseActivitySort(sort)
+
+	var value string
+	switch column {
+	case "done_at":
+		value = activity.DoneAt.Format(time.RFC3339Nano)
+	case "created_at":
+		value = activity.CreatedAt.Format(time.RFC3339Nano)
+	case "calories_burned":
+		value = strconv.Itoa(activity.CaloriesBurned)
+	case "duration_in_minutes":
+		value = strconv.Itoa(activity.DurationInMinutes)
+	}
+
+	data, _ := json.Marshal(models.ActivityCursor{Sort: sort, Value: value, ID: activity.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
 }
 
-func (s *activityService) UpdateActivity(ctx context.Context, userID uint, activityID string, req models.UpdateActivityRequest) (*models.ActivityResponse, error) {
+// decodeActivityCursor unpacks a cursor, rejecting cursors issued for another sort order
+func decodeActivityCursor(encoded string, sort string) (activityCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return activityCursor{}, customErrors.ErrInvalidCursor
+	}
+
+	var cursor models.ActivityCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 || cursor.Sort != sort {
+		return activityCursor{}, customErrors.ErrInvalidCursor
+	}
+
+	column, _, _ := models.ParseActivitySort(sort)
+	switch column {
+	case "done_at", "created_at":
+		value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return activityCursor{}, customErrors.ErrInvalidCursor
+		}
+		return activityCursor{value: value, id: cursor.ID}, nil
+	default:
+		value, err := strconv.Atoi(cursor.Value)
+		if err != nil {
+			return activityCursor{}, customErrors.ErrInvalidCursor
+		}
+		return activityCursor{value: value, id: cursor.ID}, nil
+	}
+}
+
+// GetActivity returns a single activity of the user
+func (s *activityService) GetActivity(ctx context.Context, userID uint, activityID string) (*models.ActivityResponse, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.ActivityResponse{toActivityResponse(*activity, units)}
+	if err := s.setAttachments(ctx, userID, responses); err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// setAttachments fills in the attachments of the activity responses
+func (s *activityService) setAttachments(ctx context.Context, userID uint, responses []models.ActivityResponse) error {
+	if len(responses) == 0 {
+		return nil
+	}
+
+	activityIDs := make([]string, len(responses))
+	for i, response := range responses {
+		activityIDs[i] = response.ActivityID
+	}
+
+	attachments, err := s.attachmentSvc.GetAttachmentsByActivity(ctx, userID, activityIDs)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity attachments")
+		return err
+	}
+	for i := range responses {
+		responses[i].Attachments = attachments[responses[i].ActivityID]
+	}
+	return nil
+}
+
+// UpdateActivity applies a partial update. A non-zero expectedVersion makes the update
+// conditional on the activity not having changed since the client read that version.
+func (s *activityService) UpdateActivity(ctx context.Context, userID uint, activityID string, req models.UpdateActivityRequest, conflictPolicy string, expectedVersion int) (*models.ActivityResponse, error) {
 	// Check if activity exists
 	existingActivity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
 	if err != nil {
@@ -117,12 +544,16 @@ func (s *activityService) UpdateActivity(ctx context.Context, userID uint, activ
 	if existingActivity == nil {
 		return nil, customErrors.ErrActivityNotFound
 	}
+	if expectedVersion > 0 && existingActivity.Version != expectedVersion {
+		return nil, customErrors.ErrVersionMismatch
+	}
 
 	updates := make(map[string]interface{})
 	
 	// Track what fields are being updated for recalculation
 	var newActivityType string = existingActivity.ActivityType
 	var newDurationInMinutes int = existingActivity.DurationInMinutes
+	var newDoneAt time.Time = existingActivity.DoneAt
 
 	if req.ActivityType != nil {
 		updates["activity_type"] = *req.ActivityType
@@ -136,6 +567,7 @@ func (s *activityService) UpdateActivity(ctx context.Context, userID uint, activ
 			return nil, err
 		}
 		updates["done_at"] = doneAt
+		newDoneAt = doneAt
 	}
 
 	if req.DurationInMinutes != nil {
@@ -143,6 +575,41 @@ func (s *activityService) UpdateActivity(ctx context.Context, userID uint, activ
 		newDurationInMinutes = *req.DurationInMinutes
 	}
 
+	// Validate the metrics being set against the activity type they will end up on
+	err = ValidateActivityMetrics(newActivityType, req.DistanceMeters, req.ElevationGainMeters, req.PoolLengthMeters)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DistanceMeters != nil {
+		updates["distance_meters"] = *req.DistanceMeters
+	}
+	if req.ElevationGainMeters != nil {
+		updates["elevation_gain_meters"] = *req.ElevationGainMeters
+	}
+	if req.PoolLengthMeters != nil {
+		updates["pool_length_meters"] = *req.PoolLengthMeters
+	}
+
+	if req.Notes != nil {
+		updates["notes"] = normalizeNotes(req.Notes)
+	}
+	if req.Tags != nil {
+		updates["tags"] = models.NormalizeTags(*req.Tags)
+	}
+	if req.Visibility != nil {
+		updates["visibility"] = *req.Visibility
+	}
+
+	// Metrics recorded for the old activity type are dropped when it no longer supports them
+	if !models.EnduranceActivityTypes[newActivityType] {
+		updates["distance_meters"] = nil
+		updates["elevation_gain_meters"] = nil
+	}
+	if newActivityType != "Swimming" {
+		updates["pool_length_meters"] = nil
+	}
+
 	// Recalculate calories if activity type or duration changed
 	if req.ActivityType != nil || req.DurationInMinutes != nil {
 		caloriesPerMinute, exists := models.ActivityTypeCalories[newActivityType]
@@ -154,45 +621,102 @@ func (s *activityService) UpdateActivity(ctx context.Context, userID uint, activ
 		updates["calories_burned"] = newCaloriesBurned
 	}
 
+	// Only a change of time span can create an overlap, so other edits skip the check
+	var overlaps []string
+	if req.DoneAt != nil || req.DurationInMinutes != nil {
+		overlaps, err = s.checkOverlaps(ctx, userID, newDoneAt, newDurationInMinutes, activityID, conflictPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Add updated_at timestamp
 	updates["updated_at"] = time.Now()
 
-	err = s.activityRepo.UpdateActivity(ctx, activityID, userID, updates)
+	// The revision is recorded against the row the update replaces, which is locked for
+	// that, rather than the one read above
+	var replacedActivity, updatedActivity *models.Activity
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		replacedActivity, err = s.activityRepo.GetActivityForUpdate(ctx, activityID, userID)
+		if err != nil {
+			return err
+		}
+		if replacedActivity == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := s.activityRepo.UpdateActivity(ctx, activityID, userID, expectedVersion, updates); err != nil {
+			return err
+		}
+
+		updatedActivity, err = s.activityRepo.GetActivityByID(ctx, activityID, userID)
+		if err != nil {
+			return err
+		}
+		if updatedActivity == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := s.revisionSvc.RecordChanged(ctx, userID, models.RevisionOperationUpdate, *replacedActivity, *updatedActivity); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, *replacedActivity, *updatedActivity)
+	})
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
+			// The activity existed a moment ago, so a conditional update lost a race
+			if expectedVersion > 0 && replacedActivity != nil {
+				return nil, customErrors.ErrVersionMismatch
+			}
 			return nil, customErrors.ErrActivityNotFound
 		}
 		log.Logger.Error().Err(err).Msg("Failed to update activity")
 		return nil, err
 	}
 
-	// Get the updated activity to return
-	updatedActivity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
 	if err != nil {
-		log.Logger.Error().Err(err).Msg("Failed to get updated activity")
 		return nil, err
 	}
 
+	response := toActivityResponse(*updatedActivity, units)
+	response.OverlapsWith = overlaps
+
 	// Use the original request doneAt format if it was provided, otherwise use the stored format
-	doneAtString := updatedActivity.DoneAt.Format(time.RFC3339)
 	if req.DoneAt != nil {
 		// If doneAt was updated, use the original request format
-		doneAtString = *req.DoneAt
+		response.DoneAt = *req.DoneAt
 	}
 
-	return &models.ActivityResponse{
-		ActivityID:        updatedActivity.ActivityID,
-		ActivityType:      updatedActivity.ActivityType,
-		DoneAt:            doneAtString,
-		DurationInMinutes: updatedActivity.DurationInMinutes,
-		CaloriesBurned:    updatedActivity.CaloriesBurned,
-		CreatedAt:         updatedActivity.CreatedAt,
-		UpdatedAt:         updatedActivity.UpdatedAt,
-	}, nil
+	responses := []models.ActivityResponse{response}
+	if err := s.setAttachments(ctx, userID, responses); err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
 }
 
 func (s *activityService) DeleteActivity(ctx context.Context, userID uint, activityID string) error {
-	err := s.activityRepo.DeleteActivity(ctx, activityID, userID)
+	// The deleted activity is kept in its history as it was when it was deleted
+	var activity *models.Activity
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		activity, err = s.activityRepo.GetActivityForUpdate(ctx, activityID, userID)
+		if err != nil {
+			return err
+		}
+		if activity == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := s.activityRepo.DeleteActivity(ctx, activityID, userID); err != nil {
+			return err
+		}
+		if err := s.revisionSvc.RecordDeleted(ctx, userID, *activity); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, *activity)
+	})
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return customErrors.ErrActivityNotFound
@@ -203,3 +727,277 @@ func (s *activityService) DeleteActivity(ctx context.Context, userID uint, activ
 	return nil
 }
 
+
+func (s *activityService) GetTags(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+	usage, err := s.activityRepo.GetTagUsage(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get tags")
+		return nil, err
+	}
+	if usage == nil {
+		usage = []models.TagUsage{}
+	}
+	return usage, nil
+}
+
+func (s *activityService) GetDuplicateCandidates(ctx context.Context, userID uint, query models.GetDuplicatesQuery) ([]models.DuplicateCandidate, error) {
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	pairs, err := s.activityRepo.GetOverlappingPairs(ctx, userID, models.ActivityOverlapThreshold, query.Limit)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get duplicate candidates")
+		return nil, err
+	}
+
+	candidates := make([]models.DuplicateCandidate, 0, len(pairs))
+	if len(pairs) == 0 {
+		return candidates, nil
+	}
+
+	activityIDs := make([]string, 0, len(pairs)*2)
+	for _, pair := range pairs {
+		activityIDs = append(activityIDs, pair.FirstActivityID, pair.SecondActivityID)
+	}
+	activities, err := s.activityRepo.GetActivitiesByIDs(ctx, userID, activityIDs)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get duplicate candidate activities")
+		return nil, err
+	}
+	byID := make(map[string]models.Activity, len(activities))
+	for _, activity := range activities {
+		byID[activity.ActivityID] = activity
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range pairs {
+		first, firstFound := byID[pair.FirstActivityID]
+		second, secondFound := byID[pair.SecondActivityID]
+		if !firstFound || !secondFound {
+			continue
+		}
+
+		// List the earlier activity first, as a client would show them on a timeline
+		if second.DoneAt.Before(first.DoneAt) {
+			first, second = second, first
+		}
+
+		ratio := 1.0
+		if pair.ShorterMinutes > 0 {
+			ratio = math.Min(pair.OverlapMinutes/float64(pair.ShorterMinutes), 1)
+		}
+
+		candidates = append(candidates, models.DuplicateCandidate{
+			Activities:       []models.ActivityResponse{toActivityResponse(first, units), toActivityResponse(second, units)},
+			OverlapRatio:     roundTo(ratio, 2),
+			SameActivityType: first.ActivityType == second.ActivityType,
+		})
+	}
+
+	return candidates, nil
+}
+
+func (s *activityService) MergeActivities(ctx context.Context, userID uint, req models.MergeActivitiesRequest) (*models.MergeActivitiesResponse, error) {
+	activityIDs := make([]string, 0, len(req.ActivityIDs)+1)
+	seen := make(map[string]bool, len(req.ActivityIDs)+1)
+	for _, activityID := range append([]string{req.PrimaryActivityID}, req.ActivityIDs...) {
+		if activityID == "" || seen[activityID] {
+			continue
+		}
+		seen[activityID] = true
+		activityIDs = append(activityIDs, activityID)
+	}
+	if len(activityIDs) < 2 {
+		return nil, customErrors.ErrNothingToMerge
+	}
+
+	// The activities are locked while they are merged, so the combined values and the
+	// revisions come from the rows the merge replaces
+	var activities []models.Activity
+	var merged *models.Activity
+	var mergedActivityIDs []string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		activities, err = s.activityRepo.GetActivitiesForUpdate(ctx, userID, activityIDs)
+		if err != nil {
+			return err
+		}
+		if len(activities) != len(activityIDs) {
+			return customErrors.ErrActivityNotFound
+		}
+
+		primary := mergePrimary(activities, req.PrimaryActivityID)
+
+		// Only activities that GetDuplicateCandidates would pair with the primary one are merged
+		for _, activity := range activities {
+			if activity.ActivityID == primary.ActivityID {
+				continue
+			}
+			if models.OverlapRatio(primary.DoneAt, primary.EndsAt(), activity.DoneAt, activity.EndsAt()) < models.ActivityOverlapThreshold {
+				return customErrors.ErrActivitiesNotOverlapping
+			}
+		}
+
+		updates := mergedActivityUpdates(primary, activities)
+
+		mergedActivityIDs = make([]string, 0, len(activities)-1)
+		mergedActivities := make([]models.Activity, 0, len(activities)-1)
+		for _, activity := range activities {
+			if activity.ActivityID != primary.ActivityID {
+				mergedActivityIDs = append(mergedActivityIDs, activity.ActivityID)
+				mergedActivities = append(mergedActivities, activity)
+			}
+		}
+
+		ok, err := s.activityRepo.MergeActivities(ctx, userID, primary.ActivityID, updates, mergedActivityIDs, constant.MaxActivityAttachments)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return customErrors.ErrTooManyAttachments
+		}
+
+		merged, err = s.activityRepo.GetActivityByID(ctx, primary.ActivityID, userID)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			return customErrors.ErrActivityNotFound
+		}
+
+		if err := s.revisionSvc.RecordChanged(ctx, userID, models.RevisionOperationMerge, primary, *merged); err != nil {
+			return err
+		}
+		if err := s.revisionSvc.RecordDeleted(ctx, userID, mergedActivities...); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, activities...)
+	})
+	if err != nil {
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			return nil, customErrors.ErrActivityNotFound
+		case err == customErrors.ErrActivityNotFound, err == customErrors.ErrActivitiesNotOverlapping, err == customErrors.ErrTooManyAttachments:
+			return nil, err
+		}
+		log.Logger.Error().Err(err).Msg("Failed to merge activities")
+		return nil, err
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MergeActivitiesResponse{
+		Activity:          toActivityResponse(*merged, units),
+		MergedActivityIDs: mergedActivityIDs,
+	}, nil
+}
+
+// mergePrimary picks the activity the others are merged into. Without an explicit choice
+// an imported activity is preferred, since it may carry a GPS track and laps, and then
+// the longest one.
+func mergePrimary(activities []models.Activity, primaryActivityID string) models.Activity {
+	var primary models.Activity
+	for i, activity := range activities {
+		if primaryActivityID != "" {
+			if activity.ActivityID == primaryActivityID {
+				return activity
+			}
+			continue
+		}
+		if i == 0 {
+			primary = activity
+			continue
+		}
+		imported, primaryImported := activity.FileID != nil, primary.FileID != nil
+		if imported != primaryImported {
+			if imported {
+				primary = activity
+			}
+			continue
+		}
+		if activity.DurationInMinutes > primary.DurationInMinutes {
+			primary = activity
+		}
+	}
+	return primary
+}
+
+// mergedActivityUpdates combines the activities into the primary one: it spans from the
+// earliest start to the latest end, keeps its own type and fills missing metrics from the
+// others, while notes and tags of all activities are kept
+func mergedActivityUpdates(primary models.Activity, activities []models.Activity) map[string]interface{} {
+	start, end := primary.DoneAt, primary.EndsAt()
+	for _, activity := range activities {
+		if activity.DoneAt.Before(start) {
+			start = activity.DoneAt
+		}
+		if activity.EndsAt().After(end) {
+			end = activity.EndsAt()
+		}
+	}
+	durationInMinutes := int(math.Ceil(end.Sub(start).Minutes()))
+
+	updates := map[string]interface{}{
+		"done_at":             start,
+		"duration_in_minutes": durationInMinutes,
+		"calories_burned":     models.ActivityTypeCalories[primary.ActivityType] * durationInMinutes,
+		"updated_at":          time.Now(),
+	}
+
+	// Metrics are only taken over where the primary activity's type supports them
+	distance, elevation, poolLength := primary.DistanceMeters, primary.ElevationGainMeters, primary.PoolLengthMeters
+	notes := make([]string, 0, len(activities))
+	tags := make([]string, 0)
+	for _, activity := range append([]models.Activity{primary}, activities...) {
+		if distance == nil && activity.DistanceMeters != nil {
+			distance = activity.DistanceMeters
+		}
+		if elevation == nil && activity.ElevationGainMeters != nil {
+			elevation = activity.ElevationGainMeters
+		}
+		if poolLength == nil && activity.PoolLengthMeters != nil {
+			poolLength = activity.PoolLengthMeters
+		}
+		if activity.Notes != nil && !slices.Contains(notes, *activity.Notes) {
+			notes = append(notes, *activity.Notes)
+		}
+		tags = append(tags, activity.Tags...)
+	}
+	if models.EnduranceActivityTypes[primary.ActivityType] {
+		updates["distance_meters"] = distance
+		updates["elevation_gain_meters"] = elevation
+	}
+	if primary.ActivityType == "Swimming" {
+		updates["pool_length_meters"] = poolLength
+	}
+
+	if len(notes) > 0 {
+		combined := []rune(strings.Join(notes, "\n\n"))
+		if len(combined) > maxActivityNotesLength {
+			combined = combined[:maxActivityNotesLength]
+		}
+		updates["notes"] = string(combined)
+	}
+
+	mergedTags := models.NormalizeTags(tags)
+	if len(mergedTags) > maxActivityTags {
+		mergedTags = mergedTags[:maxActivityTags]
+	}
+	updates["tags"] = mergedTags
+
+	return updates
+}
+
+// Limits on merged notes and tags, matching the validation of activity requests
+const (
+	maxActivityNotesLength = 2000
+	maxActivityTags        = 20
+)
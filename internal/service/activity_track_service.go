@@ -0,0 +1,259 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/geo"
+	"FitByte/pkg/log"
+	"FitByte/pkg/workout"
+	"context"
+	"math"
+	"time"
+)
+
+type ActivityTrackService interface {
+	GetActivityTrack(ctx context.Context, userID uint, activityID string, query models.GetActivityTrackQuery) (interface{}, error)
+	GetActivitySplits(ctx context.Context, userID uint, activityID string) (*models.ActivitySplitsResponse, error)
+}
+
+type activityTrackService struct {
+	activityRepo repositories.ActivityRepository
+	trackRepo    repositories.ActivityTrackRepository
+	lapRepo      repositories.ActivityLapRepository
+	profileRepo  repositories.ProfileRepository
+}
+
+func NewActivityTrackService(activityRepo repositories.ActivityRepository, trackRepo repositories.ActivityTrackRepository, lapRepo repositories.ActivityLapRepository, profileRepo repositories.ProfileRepository) ActivityTrackService {
+	return &activityTrackService{
+		activityRepo: activityRepo,
+		trackRepo:    trackRepo,
+		lapRepo:      lapRepo,
+		profileRepo:  profileRepo,
+	}
+}
+
+// GetActivityTrack returns the activity's route as an encoded polyline or a GeoJSON
+// feature, simplified to query.Tolerance meters when one is given
+func (s *activityTrackService) GetActivityTrack(ctx context.Context, userID uint, activityID string, query models.GetActivityTrackQuery) (interface{}, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity for track")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	track, err := s.trackRepo.GetTrackByActivityID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity track")
+		return nil, err
+	}
+	if track == nil {
+		return nil, customErrors.ErrTrackNotFound
+	}
+
+	points, err := geo.DecodeTrack(track.Points)
+	if err != nil {
+		log.Logger.Error().Err(err).Str("activityId", activityID).Msg("Failed to decode activity track")
+		return nil, err
+	}
+	points = geo.Simplify(points, query.Tolerance)
+
+	bbox := [4]float64{track.MinLongitude, track.MinLatitude, track.MaxLongitude, track.MaxLatitude}
+
+	if query.Format == models.TrackFormatGeoJSON {
+		coordinates := make([][]float64, len(points))
+		for i, p := range points {
+			if p.HasElevation {
+				coordinates[i] = []float64{p.Longitude, p.Latitude, p.Elevation}
+			} else {
+				coordinates[i] = []float64{p.Longitude, p.Latitude}
+			}
+		}
+
+		return models.GeoJSONFeature{
+			Type:        "Feature",
+			BoundingBox: bbox,
+			Geometry: models.GeoJSONLineString{
+				Type:        "LineString",
+				Coordinates: coordinates,
+			},
+			Properties: map[string]interface{}{
+				"activityId":   activity.ActivityID,
+				"activityType": activity.ActivityType,
+				"doneAt":       activity.DoneAt.Format(time.RFC3339),
+				"pointCount":   len(points),
+			},
+		}, nil
+	}
+
+	return models.ActivityTrackResponse{
+		ActivityID:  activity.ActivityID,
+		Format:      models.TrackFormatPolyline,
+		PointCount:  len(points),
+		BoundingBox: bbox,
+		Polyline:    geo.EncodePolyline(points),
+	}, nil
+}
+
+// GetActivitySplits returns per-kilometre or per-mile splits of the activity's GPS track,
+// depending on the user's units, together with the laps recorded by the device
+func (s *activityTrackService) GetActivitySplits(ctx context.Context, userID uint, activityID string) (*models.ActivitySplitsResponse, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity for splits")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	splitMeters, distanceUnit, paceUnit := 1000.0, models.DistanceUnitKilometers, models.PaceUnitSecondsPerKilometer
+	elevationUnit, metersPerElevationUnit := models.ElevationUnitMeters, 1.0
+	if units == models.UnitSystemImperial {
+		splitMeters, distanceUnit, paceUnit = models.MetersPerMile, models.DistanceUnitMiles, models.PaceUnitSecondsPerMile
+		elevationUnit, metersPerElevationUnit = models.ElevationUnitFeet, models.MetersPerFoot
+	}
+
+	response := &models.ActivitySplitsResponse{
+		ActivityID: activity.ActivityID,
+		Splits:     []models.SplitResponse{},
+		Laps:       []models.LapResponse{},
+	}
+
+	track, err := s.trackRepo.GetTrackByActivityID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity track for splits")
+		return nil, err
+	}
+	if track != nil {
+		points, err := geo.DecodeTrack(track.Points)
+		if err != nil {
+			log.Logger.Error().Err(err).Str("activityId", activityID).Msg("Failed to decode activity track")
+			return nil, err
+		}
+
+		for i, split := range geo.Splits(points, splitMeters) {
+			seconds := split.Duration.Seconds()
+			splitResponse := models.SplitResponse{
+				Split:             i + 1,
+				DistanceMeters:    roundTo(split.DistanceMeters, 1),
+				Distance:          roundTo(split.DistanceMeters/splitMeters, 2),
+				DistanceUnit:      distanceUnit,
+				DurationInSeconds: int(math.Round(seconds)),
+				Pace:              math.Round(seconds / (split.DistanceMeters / splitMeters)),
+				PaceUnit:          paceUnit,
+				ElevationUnit:     elevationUnit,
+			}
+			if split.HasElevation {
+				elevationChange := roundTo(split.ElevationChange/metersPerElevationUnit, 1)
+				splitResponse.ElevationChange = &elevationChange
+			}
+			if split.AvgHeartRate > 0 {
+				avgHeartRate := split.AvgHeartRate
+				splitResponse.AvgHeartRate = &avgHeartRate
+			}
+			response.Splits = append(response.Splits, splitResponse)
+		}
+	}
+
+	laps, err := s.lapRepo.GetLapsByActivityID(ctx, activity.ActivityID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity laps")
+		return nil, err
+	}
+	for _, lap := range laps {
+		lapResponse := models.LapResponse{
+			Lap:               lap.LapIndex + 1,
+			StartTime:         lap.StartTime,
+			DurationInSeconds: lap.DurationInSeconds,
+			DistanceMeters:    roundTo(lap.DistanceMeters, 1),
+			Distance:          roundTo(lap.DistanceMeters/splitMeters, 2),
+			DistanceUnit:      distanceUnit,
+			PaceUnit:          paceUnit,
+			Calories:          positiveIntOrNil(lap.Calories),
+			AvgHeartRate:      positiveIntOrNil(lap.AvgHeartRate),
+			MaxHeartRate:      positiveIntOrNil(lap.MaxHeartRate),
+		}
+		if lap.DistanceMeters > 0 {
+			pace := math.Round(float64(lap.DurationInSeconds) / (lap.DistanceMeters / splitMeters))
+			lapResponse.Pace = &pace
+		}
+		response.Laps = append(response.Laps, lapResponse)
+	}
+
+	return response, nil
+}
+
+// newActivityTrack builds the stored route for an activity from the points of a
+// workout file, or returns nil when the file has no GPS data
+func newActivityTrack(activity models.Activity, points []workout.Point) *models.ActivityTrack {
+	route := workoutRoute(points)
+
+	bounds, ok := geo.Bounds(route)
+	if !ok {
+		return nil
+	}
+
+	return &models.ActivityTrack{
+		ActivityID:   activity.ActivityID,
+		UserID:       activity.UserID,
+		PointCount:   len(route),
+		Points:       geo.EncodeTrack(route),
+		MinLatitude:  bounds.MinLatitude,
+		MinLongitude: bounds.MinLongitude,
+		MaxLatitude:  bounds.MaxLatitude,
+		MaxLongitude: bounds.MaxLongitude,
+	}
+}
+
+// workoutRoute returns the positioned points of a workout as a route
+func workoutRoute(points []workout.Point) []geo.Point {
+	route := make([]geo.Point, 0, len(points))
+	for _, p := range points {
+		if !p.HasPosition {
+			continue
+		}
+		route = append(route, geo.Point{
+			Latitude:     p.Latitude,
+			Longitude:    p.Longitude,
+			Elevation:    p.Elevation,
+			HasElevation: p.HasElevation,
+			Time:         p.Time,
+			HeartRate:    p.HeartRate,
+		})
+	}
+	return route
+}
+
+// newActivityLaps converts the device laps of a workout file for storage
+func newActivityLaps(activity models.Activity, laps []workout.Lap) []models.ActivityLap {
+	activityLaps := make([]models.ActivityLap, len(laps))
+	for i, lap := range laps {
+		activityLaps[i] = models.ActivityLap{
+			ActivityID:        activity.ActivityID,
+			LapIndex:          i,
+			StartTime:         lap.StartTime,
+			DurationInSeconds: int(math.Round(lap.Duration.Seconds())),
+			DistanceMeters:    lap.DistanceMeters,
+			Calories:          lap.Calories,
+			AvgHeartRate:      lap.AvgHeartRate,
+			MaxHeartRate:      lap.MaxHeartRate,
+		}
+	}
+	return activityLaps
+}
+
+func positiveIntOrNil(value int) *int {
+	if value <= 0 {
+		return nil
+	}
+	return &value
+}
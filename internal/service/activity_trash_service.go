@@ -0,0 +1,144 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultTrashRetention applies when no retention is configured
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+type ActivityTrashService interface {
+	GetTrash(ctx context.Context, userID uint, query models.GetTrashQuery) ([]models.TrashedActivityResponse, error)
+	RestoreActivity(ctx context.Context, userID uint, activityID string) (*models.ActivityResponse, error)
+	StartRetentionPurge(interval time.Duration)
+}
+
+type activityTrashService struct {
+	activityRepo    repositories.ActivityRepository
+	profileRepo     repositories.ProfileRepository
+	transactor      repositories.Transactor
+	revisionSvc     ActivityRevisionService
+	attachmentSvc   ActivityAttachmentService
+	trainingLoadSvc TrainingLoadService
+	retention       time.Duration
+}
+
+func NewActivityTrashService(activityRepo repositories.ActivityRepository, profileRepo repositories.ProfileRepository, transactor repositories.Transactor, revisionService ActivityRevisionService, attachmentService ActivityAttachmentService, trainingLoadService TrainingLoadService, retention time.Duration) ActivityTrashService {
+	if retention <= 0 {
+		retention = defaultTrashRetention
+	}
+	return &activityTrashService{
+		activityRepo:    activityRepo,
+		profileRepo:     profileRepo,
+		transactor:      transactor,
+		revisionSvc:     revisionService,
+		attachmentSvc:   attachmentService,
+		trainingLoadSvc: trainingLoadService,
+		retention:       retention,
+	}
+}
+
+// GetTrash lists the user's deleted activities with the time each one will be purged
+func (s *activityTrashService) GetTrash(ctx context.Context, userID uint, query models.GetTrashQuery) ([]models.TrashedActivityResponse, error) {
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	activities, err := s.activityRepo.GetDeletedActivities(ctx, userID, query.Limit, query.Offset)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity trash")
+		return nil, err
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.TrashedActivityResponse, len(activities))
+	for i, activity := range activities {
+		deletedAt := activity.DeletedAt.Time
+		responses[i] = models.TrashedActivityResponse{
+			ActivityResponse: toActivityResponse(activity, units),
+			DeletedAt:        deletedAt,
+			PurgeAt:          deletedAt.Add(s.retention),
+		}
+	}
+
+	return responses, nil
+}
+
+// RestoreActivity takes an activity out of the trash. Its track, laps and any planned
+// workout it completed come back with it.
+func (s *activityTrashService) RestoreActivity(ctx context.Context, userID uint, activityID string) (*models.ActivityResponse, error) {
+	var activity *models.Activity
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.activityRepo.RestoreActivity(ctx, activityID, userID); err != nil {
+			return err
+		}
+
+		var err error
+		activity, err = s.activityRepo.GetActivityByID(ctx, activityID, userID)
+		if err != nil {
+			return err
+		}
+		if activity == nil {
+			return customErrors.ErrActivityNotFound
+		}
+
+		if err := s.revisionSvc.RecordRestored(ctx, userID, *activity); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, *activity)
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customErrors.ErrActivityNotInTrash
+		}
+		if err != customErrors.ErrActivityNotFound {
+			log.Logger.Error().Err(err).Msg("Failed to restore activity")
+		}
+		return nil, err
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toActivityResponse(*activity, units)
+	return &response, nil
+}
+
+// StartRetentionPurge permanently removes activities that have been in the trash longer
+// than the retention window, checking every interval
+func (s *activityTrashService) StartRetentionPurge(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			before := time.Now().Add(-s.retention)
+			if err := s.attachmentSvc.PurgeAttachments(context.Background(), before); err != nil {
+				log.Logger.Error().Err(err).Msg("Failed to purge attachments of trashed activities")
+				continue
+			}
+
+			purged, err := s.activityRepo.PurgeDeletedActivities(context.Background(), before)
+			if err != nil {
+				log.Logger.Error().Err(err).Msg("Failed to purge trashed activities")
+				continue
+			}
+			if purged > 0 {
+				log.Logger.Info().Int64("purged", purged).Msg("Purged trashed activities")
+			}
+		}
+	}()
+}
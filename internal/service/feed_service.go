@@ -0,0 +1,207 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// FeedService builds the activity feed: the shared activities of the users a user
+// follows, newest first. It also lists the activities of a single user that someone
+// else may see.
+type FeedService interface {
+	GetFeed(ctx context.Context, userID uint, query models.GetFeedQuery) (*models.FeedPage, error)
+	GetUserActivities(ctx context.Context, viewerID, userID uint, query models.GetFeedQuery) (*models.UserActivitiesPage, error)
+}
+
+type feedService struct {
+	activityRepo repositories.ActivityRepository
+	profileRepo  repositories.ProfileRepository
+	followRepo   repositories.FollowRepository
+}
+
+func NewFeedService(activityRepo repositories.ActivityRepository, profileRepo repositories.ProfileRepository, followRepo repositories.FollowRepository) FeedService {
+	return &feedService{
+		activityRepo: activityRepo,
+		profileRepo:  profileRepo,
+		followRepo:   followRepo,
+	}
+}
+
+// GetFeed returns a page of the feed. Distances are in the reader's units.
+func (s *feedService) GetFeed(ctx context.Context, userID uint, query models.GetFeedQuery) (*models.FeedPage, error) {
+	query, limit, err := feedPageQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.activityRepo.GetFeedActivities(ctx, userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.FeedPage{}
+	if len(activities) > limit {
+		activities = activities[:limit]
+		nextCursor := encodeFeedCursor(activities[limit-1])
+		page.NextCursor = &nextCursor
+	}
+
+	users, err := s.feedUsers(ctx, activities)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Items = make([]models.FeedItemResponse, len(activities))
+	for i, activity := range activities {
+		page.Items[i] = models.FeedItemResponse{
+			User:     users[activity.UserID],
+			Activity: toActivityResponse(activity, units),
+		}
+	}
+
+	return page, nil
+}
+
+// GetUserActivities returns a page of the user's activities the viewer may see: public
+// ones to anyone, followers ones too to accepted followers and all of them to the user
+// themselves. Distances are in the viewer's units.
+func (s *feedService) GetUserActivities(ctx context.Context, viewerID, userID uint, query models.GetFeedQuery) (*models.UserActivitiesPage, error) {
+	query, limit, err := feedPageQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.profileRepo.GetProfileByID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get profile of activities")
+		return nil, err
+	}
+	if user == nil {
+		return nil, customErrors.ErrorUserNotFound
+	}
+
+	visibilities := []string{models.ActivityVisibilityPublic}
+	if viewerID == userID {
+		visibilities = append(visibilities, models.ActivityVisibilityFollowers, models.ActivityVisibilityPrivate)
+	} else {
+		follow, err := s.followRepo.GetFollow(ctx, viewerID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if follow != nil && follow.Status == models.FollowStatusAccepted {
+			visibilities = append(visibilities, models.ActivityVisibilityFollowers)
+		}
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.activityRepo.GetVisibleActivities(ctx, userID, visibilities, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.UserActivitiesPage{}
+	if len(activities) > limit {
+		activities = activities[:limit]
+		nextCursor := encodeFeedCursor(activities[limit-1])
+		page.NextCursor = &nextCursor
+	}
+
+	page.Items = make([]models.ActivityResponse, len(activities))
+	for i, activity := range activities {
+		page.Items[i] = toActivityResponse(activity, units)
+	}
+
+	return page, nil
+}
+
+// feedPageQuery fills in the default page size and the position of the cursor. The
+// returned query fetches one activity more than the page holds to find out whether there
+// is a next page.
+func feedPageQuery(query models.GetFeedQuery) (models.GetFeedQuery, int, error) {
+	if query.Limit <= 0 {
+		query.Limit = models.DefaultFeedLimit
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeFeedCursor(query.Cursor)
+		if err != nil {
+			return query, 0, err
+		}
+		query.BeforeDoneAt, query.BeforeID = cursor.DoneAt, cursor.ID
+	}
+
+	limit := query.Limit
+	query.Limit = limit + 1
+	return query, limit, nil
+}
+
+// feedUsers looks up who did the activities, once per user
+func (s *feedService) feedUsers(ctx context.Context, activities []models.Activity) (map[uint]models.FeedUserResponse, error) {
+	users := make(map[uint]models.FeedUserResponse)
+	userIDs := make([]uint, 0)
+	for _, activity := range activities {
+		if _, seen := users[activity.UserID]; !seen {
+			users[activity.UserID] = models.FeedUserResponse{UserID: activity.UserID}
+			userIDs = append(userIDs, activity.UserID)
+		}
+	}
+
+	profiles, err := s.profileRepo.GetProfilesByIDs(ctx, userIDs)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get feed users")
+		return nil, err
+	}
+	for _, profile := range profiles {
+		users[profile.ID] = models.FeedUserResponse{
+			UserID:   profile.ID,
+			Name:     optionalString(profile.Name),
+			ImageURI: optionalString(profile.ImageURI),
+		}
+	}
+
+	return users, nil
+}
+
+type feedCursor struct {
+	DoneAt time.Time
+	ID     uint
+}
+
+// encodeFeedCursor builds the opaque cursor pointing just past the given activity
+func encodeFeedCursor(activity models.Activity) string {
+	data, _ := json.Marshal(models.FeedCursor{DoneAt: activity.DoneAt.Format(time.RFC3339Nano), ID: activity.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeFeedCursor(encoded string) (feedCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return feedCursor{}, customErrors.ErrInvalidCursor
+	}
+
+	var cursor models.FeedCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return feedCursor{}, customErrors.ErrInvalidCursor
+	}
+
+	doneAt, err := time.Parse(time.RFC3339Nano, cursor.DoneAt)
+	if err != nil {
+		return feedCursor{}, customErrors.ErrInvalidCursor
+	}
+	return feedCursor{DoneAt: doneAt, ID: cursor.ID}, nil
+}
@@ -9,6 +9,8 @@ import (
 
 type FileService interface {
 	SaveFileUpload(ctx context.Context, userID int64, file models.UploadFile) (string, error)
+	SaveFile(ctx context.Context, userID int64, file models.UploadFile) (*models.File, error)
+	DeleteFile(ctx context.Context, file *models.File)
 }
 
 type fileService struct {
@@ -24,23 +26,50 @@ func NewFileService(fileRepo repositories.FileRepository, storageRepo repositori
 }
 
 func (s *fileService) SaveFileUpload(ctx context.Context, userID int64, file models.UploadFile) (string, error) {
-	key, err := s.minioRepo.UploadFile(ctx, file)
+	saved, err := s.SaveFile(ctx, userID, file)
 	if err != nil {
-		log.Logger.Error().Err(err).Msg("minioRepo.UploadFile")
 		return "", err
 	}
 
+	return saved.FileURL, nil
+}
+
+func (s *fileService) SaveFile(ctx context.Context, userID int64, file models.UploadFile) (*models.File, error) {
+	_, err := s.minioRepo.UploadFile(ctx, file)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("minioRepo.UploadFile")
+		return nil, err
+	}
+
 	fileTableSchema := models.File{
 		UserID:   userID,
 		FileName: file.FileName,
 		FileURL:  file.FilePath,
 	}
 
-	err = s.fileRepo.Insert(ctx, fileTableSchema)
+	err = s.fileRepo.Insert(ctx, &fileTableSchema)
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("fileRepo.Insert")
-		return "", err
+		// Nothing refers to the uploaded object without its row
+		if err := s.minioRepo.RemoveFile(context.WithoutCancel(ctx), file.FilePath); err != nil {
+			log.Logger.Error().Err(err).Msg("minioRepo.RemoveFile")
+		}
+		return nil, err
 	}
 
-	return key, nil
+	return &fileTableSchema, nil
+}
+
+// DeleteFile removes a saved file that ended up unused, its row and its object in
+// storage. Its callers are already returning the error that made the file unused, so a
+// failed cleanup is logged instead of replacing that error.
+func (s *fileService) DeleteFile(ctx context.Context, file *models.File) {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.fileRepo.Delete(ctx, file.ID); err != nil {
+		log.Logger.Error().Err(err).Uint("fileID", file.ID).Msg("fileRepo.Delete")
+		return
+	}
+	if err := s.minioRepo.RemoveFile(ctx, file.FileURL); err != nil {
+		log.Logger.Error().Err(err).Uint("fileID", file.ID).Msg("minioRepo.RemoveFile")
+	}
 }
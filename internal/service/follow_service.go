@@ -0,0 +1,192 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FollowService manages who follows whom. Following a public account takes effect at
+// once; following a private account sends a request the account accepts or declines.
+type FollowService interface {
+	Follow(ctx context.Context, userID, followeeID uint) (*models.FollowResponse, bool, error)
+	Unfollow(ctx context.Context, userID, followeeID uint) error
+	GetFollowers(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error)
+	GetFollowing(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error)
+	GetFollowRequests(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error)
+	AcceptFollowRequest(ctx context.Context, userID, followerID uint) error
+	DeclineFollowRequest(ctx context.Context, userID, followerID uint) error
+	RemoveFollower(ctx context.Context, userID, followerID uint) error
+	AcceptPendingRequests(ctx context.Context, userID uint)
+}
+
+type followService struct {
+	followRepo  repositories.FollowRepository
+	profileRepo repositories.ProfileRepository
+}
+
+func NewFollowService(followRepo repositories.FollowRepository, profileRepo repositories.ProfileRepository) FollowService {
+	return &followService{
+		followRepo:  followRepo,
+		profileRepo: profileRepo,
+	}
+}
+
+// Follow follows the followee, or asks to when the followee's account is private. It
+// reports whether a new follow was made; following again returns the existing one.
+func (s *followService) Follow(ctx context.Context, userID, followeeID uint) (*models.FollowResponse, bool, error) {
+	if userID == followeeID {
+		return nil, false, customErrors.ErrCannotFollowSelf
+	}
+
+	followee, err := s.profileRepo.GetProfileByID(ctx, followeeID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get profile to follow")
+		return nil, false, err
+	}
+	if followee == nil {
+		return nil, false, customErrors.ErrorUserNotFound
+	}
+
+	follow := models.Follow{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+		Status:     models.FollowStatusAccepted,
+	}
+	if followee.IsPrivate {
+		follow.Status = models.FollowStatusPending
+	} else {
+		now := time.Now()
+		follow.AcceptedAt = &now
+	}
+
+	created, err := s.followRepo.CreateFollow(ctx, &follow)
+	if err != nil {
+		return nil, false, err
+	}
+	if !created {
+		existing, err := s.followRepo.GetFollow(ctx, userID, followeeID)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing == nil {
+			// Unfollowed in the meantime
+			return nil, false, customErrors.ErrFollowNotFound
+		}
+		follow = *existing
+	}
+
+	return &models.FollowResponse{UserID: followeeID, Status: follow.Status}, created, nil
+}
+
+// Unfollow stops following the followee or withdraws the request to
+func (s *followService) Unfollow(ctx context.Context, userID, followeeID uint) error {
+	err := s.followRepo.DeleteFollow(ctx, userID, followeeID, "")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return customErrors.ErrFollowNotFound
+	}
+	return err
+}
+
+func (s *followService) GetFollowers(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error) {
+	query = withFollowListDefaults(query)
+	users, err := s.followRepo.GetFollowers(ctx, userID, models.FollowStatusAccepted, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return toFollowUserResponses(users), nil
+}
+
+// GetFollowing lists the users the user follows, pending requests included
+func (s *followService) GetFollowing(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error) {
+	query = withFollowListDefaults(query)
+	users, err := s.followRepo.GetFollowing(ctx, userID, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return toFollowUserResponses(users), nil
+}
+
+// GetFollowRequests lists the users waiting for the user to accept their follow request
+func (s *followService) GetFollowRequests(ctx context.Context, userID uint, query models.ListFollowsQuery) ([]models.FollowUserResponse, error) {
+	query = withFollowListDefaults(query)
+	users, err := s.followRepo.GetFollowers(ctx, userID, models.FollowStatusPending, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return toFollowUserResponses(users), nil
+}
+
+func (s *followService) AcceptFollowRequest(ctx context.Context, userID, followerID uint) error {
+	err := s.followRepo.AcceptFollow(ctx, followerID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return customErrors.ErrFollowRequestNotFound
+	}
+	return err
+}
+
+func (s *followService) DeclineFollowRequest(ctx context.Context, userID, followerID uint) error {
+	err := s.followRepo.DeleteFollow(ctx, followerID, userID, models.FollowStatusPending)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return customErrors.ErrFollowRequestNotFound
+	}
+	return err
+}
+
+// RemoveFollower stops a user from following the user
+func (s *followService) RemoveFollower(ctx context.Context, userID, followerID uint) error {
+	err := s.followRepo.DeleteFollow(ctx, followerID, userID, models.FollowStatusAccepted)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return customErrors.ErrFollowNotFound
+	}
+	return err
+}
+
+// AcceptPendingRequests is called once a private account is made public, which lets
+// everyone waiting in. Requests it fails to accept stay pending, where the user can
+// still accept them one by one, so the error is logged rather than failing the update.
+func (s *followService) AcceptPendingRequests(ctx context.Context, userID uint) {
+	accepted, err := s.followRepo.AcceptPendingFollows(context.WithoutCancel(ctx), userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Uint("userID", userID).Msg("Failed to accept pending follow requests")
+		return
+	}
+	if accepted > 0 {
+		log.Logger.Info().Uint("userID", userID).Int64("accepted", accepted).Msg("Accepted pending follow requests")
+	}
+}
+
+func withFollowListDefaults(query models.ListFollowsQuery) models.ListFollowsQuery {
+	if query.Limit <= 0 {
+		query.Limit = models.DefaultFollowListLimit
+	}
+	return query
+}
+
+func toFollowUserResponses(users []models.FollowUser) []models.FollowUserResponse {
+	responses := make([]models.FollowUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = models.FollowUserResponse{
+			UserID:   user.UserID,
+			Name:     optionalString(user.Name),
+			ImageURI: optionalString(user.ImageURI),
+			Status:   user.Status,
+			Since:    user.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	return responses
+}
+
+// optionalString turns the empty string profiles store for unset fields into nil
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
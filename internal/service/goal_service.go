@@ -0,0 +1,252 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// goalHistoryPeriods is the number of completed periods reported in goal progress history
+const goalHistoryPeriods = 6
+
+type GoalService interface {
+	CreateGoal(ctx context.Context, userID uint, req models.CreateGoalRequest) (*models.GoalResponse, error)
+	GetGoals(ctx context.Context, userID uint) ([]models.GoalResponse, error)
+	GetGoal(ctx context.Context, userID uint, goalID string) (*models.GoalResponse, error)
+	UpdateGoal(ctx context.Context, userID uint, goalID string, req models.UpdateGoalRequest) (*models.GoalResponse, error)
+	DeleteGoal(ctx context.Context, userID uint, goalID string) error
+	GetGoalsProgress(ctx context.Context, userID uint, query models.GetGoalsProgressQuery) ([]models.GoalProgressResponse, error)
+}
+
+type goalService struct {
+	goalRepo     repositories.GoalRepository
+	activityRepo repositories.ActivityRepository
+}
+
+func NewGoalService(goalRepo repositories.GoalRepository, activityRepo repositories.ActivityRepository) GoalService {
+	return &goalService{
+		goalRepo:     goalRepo,
+		activityRepo: activityRepo,
+	}
+}
+
+func (s *goalService) CreateGoal(ctx context.Context, userID uint, req models.CreateGoalRequest) (*models.GoalResponse, error) {
+	goal := models.Goal{
+		GoalID:       uuid.New().String(),
+		UserID:       userID,
+		Metric:       req.Metric,
+		ActivityType: req.ActivityType,
+		Period:       req.Period,
+		Target:       req.Target,
+	}
+
+	err := s.goalRepo.CreateGoal(ctx, goal)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create goal")
+		return nil, err
+	}
+
+	now := time.Now()
+	goal.CreatedAt = now
+	goal.UpdatedAt = now
+	response := toGoalResponse(goal)
+	return &response, nil
+}
+
+func (s *goalService) GetGoals(ctx context.Context, userID uint) ([]models.GoalResponse, error) {
+	goals, err := s.goalRepo.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get goals")
+		return nil, err
+	}
+
+	responses := make([]models.GoalResponse, len(goals))
+	for i, goal := range goals {
+		responses[i] = toGoalResponse(goal)
+	}
+
+	return responses, nil
+}
+
+func (s *goalService) GetGoal(ctx context.Context, userID uint, goalID string) (*models.GoalResponse, error) {
+	goal, err := s.goalRepo.GetGoalByID(ctx, goalID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get goal")
+		return nil, err
+	}
+	if goal == nil {
+		return nil, customErrors.ErrGoalNotFound
+	}
+
+	response := toGoalResponse(*goal)
+	return &response, nil
+}
+
+func (s *goalService) UpdateGoal(ctx context.Context, userID uint, goalID string, req models.UpdateGoalRequest) (*models.GoalResponse, error) {
+	updates := make(map[string]interface{})
+
+	if req.Metric != nil {
+		updates["metric"] = *req.Metric
+	}
+
+	if req.ActivityType != nil {
+		updates["activity_type"] = *req.ActivityType
+	}
+
+	if req.Period != nil {
+		updates["period"] = *req.Period
+	}
+
+	if req.Target != nil {
+		updates["target"] = *req.Target
+	}
+
+	updates["updated_at"] = time.Now()
+
+	err := s.goalRepo.UpdateGoal(ctx, goalID, userID, updates)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customErrors.ErrGoalNotFound
+		}
+		log.Logger.Error().Err(err).Msg("Failed to update goal")
+		return nil, err
+	}
+
+	return s.GetGoal(ctx, userID, goalID)
+}
+
+func (s *goalService) DeleteGoal(ctx context.Context, userID uint, goalID string) error {
+	err := s.goalRepo.DeleteGoal(ctx, goalID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customErrors.ErrGoalNotFound
+		}
+		log.Logger.Error().Err(err).Msg("Failed to delete goal")
+		return err
+	}
+	return nil
+}
+
+func (s *goalService) GetGoalsProgress(ctx context.Context, userID uint, query models.GetGoalsProgressQuery) ([]models.GoalProgressResponse, error) {
+	location := time.UTC
+	if query.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(query.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	goals, err := s.goalRepo.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get goals for progress")
+		return nil, err
+	}
+
+	now := time.Now().In(location)
+	responses := make([]models.GoalProgressResponse, len(goals))
+	for i, goal := range goals {
+		periodStart := goalPeriodStart(goal.Period, now)
+		historyStart := goalPeriodAdd(goal.Period, periodStart, -goalHistoryPeriods)
+
+		// One query covers the current period and all of the history
+		totals, err := s.activityRepo.GetActivityTotalsByPeriod(ctx, userID, historyStart, goalPeriodAdd(goal.Period, periodStart, 1), goal.ActivityType, goal.Period, location.String())
+		if err != nil {
+			log.Logger.Error().Err(err).Str("goalId", goal.GoalID).Msg("Failed to get activity totals for goal")
+			return nil, err
+		}
+		totalsByPeriod := make(map[string]models.ActivityPeriodTotals, len(totals))
+		for _, t := range totals {
+			totalsByPeriod[t.PeriodStart] = t
+		}
+
+		current := periodProgress(goal, periodStart, totalsByPeriod)
+
+		// Walk back through previous periods, most recent first
+		history := make([]models.GoalPeriodProgress, 0, goalHistoryPeriods)
+		for p := 0; p < goalHistoryPeriods; p++ {
+			periodStart = goalPeriodAdd(goal.Period, periodStart, -1)
+			history = append(history, periodProgress(goal, periodStart, totalsByPeriod))
+		}
+
+		responses[i] = models.GoalProgressResponse{
+			GoalResponse: toGoalResponse(goal),
+			Current:      current,
+			History:      history,
+		}
+	}
+
+	return responses, nil
+}
+
+// periodProgress measures the goal against the totals of the period starting at
+// periodStart, which are zero when nothing was done in it
+func periodProgress(goal models.Goal, periodStart time.Time, totalsByPeriod map[string]models.ActivityPeriodTotals) models.GoalPeriodProgress {
+	periodEnd := goalPeriodAdd(goal.Period, periodStart, 1)
+	totals := totalsByPeriod[periodStart.Format(models.DateLayout)]
+
+	var value int
+	switch goal.Metric {
+	case models.GoalMetricActiveMinutes:
+		value = totals.ActiveMinutes
+	case models.GoalMetricCalories:
+		value = totals.CaloriesBurned
+	case models.GoalMetricSessions:
+		value = totals.Sessions
+	}
+
+	percent := float64(value) / float64(goal.Target) * 100
+	if percent > 100 {
+		percent = 100
+	}
+
+	return models.GoalPeriodProgress{
+		PeriodStart: periodStart.Format(time.RFC3339),
+		PeriodEnd:   periodEnd.Format(time.RFC3339),
+		Value:       value,
+		Percent:     percent,
+		Completed:   value >= goal.Target,
+	}
+}
+
+// goalPeriodStart returns the start of the week (Monday) or month containing t
+func goalPeriodStart(period string, t time.Time) time.Time {
+	year, month, day := t.Date()
+	if period == models.GoalPeriodMonthly {
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+
+	weekday := (int(t.Weekday()) + 6) % 7
+	return time.Date(year, month, day-weekday, 0, 0, 0, 0, t.Location())
+}
+
+// goalPeriodAdd moves a period start forward or backward by n periods
+func goalPeriodAdd(period string, start time.Time, n int) time.Time {
+	if period == models.GoalPeriodMonthly {
+		return start.AddDate(0, n, 0)
+	}
+	return start.AddDate(0, 0, 7*n)
+}
+
+func toGoalResponse(goal models.Goal) models.GoalResponse {
+	var activityType *string
+	if goal.ActivityType != "" {
+		activityType = &goal.ActivityType
+	}
+
+	return models.GoalResponse{
+		GoalID:       goal.GoalID,
+		Metric:       goal.Metric,
+		ActivityType: activityType,
+		Period:       goal.Period,
+		Target:       goal.Target,
+		CreatedAt:    goal.CreatedAt,
+		UpdatedAt:    goal.UpdatedAt,
+	}
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/heartrate"
+	"FitByte/pkg/log"
+	"FitByte/pkg/workout"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HeartRateService interface {
+	SetHeartRate(ctx context.Context, userID uint, activityID string, req models.SetHeartRateRequest) (*models.HeartRateSeriesResponse, error)
+	GetHeartRate(ctx context.Context, userID uint, activityID string, query models.GetHeartRateQuery) (*models.HeartRateSeriesResponse, error)
+	DeleteHeartRate(ctx context.Context, userID uint, activityID string) error
+	GetActivityZones(ctx context.Context, userID uint, activityID string) (*models.ActivityHeartRateZonesResponse, error)
+	GetWeeklyZones(ctx context.Context, userID uint, query models.GetWeeklyHeartRateZonesQuery) (*models.WeeklyHeartRateZonesResponse, error)
+}
+
+type heartRateService struct {
+	activityRepo    repositories.ActivityRepository
+	heartRateRepo   repositories.ActivityHeartRateRepository
+	profileRepo     repositories.ProfileRepository
+	transactor      repositories.Transactor
+	trainingLoadSvc TrainingLoadService
+}
+
+func NewHeartRateService(activityRepo repositories.ActivityRepository, heartRateRepo repositories.ActivityHeartRateRepository, profileRepo repositories.ProfileRepository, transactor repositories.Transactor, trainingLoadService TrainingLoadService) HeartRateService {
+	return &heartRateService{
+		activityRepo:    activityRepo,
+		heartRateRepo:   heartRateRepo,
+		profileRepo:     profileRepo,
+		transactor:      transactor,
+		trainingLoadSvc: trainingLoadService,
+	}
+}
+
+// SetHeartRate stores readings taken at a fixed interval as the activity's heart rate,
+// replacing any recorded before
+func (s *heartRateService) SetHeartRate(ctx context.Context, userID uint, activityID string, req models.SetHeartRateRequest) (*models.HeartRateSeriesResponse, error) {
+	activity, err := s.getActivity(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := activity.DoneAt
+	if req.StartTime != nil {
+		start, err = time.Parse(time.RFC3339, *req.StartTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	interval := time.Duration(req.IntervalSeconds) * time.Second
+	samples := make([]heartrate.Sample, 0, len(req.Samples))
+	for i, bpm := range req.Samples {
+		samples = append(samples, heartrate.Sample{Time: start.Add(time.Duration(i) * interval), BPM: bpm})
+	}
+
+	samples = heartrate.Normalize(samples)
+	heartRate := newActivityHeartRate(*activity, samples)
+	if heartRate == nil {
+		return nil, customErrors.ErrTooFewHeartRateSamples
+	}
+
+	// The heart rate weighs the activity's training load
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.heartRateRepo.SaveHeartRate(ctx, heartRate); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, *activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toHeartRateSeriesResponse(*heartRate, samples, models.DefaultHeartRatePoints), nil
+}
+
+// GetHeartRate returns the activity's heart rate downsampled to query.Points points
+func (s *heartRateService) GetHeartRate(ctx context.Context, userID uint, activityID string, query models.GetHeartRateQuery) (*models.HeartRateSeriesResponse, error) {
+	heartRate, samples, err := s.getSamples(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	points := query.Points
+	if points == 0 {
+		points = models.DefaultHeartRatePoints
+	}
+	return toHeartRateSeriesResponse(*heartRate, samples, points), nil
+}
+
+func (s *heartRateService) DeleteHeartRate(ctx context.Context, userID uint, activityID string) error {
+	activity, err := s.getActivity(ctx, userID, activityID)
+	if err != nil {
+		return err
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.heartRateRepo.DeleteHeartRate(ctx, activityID, userID); err != nil {
+			return err
+		}
+		return markLoadStale(ctx, s.trainingLoadSvc, userID, *activity)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return customErrors.ErrHeartRateNotFound
+	}
+	return err
+}
+
+// GetActivityZones works out how long the activity spent in each heart rate zone
+func (s *heartRateService) GetActivityZones(ctx context.Context, userID uint, activityID string) (*models.ActivityHeartRateZonesResponse, error) {
+	maxHR, source, err := s.maxHeartRate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, samples, err := s.getSamples(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := heartrate.Zones(maxHR)
+	return &models.ActivityHeartRateZonesResponse{
+		ActivityID:                activityID,
+		MaxHeartRate:              maxHR,
+		MaxHeartRateSource:        source,
+		HeartRateZoneDistribution: toZoneDistribution(zones, heartrate.TimeInZones(samples, zones)),
+	}, nil
+}
+
+// GetWeeklyZones sums the time in each heart rate zone of the activities done in every
+// week from query.From to query.To
+func (s *heartRateService) GetWeeklyZones(ctx context.Context, userID uint, query models.GetWeeklyHeartRateZonesQuery) (*models.WeeklyHeartRateZonesResponse, error) {
+	from, err := time.Parse(models.DateLayout, query.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse(models.DateLayout, query.To)
+	if err != nil {
+		return nil, err
+	}
+	location := time.UTC
+	if query.TimeZone != "" {
+		location, err = time.LoadLocation(query.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxHR, source, err := s.maxHeartRate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeStart := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	rangeEnd := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, location)
+
+	records, err := s.heartRateRepo.GetHeartRatesInRange(ctx, userID, rangeStart, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	type week struct {
+		start        time.Time
+		activities   int
+		distribution heartrate.Distribution
+	}
+	var weeks []week
+	index := make(map[string]int)
+	for start := goalPeriodStart(models.GoalPeriodWeekly, rangeStart); start.Before(rangeEnd); start = goalPeriodAdd(models.GoalPeriodWeekly, start, 1) {
+		index[start.Format(models.DateLayout)] = len(weeks)
+		weeks = append(weeks, week{start: start})
+	}
+
+	zones := heartrate.Zones(maxHR)
+	for _, record := range records {
+		samples, err := heartrate.Decode(record.Samples)
+		if err != nil {
+			log.Logger.Error().Err(err).Str("activityId", record.ActivityID).Msg("Failed to decode activity heart rate")
+			return nil, err
+		}
+
+		i, ok := index[goalPeriodStart(models.GoalPeriodWeekly, record.DoneAt.In(location)).Format(models.DateLayout)]
+		if !ok {
+			continue
+		}
+		weeks[i].activities++
+		weeks[i].distribution.Add(heartrate.TimeInZones(samples, zones))
+	}
+
+	response := &models.WeeklyHeartRateZonesResponse{
+		MaxHeartRate:       maxHR,
+		MaxHeartRateSource: source,
+		Weeks:              make([]models.HeartRateZoneWeek, len(weeks)),
+	}
+	for i, w := range weeks {
+		response.Weeks[i] = models.HeartRateZoneWeek{
+			WeekStart:                 w.start.Format(models.DateLayout),
+			Activities:                w.activities,
+			HeartRateZoneDistribution: toZoneDistribution(zones, w.distribution),
+		}
+	}
+	return response, nil
+}
+
+func (s *heartRateService) getActivity(ctx context.Context, userID uint, activityID string) (*models.Activity, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activity for heart rate")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+	return activity, nil
+}
+
+// getSamples returns the stored heart rate of an activity with its decoded readings
+func (s *heartRateService) getSamples(ctx context.Context, userID uint, activityID string) (*models.ActivityHeartRate, []heartrate.Sample, error) {
+	if _, err := s.getActivity(ctx, userID, activityID); err != nil {
+		return nil, nil, err
+	}
+
+	heartRate, err := s.heartRateRepo.GetHeartRateByActivityID(ctx, activityID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if heartRate == nil {
+		return nil, nil, customErrors.ErrHeartRateNotFound
+	}
+
+	samples, err := heartrate.Decode(heartRate.Samples)
+	if err != nil {
+		log.Logger.Error().Err(err).Str("activityId", activityID).Msg("Failed to decode activity heart rate")
+		return nil, nil, err
+	}
+	return heartRate, samples, nil
+}
+
+// maxHeartRate returns the user's maximum heart rate and where it came from
+func (s *heartRateService) maxHeartRate(ctx context.Context, userID uint) (int, string, error) {
+	profile, err := s.profileRepo.GetProfileByID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get profile for heart rate zones")
+		return 0, "", err
+	}
+	if profile == nil {
+		return 0, "", customErrors.ErrorUserNotFound
+	}
+
+	maxHR, source, ok := profileMaxHeartRate(profile, time.Now())
+	if !ok {
+		return 0, "", customErrors.ErrMaxHeartRateUnknown
+	}
+	return maxHR, source, nil
+}
+
+// profileMaxHeartRate returns the maximum heart rate set in the profile or, failing that,
+// the one estimated from the age at now. It reports false when neither is known.
+func profileMaxHeartRate(profile *models.Profile, now time.Time) (int, string, bool) {
+	if profile.MaxHeartRate != nil {
+		return *profile.MaxHeartRate, models.MaxHeartRateSourceProfile, true
+	}
+	if profile.BirthDate != nil {
+		age := heartrate.Age(*profile.BirthDate, now)
+		return heartrate.EstimateMaxHR(age), models.MaxHeartRateSourceAge, true
+	}
+	return 0, "", false
+}
+
+// newActivityHeartRate builds the stored heart rate for an activity from raw readings, or
+// returns nil when fewer than two usable readings remain
+func newActivityHeartRate(activity models.Activity, samples []heartrate.Sample) *models.ActivityHeartRate {
+	samples = heartrate.Normalize(samples)
+	if len(samples) < 2 {
+		return nil
+	}
+
+	minBPM, avgBPM, maxBPM, _ := heartrate.Stats(samples)
+	return &models.ActivityHeartRate{
+		ActivityID:  activity.ActivityID,
+		UserID:      activity.UserID,
+		SampleCount: len(samples),
+		Samples:     heartrate.Encode(samples),
+		StartTime:   samples[0].Time,
+		EndTime:     samples[len(samples)-1].Time,
+		MinBPM:      minBPM,
+		AvgBPM:      avgBPM,
+		MaxBPM:      maxBPM,
+	}
+}
+
+// workoutHeartRate returns the heart rate readings of a workout file's trackpoints
+func workoutHeartRate(points []workout.Point) []heartrate.Sample {
+	samples := make([]heartrate.Sample, 0, len(points))
+	for _, p := range points {
+		if p.HeartRate > 0 && !p.Time.IsZero() {
+			samples = append(samples, heartrate.Sample{Time: p.Time, BPM: p.HeartRate})
+		}
+	}
+	return samples
+}
+
+func toHeartRateSeriesResponse(heartRate models.ActivityHeartRate, samples []heartrate.Sample, points int) *models.HeartRateSeriesResponse {
+	response := &models.HeartRateSeriesResponse{
+		ActivityID:  heartRate.ActivityID,
+		StartTime:   heartRate.StartTime,
+		EndTime:     heartRate.EndTime,
+		SampleCount: heartRate.SampleCount,
+		MinBPM:      heartRate.MinBPM,
+		AvgBPM:      heartRate.AvgBPM,
+		MaxBPM:      heartRate.MaxBPM,
+	}
+
+	downsampled := heartrate.Downsample(samples, points)
+	response.Points = make([]models.HeartRatePointResponse, len(downsampled))
+	for i, p := range downsampled {
+		response.Points[i] = models.HeartRatePointResponse{
+			OffsetSeconds: int(p.Time.Sub(heartRate.StartTime).Seconds()),
+			BPM:           p.BPM,
+		}
+	}
+	return response
+}
+
+// toZoneDistribution reports a distribution in whole seconds with each zone's share of
+// the recorded time
+func toZoneDistribution(zones [heartrate.ZoneCount]heartrate.Zone, distribution heartrate.Distribution) models.HeartRateZoneDistribution {
+	total := distribution.Total()
+	response := models.HeartRateZoneDistribution{
+		TotalSeconds:      int(total.Seconds()),
+		BelowZonesSeconds: int(distribution.Below.Seconds()),
+		Zones:             make([]models.HeartRateZoneResponse, len(zones)),
+	}
+	for i, zone := range zones {
+		var percent float64
+		if total > 0 {
+			percent = roundTo(float64(distribution.Zones[i])/float64(total)*100, 1)
+		}
+		response.Zones[i] = models.HeartRateZoneResponse{
+			Zone:    zone.Number,
+			MinBPM:  zone.MinBPM,
+			MaxBPM:  zone.MaxBPM,
+			Seconds: int(distribution.Zones[i].Seconds()),
+			Percent: percent,
+		}
+	}
+	return response
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"time"
+)
+
+const (
+	// defaultIdempotencyTTL applies when no TTL is configured
+	defaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyLockTimeout is how long a key may stay in progress before it is treated
+	// as abandoned, for instance because the server restarted mid-request
+	idempotencyLockTimeout = 10 * time.Minute
+)
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, userID uint, key string, requestHash string) (*models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, id uint, status int, contentType string, body []byte) error
+	Release(ctx context.Context, id uint) error
+	StartExpiryPurge(interval time.Duration)
+}
+
+type idempotencyService struct {
+	idempotencyRepo repositories.IdempotencyRepository
+	ttl             time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repositories.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &idempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+	}
+}
+
+// Begin claims the key for a new request. When the key was already used for the same
+// request and that request has finished, the stored key is returned with replay set so
+// its response can be sent again.
+func (s *idempotencyService) Begin(ctx context.Context, userID uint, key string, requestHash string) (*models.IdempotencyKey, bool, error) {
+	// A second attempt is needed when an expired or abandoned key was removed
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		claim := &models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.ttl),
+		}
+
+		created, err := s.idempotencyRepo.CreateIdempotencyKey(ctx, claim)
+		if err != nil {
+			return nil, false, err
+		}
+		if created {
+			return claim, false, nil
+		}
+
+		existing, err := s.idempotencyRepo.GetIdempotencyKey(ctx, userID, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing == nil {
+			// Removed between the insert and the lookup; try to claim it again
+			continue
+		}
+
+		abandoned := existing.ResponseStatus == 0 && now.Sub(existing.CreatedAt) > idempotencyLockTimeout
+		if !existing.ExpiresAt.After(now) || abandoned {
+			if err := s.idempotencyRepo.DeleteIdempotencyKey(ctx, existing.ID); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, false, customErrors.ErrIdempotencyKeyReused
+		}
+		if existing.ResponseStatus == 0 {
+			return nil, false, customErrors.ErrIdempotencyKeyInProgress
+		}
+		return existing, true, nil
+	}
+
+	return nil, false, customErrors.ErrIdempotencyKeyInProgress
+}
+
+// Complete stores the response of the request that claimed the key
+func (s *idempotencyService) Complete(ctx context.Context, id uint, status int, contentType string, body []byte) error {
+	return s.idempotencyRepo.UpdateIdempotencyKey(ctx, id, map[string]interface{}{
+		"response_status":       status,
+		"response_content_type": contentType,
+		"response_body":         body,
+	})
+}
+
+// Release gives up a claimed key so that the request can be retried, used when it failed
+// in a way the client should not be stuck with
+func (s *idempotencyService) Release(ctx context.Context, id uint) error {
+	return s.idempotencyRepo.DeleteIdempotencyKey(ctx, id)
+}
+
+// StartExpiryPurge removes expired keys in the background every interval
+func (s *idempotencyService) StartExpiryPurge(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, err := s.idempotencyRepo.DeleteExpiredIdempotencyKeys(context.Background(), time.Now())
+			if err != nil {
+				log.Logger.Error().Err(err).Msg("Failed to purge expired idempotency keys")
+				continue
+			}
+			if purged > 0 {
+				log.Logger.Info().Int64("purged", purged).Msg("Purged expired idempotency keys")
+			}
+		}
+	}()
+}
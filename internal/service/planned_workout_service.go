@@ -0,0 +1,375 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"FitByte/pkg/rrule"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlannedWorkoutService interface {
+	CreatePlannedWorkout(ctx context.Context, userID uint, req models.CreatePlannedWorkoutRequest) (*models.PlannedWorkoutResponse, error)
+	GetPlannedWorkouts(ctx context.Context, userID uint) ([]models.PlannedWorkoutResponse, error)
+	GetPlannedWorkout(ctx context.Context, userID uint, plannedWorkoutID string) (*models.PlannedWorkoutResponse, error)
+	UpdatePlannedWorkout(ctx context.Context, userID uint, plannedWorkoutID string, req models.UpdatePlannedWorkoutRequest) (*models.PlannedWorkoutResponse, error)
+	DeletePlannedWorkout(ctx context.Context, userID uint, plannedWorkoutID string) error
+	GetCalendar(ctx context.Context, userID uint, query models.GetCalendarQuery) ([]models.CalendarDay, error)
+	CompleteMatchingSlot(ctx context.Context, userID uint, activity models.Activity) error
+}
+
+type plannedWorkoutService struct {
+	plannedWorkoutRepo repositories.PlannedWorkoutRepository
+	activityRepo       repositories.ActivityRepository
+	profileRepo        repositories.ProfileRepository
+}
+
+func NewPlannedWorkoutService(plannedWorkoutRepo repositories.PlannedWorkoutRepository, activityRepo repositories.ActivityRepository, profileRepo repositories.ProfileRepository) PlannedWorkoutService {
+	return &plannedWorkoutService{
+		plannedWorkoutRepo: plannedWorkoutRepo,
+		activityRepo:       activityRepo,
+		profileRepo:        profileRepo,
+	}
+}
+
+func (s *plannedWorkoutService) CreatePlannedWorkout(ctx context.Context, userID uint, req models.CreatePlannedWorkoutRequest) (*models.PlannedWorkoutResponse, error) {
+	scheduledDate, err := time.Parse(models.DateLayout, req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	recurrence, lastDate, err := plannedWorkoutRecurrence(scheduledDate, req.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	targetCalories := models.ActivityTypeCalories[req.ActivityType] * req.TargetDurationInMinutes
+	if req.TargetCalories != nil {
+		targetCalories = *req.TargetCalories
+	}
+
+	workout := models.PlannedWorkout{
+		PlannedWorkoutID:        uuid.New().String(),
+		UserID:                  userID,
+		ActivityType:            req.ActivityType,
+		ScheduledDate:           scheduledDate,
+		TargetDurationInMinutes: req.TargetDurationInMinutes,
+		TargetCalories:          targetCalories,
+		Recurrence:              recurrence,
+		LastDate:                lastDate,
+	}
+
+	err = s.plannedWorkoutRepo.CreatePlannedWorkout(ctx, workout)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create planned workout")
+		return nil, err
+	}
+
+	now := time.Now()
+	workout.CreatedAt = now
+	workout.UpdatedAt = now
+	response := toPlannedWorkoutResponse(workout)
+	return &response, nil
+}
+
+// plannedWorkoutRecurrence validates a recurrence rule for a workout starting on
+// scheduledDate and works out its last occurrence. A nil or blank rule means the
+// workout happens once, on scheduledDate.
+func plannedWorkoutRecurrence(scheduledDate time.Time, recurrence *string) (*string, *time.Time, error) {
+	if recurrence == nil || strings.TrimSpace(*recurrence) == "" {
+		return nil, &scheduledDate, nil
+	}
+
+	normalized := strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(*recurrence), "RRULE:"))
+	rule, err := rrule.Parse(normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !rule.Bounded() {
+		return &normalized, nil, nil
+	}
+	lastDate, ok := rule.Last(scheduledDate)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: it has no occurrences on or after the date", rrule.ErrInvalidRule)
+	}
+	return &normalized, &lastDate, nil
+}
+
+func (s *plannedWorkoutService) GetPlannedWorkouts(ctx context.Context, userID uint) ([]models.PlannedWorkoutResponse, error) {
+	workouts, err := s.plannedWorkoutRepo.GetPlannedWorkoutsByUserID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workouts")
+		return nil, err
+	}
+
+	responses := make([]models.PlannedWorkoutResponse, len(workouts))
+	for i, workout := range workouts {
+		responses[i] = toPlannedWorkoutResponse(workout)
+	}
+
+	return responses, nil
+}
+
+func (s *plannedWorkoutService) GetPlannedWorkout(ctx context.Context, userID uint, plannedWorkoutID string) (*models.PlannedWorkoutResponse, error) {
+	workout, err := s.plannedWorkoutRepo.GetPlannedWorkoutByID(ctx, plannedWorkoutID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workout")
+		return nil, err
+	}
+	if workout == nil {
+		return nil, customErrors.ErrPlannedWorkoutNotFound
+	}
+
+	response := toPlannedWorkoutResponse(*workout)
+	return &response, nil
+}
+
+func (s *plannedWorkoutService) UpdatePlannedWorkout(ctx context.Context, userID uint, plannedWorkoutID string, req models.UpdatePlannedWorkoutRequest) (*models.PlannedWorkoutResponse, error) {
+	existing, err := s.plannedWorkoutRepo.GetPlannedWorkoutByID(ctx, plannedWorkoutID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workout for update")
+		return nil, err
+	}
+	if existing == nil {
+		return nil, customErrors.ErrPlannedWorkoutNotFound
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.ActivityType != nil {
+		updates["activity_type"] = *req.ActivityType
+	}
+
+	if req.TargetDurationInMinutes != nil {
+		updates["target_duration_in_minutes"] = *req.TargetDurationInMinutes
+	}
+
+	if req.TargetCalories != nil {
+		updates["target_calories"] = *req.TargetCalories
+	}
+
+	// The last occurrence depends on both the date and the rule, so it is worked out
+	// again whenever either of them changes
+	if req.Date != nil || req.Recurrence != nil {
+		scheduledDate := existing.ScheduledDate
+		if req.Date != nil {
+			scheduledDate, err = time.Parse(models.DateLayout, *req.Date)
+			if err != nil {
+				return nil, err
+			}
+		}
+		recurrence := existing.Recurrence
+		if req.Recurrence != nil {
+			recurrence = req.Recurrence
+		}
+
+		recurrence, lastDate, err := plannedWorkoutRecurrence(scheduledDate, recurrence)
+		if err != nil {
+			return nil, err
+		}
+		updates["scheduled_date"] = scheduledDate
+		updates["recurrence"] = recurrence
+		updates["last_date"] = lastDate
+	}
+
+	updates["updated_at"] = time.Now()
+
+	err = s.plannedWorkoutRepo.UpdatePlannedWorkout(ctx, plannedWorkoutID, userID, updates)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customErrors.ErrPlannedWorkoutNotFound
+		}
+		log.Logger.Error().Err(err).Msg("Failed to update planned workout")
+		return nil, err
+	}
+
+	return s.GetPlannedWorkout(ctx, userID, plannedWorkoutID)
+}
+
+func (s *plannedWorkoutService) DeletePlannedWorkout(ctx context.Context, userID uint, plannedWorkoutID string) error {
+	err := s.plannedWorkoutRepo.DeletePlannedWorkout(ctx, plannedWorkoutID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customErrors.ErrPlannedWorkoutNotFound
+		}
+		log.Logger.Error().Err(err).Msg("Failed to delete planned workout")
+		return err
+	}
+	return nil
+}
+
+// GetCalendar lists every day from query.From to query.To with the planned sessions
+// falling on it and the activities done that day in the requested time zone
+func (s *plannedWorkoutService) GetCalendar(ctx context.Context, userID uint, query models.GetCalendarQuery) ([]models.CalendarDay, error) {
+	from, err := time.Parse(models.DateLayout, query.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse(models.DateLayout, query.To)
+	if err != nil {
+		return nil, err
+	}
+	location := time.UTC
+	if query.TimeZone != "" {
+		location, err = time.LoadLocation(query.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	workouts, err := s.plannedWorkoutRepo.GetPlannedWorkoutsInRange(ctx, userID, from, to, "")
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workouts for calendar")
+		return nil, err
+	}
+
+	completions, err := s.plannedWorkoutRepo.GetCompletionsInRange(ctx, userID, from, to)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get planned workout completions for calendar")
+		return nil, err
+	}
+	completedBy := make(map[string]string, len(completions))
+	for _, completion := range completions {
+		completedBy[occurrenceKey(completion.PlannedWorkoutID, completion.OccurrenceDate)] = completion.ActivityID
+	}
+
+	// Activities are placed on the day they were done in the caller's time zone
+	activityQuery := models.GetActivitiesQuery{
+		DoneAtFrom: time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location),
+		DoneAtTo:   time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, location).Add(-time.Nanosecond),
+		Sort:       "doneAt",
+	}
+	activities, err := s.activityRepo.GetActivitiesByUserID(ctx, userID, activityQuery)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get activities for calendar")
+		return nil, err
+	}
+
+	units, err := unitSystemForUser(ctx, s.profileRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]models.CalendarDay, 0, int(to.Sub(from).Hours()/24)+1)
+	dayIndex := make(map[string]int)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		key := date.Format(models.DateLayout)
+		dayIndex[key] = len(days)
+		days = append(days, models.CalendarDay{
+			Date:      key,
+			Planned:   []models.PlannedSession{},
+			Completed: []models.ActivityResponse{},
+		})
+	}
+
+	for _, workout := range workouts {
+		for _, date := range plannedOccurrences(workout, from, to) {
+			session := models.PlannedSession{
+				PlannedWorkoutID:        workout.PlannedWorkoutID,
+				ActivityType:            workout.ActivityType,
+				TargetDurationInMinutes: workout.TargetDurationInMinutes,
+				TargetCalories:          workout.TargetCalories,
+				Recurring:               workout.Recurrence != nil,
+			}
+			if activityID, ok := completedBy[occurrenceKey(workout.PlannedWorkoutID, date)]; ok {
+				session.Completed = true
+				session.ActivityID = &activityID
+			}
+
+			day := &days[dayIndex[date.Format(models.DateLayout)]]
+			day.Planned = append(day.Planned, session)
+		}
+	}
+
+	for _, activity := range activities {
+		i, ok := dayIndex[activity.DoneAt.In(location).Format(models.DateLayout)]
+		if !ok {
+			continue
+		}
+		days[i].Completed = append(days[i].Completed, toActivityResponse(activity, units))
+	}
+
+	return days, nil
+}
+
+// CompleteMatchingSlot marks the first open occurrence planned for the activity's type
+// on the day it was done as completed by it. It is called in the transaction that saves
+// the activity. The day is taken in UTC, since DoneAt carries the client's offset when
+// the activity is created but UTC once it is read back.
+func (s *plannedWorkoutService) CompleteMatchingSlot(ctx context.Context, userID uint, activity models.Activity) error {
+	date := rrule.Date(activity.DoneAt.UTC())
+
+	workouts, err := s.plannedWorkoutRepo.GetPlannedWorkoutsInRange(ctx, userID, date, date, activity.ActivityType)
+	if err != nil {
+		return err
+	}
+
+	for _, workout := range workouts {
+		if len(plannedOccurrences(workout, date, date)) == 0 {
+			continue
+		}
+
+		completed, err := s.plannedWorkoutRepo.CreateCompletion(ctx, &models.PlannedWorkoutCompletion{
+			PlannedWorkoutID: workout.PlannedWorkoutID,
+			UserID:           userID,
+			OccurrenceDate:   date,
+			ActivityID:       activity.ActivityID,
+			CreatedAt:        time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if completed {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// plannedOccurrences returns the dates from from to to on which the workout is planned
+func plannedOccurrences(workout models.PlannedWorkout, from, to time.Time) []time.Time {
+	start := rrule.Date(workout.ScheduledDate)
+
+	if workout.Recurrence == nil {
+		if start.Before(from) || start.After(to) {
+			return nil
+		}
+		return []time.Time{start}
+	}
+
+	rule, err := rrule.Parse(*workout.Recurrence)
+	if err != nil {
+		// Rules are validated when saved, so this only happens to rows edited by hand
+		log.Logger.Error().Err(err).Str("plannedWorkoutId", workout.PlannedWorkoutID).Msg("Failed to parse stored recurrence rule")
+		return nil
+	}
+	return rule.Between(start, from, to)
+}
+
+func occurrenceKey(plannedWorkoutID string, date time.Time) string {
+	return plannedWorkoutID + "/" + date.Format(models.DateLayout)
+}
+
+func toPlannedWorkoutResponse(workout models.PlannedWorkout) models.PlannedWorkoutResponse {
+	return models.PlannedWorkoutResponse{
+		PlannedWorkoutID:        workout.PlannedWorkoutID,
+		ActivityType:            workout.ActivityType,
+		Date:                    workout.ScheduledDate.Format(models.DateLayout),
+		TargetDurationInMinutes: workout.TargetDurationInMinutes,
+		TargetCalories:          workout.TargetCalories,
+		Recurrence:              workout.Recurrence,
+		Notes:                   workout.Notes,
+		EnrollmentID:            workout.EnrollmentID,
+		CreatedAt:               workout.CreatedAt,
+		UpdatedAt:               workout.UpdatedAt,
+	}
+}
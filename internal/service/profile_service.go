@@ -8,27 +8,36 @@ import (
 	"FitByte/pkg/log"
 	"FitByte/pkg/token"
 	"context"
+	"errors"
 	"strconv"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type ProfileService interface {
 	Register(ctx context.Context, authRequest models.AuthRequest) (models.RegisterResponse, error)
 	Login(ctx context.Context, authRequest models.AuthRequest) (string, error)
-	UpdateUserProfile(ctx context.Context, userID uint, updates map[string]interface{}) error
+	UpdateUserProfile(ctx context.Context, userID uint, updates map[string]interface{}, expectedVersion int) (*models.Profile, error)
 	GetProfile(ctx context.Context, userID uint) (*models.Profile, error)
 }
 
 type profileService struct {
-	appConfig   configs.Config
-	profileRepo repositories.ProfileRepository
+	appConfig       configs.Config
+	profileRepo     repositories.ProfileRepository
+	transactor      repositories.Transactor
+	trainingLoadSvc TrainingLoadService
+	followSvc       FollowService
 }
 
-func NewProfileService(appConfig configs.Config, profileRepo repositories.ProfileRepository) ProfileService {
+func NewProfileService(appConfig configs.Config, profileRepo repositories.ProfileRepository, transactor repositories.Transactor, trainingLoadService TrainingLoadService, followService FollowService) ProfileService {
 	return &profileService{
-		appConfig:   appConfig,
-		profileRepo: profileRepo,
+		appConfig:       appConfig,
+		profileRepo:     profileRepo,
+		transactor:      transactor,
+		trainingLoadSvc: trainingLoadService,
+		followSvc:       followService,
 	}
 }
 
@@ -100,22 +109,71 @@ func (u *profileService) Login(ctx context.Context, authRequest models.AuthReque
 	return signedToken, nil
 }
 
-func (u *profileService) UpdateUserProfile(ctx context.Context, userID uint, updates map[string]interface{}) error {
+// UpdateUserProfile applies the updates and returns the updated profile. A non-zero
+// expectedVersion makes the update conditional on the profile still being at that version.
+func (u *profileService) UpdateUserProfile(ctx context.Context, userID uint, updates map[string]interface{}, expectedVersion int) (*models.Profile, error) {
 	userProfile, err := u.profileRepo.GetProfileByID(ctx, userID)
 	if userProfile == nil {
 		log.Logger.Warn().Str("userID", strconv.FormatUint(uint64(userID), 10)).Msg("user not found")
-		return customErrors.ErrorUserNotFound
+		return nil, customErrors.ErrorUserNotFound
+	}
+	if expectedVersion > 0 && userProfile.Version != expectedVersion {
+		log.Logger.Warn().Str("userID", strconv.FormatUint(uint64(userID), 10)).Int("version", userProfile.Version).Int("expectedVersion", expectedVersion).Msg("profile version mismatch")
+		return nil, customErrors.ErrVersionMismatch
 	}
 
-	err = u.profileRepo.UpdateUser(ctx, userID, updates)
+	var updatedProfile *models.Profile
+	err = u.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if err = u.profileRepo.UpdateUser(ctx, userID, expectedVersion, updates); err != nil {
+			return err
+		}
+
+		updatedProfile, err = u.profileRepo.GetProfileByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if updatedProfile == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		// The heart rate settings weigh the load of every activity with a heart rate
+		if sameHeartRateSettings(userProfile, updatedProfile) {
+			return nil
+		}
+		return u.trainingLoadSvc.MarkStale(ctx, userID, time.Time{})
+	})
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The profile existed a moment ago, so a conditional update lost a race
+			if expectedVersion > 0 {
+				return nil, customErrors.ErrVersionMismatch
+			}
+			return nil, customErrors.ErrorUserNotFound
+		}
 		log.Logger.Error().Err(err).Msg("error occurred on UpdateUserProfile(ctx context.Context, email string, updates map[string]interface{})")
-		return err
+		return nil, err
+	}
+
+	// Nobody needs to be let in to a public account, so waiting requests are accepted
+	if userProfile.IsPrivate && !updatedProfile.IsPrivate {
+		u.followSvc.AcceptPendingRequests(ctx, userID)
 	}
 
-	return nil
+	return updatedProfile, nil
 }
 
 func (u *profileService) GetProfile(ctx context.Context, userID uint) (*models.Profile, error) {
 	return u.profileRepo.GetProfileByID(ctx, userID)
-}
\ No newline at end of file
+}
+
+func sameHeartRateSettings(a, b *models.Profile) bool {
+	return equalIntPtr(a.MaxHeartRate, b.MaxHeartRate) &&
+		equalIntPtr(a.RestingHeartRate, b.RestingHeartRate) &&
+		(a.BirthDate == nil) == (b.BirthDate == nil) &&
+		(a.BirthDate == nil || a.BirthDate.Equal(*b.BirthDate))
+}
+
+func equalIntPtr(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"FitByte/pkg/strength"
+	"context"
+	"fmt"
+)
+
+type StrengthService interface {
+	GetExercises() []strength.Exercise
+	GetSession(ctx context.Context, userID uint, activityID string) (*models.StrengthSessionResponse, error)
+	SetSessionExercises(ctx context.Context, userID uint, activityID string, req models.SetStrengthExercisesRequest) (*models.StrengthSessionResponse, error)
+	GetExerciseHistory(ctx context.Context, userID uint, exerciseID string, query models.GetExerciseHistoryQuery) (*models.ExerciseHistoryResponse, error)
+}
+
+type strengthService struct {
+	catalog      *strength.Catalog
+	strengthRepo repositories.StrengthRepository
+	activityRepo repositories.ActivityRepository
+}
+
+func NewStrengthService(catalog *strength.Catalog, strengthRepo repositories.StrengthRepository, activityRepo repositories.ActivityRepository) StrengthService {
+	return &strengthService{
+		catalog:      catalog,
+		strengthRepo: strengthRepo,
+		activityRepo: activityRepo,
+	}
+}
+
+func (s *strengthService) GetExercises() []strength.Exercise {
+	return s.catalog.All()
+}
+
+// GetSession returns the exercises logged for a strength session
+func (s *strengthService) GetSession(ctx context.Context, userID uint, activityID string) (*models.StrengthSessionResponse, error) {
+	if _, err := s.getStrengthActivity(ctx, userID, activityID); err != nil {
+		return nil, err
+	}
+
+	sets, err := s.strengthRepo.GetSets(ctx, activityID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.toSessionResponse(activityID, sets)
+	return &response, nil
+}
+
+// SetSessionExercises replaces the exercises logged for a strength session. Every
+// exercise must be in the catalog.
+func (s *strengthService) SetSessionExercises(ctx context.Context, userID uint, activityID string, req models.SetStrengthExercisesRequest) (*models.StrengthSessionResponse, error) {
+	if _, err := s.getStrengthActivity(ctx, userID, activityID); err != nil {
+		return nil, err
+	}
+
+	var sets []models.StrengthSet
+	for i, exercise := range req.Exercises {
+		if _, ok := s.catalog.Get(exercise.ExerciseID); !ok {
+			return nil, fmt.Errorf("%w: %s", customErrors.ErrExerciseNotFound, exercise.ExerciseID)
+		}
+		for j, set := range exercise.Sets {
+			sets = append(sets, models.StrengthSet{
+				ActivityID:    activityID,
+				UserID:        userID,
+				ExerciseID:    exercise.ExerciseID,
+				ExerciseIndex: i,
+				SetIndex:      j,
+				Reps:          set.Reps,
+				WeightKg:      set.WeightKg,
+				RPE:           set.RPE,
+			})
+		}
+	}
+
+	if err := s.strengthRepo.ReplaceSets(ctx, activityID, sets); err != nil {
+		return nil, err
+	}
+
+	response := s.toSessionResponse(activityID, sets)
+	return &response, nil
+}
+
+// GetExerciseHistory lists the sessions in which the user did an exercise, most recent
+// first, with the best one-rep max estimated across all of them
+func (s *strengthService) GetExerciseHistory(ctx context.Context, userID uint, exerciseID string, query models.GetExerciseHistoryQuery) (*models.ExerciseHistoryResponse, error) {
+	exercise, ok := s.catalog.Get(exerciseID)
+	if !ok {
+		return nil, customErrors.ErrExerciseNotFound
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	records, err := s.strengthRepo.GetExerciseSets(ctx, userID, exerciseID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get exercise history")
+		return nil, err
+	}
+
+	response := &models.ExerciseHistoryResponse{
+		ExerciseID: exercise.ID,
+		Name:       exercise.Name,
+		Sessions:   []models.ExerciseSessionResponse{},
+	}
+
+	// Records come grouped by session, most recent first
+	var best float64
+	for start := 0; start < len(records); {
+		end := start
+		for end < len(records) && records[end].ActivityID == records[start].ActivityID {
+			end++
+		}
+		session := toExerciseSession(records[start:end])
+		start = end
+
+		if session.EstimatedOneRepMaxKg != nil && *session.EstimatedOneRepMaxKg > best {
+			best = *session.EstimatedOneRepMaxKg
+			doneAt := session.DoneAt
+			response.BestEstimatedOneRepMaxKg, response.BestEstimatedOneRepMaxAt = session.EstimatedOneRepMaxKg, &doneAt
+		}
+
+		if response.TotalSessions >= query.Offset && len(response.Sessions) < query.Limit {
+			response.Sessions = append(response.Sessions, session)
+		}
+		response.TotalSessions++
+	}
+
+	return response, nil
+}
+
+func (s *strengthService) getStrengthActivity(ctx context.Context, userID uint, activityID string) (*models.Activity, error) {
+	activity, err := s.activityRepo.GetActivityByID(ctx, activityID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get strength activity")
+		return nil, err
+	}
+	if activity == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+	if activity.ActivityType != models.StrengthActivityType {
+		return nil, customErrors.ErrNotStrengthActivity
+	}
+	return activity, nil
+}
+
+// toSessionResponse groups sets ordered by exercise and set into the session's exercises
+func (s *strengthService) toSessionResponse(activityID string, sets []models.StrengthSet) models.StrengthSessionResponse {
+	response := models.StrengthSessionResponse{
+		ActivityID: activityID,
+		Exercises:  []models.StrengthExerciseResponse{},
+	}
+
+	var exerciseSets []strength.Set
+	for i, set := range sets {
+		if i == 0 || set.ExerciseIndex != sets[i-1].ExerciseIndex {
+			name := set.ExerciseID
+			if exercise, ok := s.catalog.Get(set.ExerciseID); ok {
+				name = exercise.Name
+			}
+			response.Exercises = append(response.Exercises, models.StrengthExerciseResponse{
+				ExerciseID: set.ExerciseID,
+				Name:       name,
+			})
+			exerciseSets = nil
+		}
+
+		current := &response.Exercises[len(response.Exercises)-1]
+		logged := toStrengthSet(set)
+		exerciseSets = append(exerciseSets, logged)
+
+		setResponse := models.StrengthSetResponse{Reps: set.Reps, WeightKg: set.WeightKg, RPE: set.RPE}
+		if estimate, ok := strength.EstimateOneRepMax(logged); ok {
+			estimate = roundTo(estimate, 1)
+			setResponse.EstimatedOneRepMaxKg = &estimate
+		}
+		current.Sets = append(current.Sets, setResponse)
+		current.VolumeKg = roundTo(strength.Volume(exerciseSets), 1)
+		if best, ok := strength.BestOneRepMax(exerciseSets); ok {
+			best = roundTo(best, 1)
+			current.EstimatedOneRepMaxKg = &best
+		}
+
+		response.TotalSets++
+		response.TotalReps += set.Reps
+		response.TotalVolumeKg += float64(set.Reps) * logged.WeightKg
+	}
+	response.TotalVolumeKg = roundTo(response.TotalVolumeKg, 1)
+
+	return response
+}
+
+// toExerciseSession summarises the sets of an exercise done in one session
+func toExerciseSession(records []models.ExerciseSetRecord) models.ExerciseSessionResponse {
+	session := models.ExerciseSessionResponse{
+		ActivityID: records[0].ActivityID,
+		DoneAt:     records[0].DoneAt,
+		Sets:       len(records),
+	}
+
+	sets := make([]strength.Set, len(records))
+	for i, record := range records {
+		sets[i] = toStrengthSet(record.StrengthSet)
+		session.Reps += record.Reps
+		if record.WeightKg != nil && (session.TopSetWeightKg == nil || *record.WeightKg > *session.TopSetWeightKg) {
+			topSet := *record.WeightKg
+			session.TopSetWeightKg = &topSet
+		}
+	}
+
+	session.VolumeKg = roundTo(strength.Volume(sets), 1)
+	if best, ok := strength.BestOneRepMax(sets); ok {
+		best = roundTo(best, 1)
+		session.EstimatedOneRepMaxKg = &best
+	}
+	return session
+}
+
+func toStrengthSet(set models.StrengthSet) strength.Set {
+	logged := strength.Set{Reps: set.Reps}
+	if set.WeightKg != nil {
+		logged.WeightKg = *set.WeightKg
+	}
+	if set.RPE != nil {
+		logged.RPE = *set.RPE
+	}
+	return logged
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/heartrate"
+	"FitByte/pkg/log"
+	"FitByte/pkg/trainingload"
+	"context"
+	"time"
+)
+
+// TrainingLoadService keeps the daily acute and chronic training load of every user.
+// Changes to activities mark the stored days stale from the day they touch; reading the
+// load recomputes only the days from there on.
+type TrainingLoadService interface {
+	MarkStale(ctx context.Context, userID uint, since time.Time) error
+	GetLoad(ctx context.Context, userID uint, query models.GetTrainingLoadQuery) (*models.TrainingLoadResponse, error)
+	GetActivityLoad(ctx context.Context, userID uint, activityID string) (*models.ActivityLoadResponse, error)
+}
+
+type trainingLoadService struct {
+	trainingLoadRepo repositories.TrainingLoadRepository
+	profileRepo      repositories.ProfileRepository
+}
+
+func NewTrainingLoadService(trainingLoadRepo repositories.TrainingLoadRepository, profileRepo repositories.ProfileRepository) TrainingLoadService {
+	return &trainingLoadService{
+		trainingLoadRepo: trainingLoadRepo,
+		profileRepo:      profileRepo,
+	}
+}
+
+// MarkStale is called in the transaction that saves a change affecting the load from
+// since on, so no saved change leaves the stored days after it looking up to date.
+func (s *trainingLoadService) MarkStale(ctx context.Context, userID uint, since time.Time) error {
+	if err := s.trainingLoadRepo.MarkStale(ctx, userID, utcDay(since)); err != nil {
+		log.Logger.Error().Err(err).Uint("userID", userID).Msg("Failed to mark training load stale")
+		return err
+	}
+	return nil
+}
+
+// GetLoad returns the load of every UTC day from query.From to query.To with the acute
+// and chronic load and the training stress balance
+func (s *trainingLoadService) GetLoad(ctx context.Context, userID uint, query models.GetTrainingLoadQuery) (*models.TrainingLoadResponse, error) {
+	from, to := query.Range(time.Now())
+
+	if err := s.refresh(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// The load going into the first day comes from the last day trained before it
+	var state trainingload.State
+	previous, err := s.trainingLoadRepo.GetLastDayBefore(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		state = trainingload.State{Acute: previous.AcuteLoad, Chronic: previous.ChronicLoad}
+		state = state.Rest(daysBetween(previous.Day, from) - 1)
+	}
+
+	stored, err := s.trainingLoadRepo.GetDays(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.TrainingLoadResponse{
+		AcuteDays:   trainingload.AcuteDays,
+		ChronicDays: trainingload.ChronicDays,
+		Days:        make([]models.TrainingLoadDayResponse, 0, daysBetween(from, to)+1),
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayResponse := models.TrainingLoadDayResponse{
+			Date:    day.Format(models.DateLayout),
+			Balance: roundTo(state.Balance(), 1),
+		}
+
+		if len(stored) > 0 && stored[0].Day.Equal(day) {
+			dayResponse.Activities = stored[0].ActivityCount
+			dayResponse.Load = roundTo(stored[0].Load, 1)
+			state = trainingload.State{Acute: stored[0].AcuteLoad, Chronic: stored[0].ChronicLoad}
+			stored = stored[1:]
+		} else {
+			state = state.Rest(1)
+		}
+
+		dayResponse.AcuteLoad = roundTo(state.Acute, 1)
+		dayResponse.ChronicLoad = roundTo(state.Chronic, 1)
+		response.Days = append(response.Days, dayResponse)
+	}
+
+	return response, nil
+}
+
+// GetActivityLoad returns the training load of a single activity
+func (s *trainingLoadService) GetActivityLoad(ctx context.Context, userID uint, activityID string) (*models.ActivityLoadResponse, error) {
+	input, err := s.trainingLoadRepo.GetActivityLoadInput(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return nil, customErrors.ErrActivityNotFound
+	}
+
+	settings, err := s.heartRateSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	load, method := activityLoad(*input, settings)
+	return &models.ActivityLoadResponse{
+		ActivityID: input.ActivityID,
+		Load:       roundTo(load, 1),
+		Method:     method,
+	}, nil
+}
+
+// refresh recomputes the stored days from the day the load became stale, starting from
+// the acute and chronic load at the end of the last day trained before it
+func (s *trainingLoadService) refresh(ctx context.Context, userID uint) error {
+	state, err := s.trainingLoadRepo.GetState(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Without a state the load has never been computed, so it is stale from the beginning
+	var (
+		staleFrom  time.Time
+		generation int64
+	)
+	if state != nil {
+		if state.StaleFrom == nil {
+			return nil
+		}
+		staleFrom, generation = *state.StaleFrom, state.Generation
+	}
+
+	var (
+		current trainingload.State
+		lastDay time.Time
+	)
+	previous, err := s.trainingLoadRepo.GetLastDayBefore(ctx, userID, staleFrom)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		current = trainingload.State{Acute: previous.AcuteLoad, Chronic: previous.ChronicLoad}
+		lastDay = previous.Day
+	}
+
+	inputs, err := s.trainingLoadRepo.GetActivityLoadInputs(ctx, userID, staleFrom)
+	if err != nil {
+		return err
+	}
+	settings, err := s.heartRateSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var days []models.TrainingLoadDay
+	for _, input := range inputs {
+		load, _ := activityLoad(input, settings)
+
+		day := utcDay(input.DoneAt)
+		if n := len(days); n > 0 && days[n-1].Day.Equal(day) {
+			days[n-1].ActivityCount++
+			days[n-1].Load += load
+			continue
+		}
+		days = append(days, models.TrainingLoadDay{UserID: userID, Day: day, ActivityCount: 1, Load: load})
+	}
+
+	for i := range days {
+		if !lastDay.IsZero() {
+			current = current.Rest(daysBetween(lastDay, days[i].Day) - 1)
+		}
+		current = current.Next(days[i].Load)
+		days[i].AcuteLoad, days[i].ChronicLoad = current.Acute, current.Chronic
+		lastDay = days[i].Day
+	}
+
+	replaced, err := s.trainingLoadRepo.ReplaceDays(ctx, userID, staleFrom, generation, days)
+	if err != nil {
+		return err
+	}
+	if !replaced {
+		log.Logger.Info().Uint("userID", userID).Msg("Training load changed while it was recomputed")
+	}
+	return nil
+}
+
+// loadHeartRateSettings are what TRIMP is worked out from
+type loadHeartRateSettings struct {
+	profile   *models.Profile
+	restingHR int
+}
+
+// maxHR returns the maximum heart rate at the time given. One estimated from the age
+// follows the age at that time, so the load of an activity does not change on the user's
+// birthdays. It is zero when unknown.
+func (s loadHeartRateSettings) maxHR(at time.Time) int {
+	maxHR, _, _ := profileMaxHeartRate(s.profile, at)
+	return maxHR
+}
+
+func (s *trainingLoadService) heartRateSettings(ctx context.Context, userID uint) (loadHeartRateSettings, error) {
+	profile, err := s.profileRepo.GetProfileByID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get profile for training load")
+		return loadHeartRateSettings{}, err
+	}
+	if profile == nil {
+		return loadHeartRateSettings{}, customErrors.ErrorUserNotFound
+	}
+
+	settings := loadHeartRateSettings{profile: profile, restingHR: heartrate.DefaultRestingBPM}
+	if profile.RestingHeartRate != nil {
+		settings.restingHR = *profile.RestingHeartRate
+	}
+	return settings, nil
+}
+
+// activityLoad returns the training load of an activity: TRIMP when it has a heart rate
+// and the maximum heart rate is known, and its duration times the typical load of its
+// type otherwise
+func activityLoad(input models.ActivityLoadInput, settings loadHeartRateSettings) (float64, string) {
+	maxHR := settings.maxHR(input.DoneAt)
+	if len(input.HeartRateSamples) > 0 && maxHR > settings.restingHR {
+		samples, err := heartrate.Decode(input.HeartRateSamples)
+		if err == nil {
+			return heartrate.TRIMP(samples, maxHR, settings.restingHR), models.TrainingLoadMethodHeartRate
+		}
+		log.Logger.Error().Err(err).Str("activityId", input.ActivityID).Msg("Failed to decode activity heart rate")
+	}
+	return float64(input.DurationInMinutes) * models.ActivityTypeLoadPerMinute[input.ActivityType], models.TrainingLoadMethodDuration
+}
+
+// markLoadStale marks the training load stale from the earliest of the activities
+func markLoadStale(ctx context.Context, trainingLoadSvc TrainingLoadService, userID uint, activities ...models.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	since := activities[0].DoneAt
+	for _, activity := range activities[1:] {
+		if activity.DoneAt.Before(since) {
+			since = activity.DoneAt
+		}
+	}
+	return trainingLoadSvc.MarkStale(ctx, userID, since)
+}
+
+// utcDay returns midnight UTC of the day t falls on there
+func utcDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
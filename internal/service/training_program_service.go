@@ -0,0 +1,396 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"FitByte/pkg/rrule"
+	"FitByte/pkg/trainingplan"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TrainingProgramService interface {
+	GetPrograms() []models.TrainingProgramSummary
+	GetProgram(programID string, version int) (*trainingplan.Program, error)
+	Enroll(ctx context.Context, userID uint, programID string, req models.EnrollProgramRequest) (*models.ProgramEnrollmentResponse, error)
+	GetEnrollments(ctx context.Context, userID uint) ([]models.ProgramEnrollmentResponse, error)
+	GetEnrollment(ctx context.Context, userID uint, enrollmentID string) (*models.ProgramEnrollmentResponse, error)
+	CancelEnrollment(ctx context.Context, userID uint, enrollmentID string) error
+	StartProgressionSync(interval time.Duration)
+}
+
+type trainingProgramService struct {
+	catalog        *trainingplan.Catalog
+	enrollmentRepo repositories.ProgramEnrollmentRepository
+}
+
+func NewTrainingProgramService(catalog *trainingplan.Catalog, enrollmentRepo repositories.ProgramEnrollmentRepository) TrainingProgramService {
+	return &trainingProgramService{
+		catalog:        catalog,
+		enrollmentRepo: enrollmentRepo,
+	}
+}
+
+func (s *trainingProgramService) GetPrograms() []models.TrainingProgramSummary {
+	programs := s.catalog.Latest()
+
+	summaries := make([]models.TrainingProgramSummary, len(programs))
+	for i, program := range programs {
+		sessions := 0
+		for _, week := range program.Weeks {
+			sessions += len(week.Sessions)
+		}
+		summaries[i] = models.TrainingProgramSummary{
+			ProgramID:     program.ID,
+			Version:       program.Version,
+			Name:          program.Name,
+			Description:   program.Description,
+			Weeks:         len(program.Weeks),
+			TotalSessions: sessions,
+		}
+	}
+	return summaries
+}
+
+func (s *trainingProgramService) GetProgram(programID string, version int) (*trainingplan.Program, error) {
+	program, ok := s.catalog.Get(programID, version)
+	if !ok {
+		return nil, customErrors.ErrProgramNotFound
+	}
+	return &program, nil
+}
+
+// Enroll starts a program on the requested date and schedules all of its sessions as
+// planned workouts. A start date of yesterday is accepted for users ahead of UTC.
+func (s *trainingProgramService) Enroll(ctx context.Context, userID uint, programID string, req models.EnrollProgramRequest) (*models.ProgramEnrollmentResponse, error) {
+	version := 0
+	if req.Version != nil {
+		version = *req.Version
+	}
+	program, ok := s.catalog.Get(programID, version)
+	if !ok {
+		return nil, customErrors.ErrProgramNotFound
+	}
+
+	startDate, err := time.Parse(models.DateLayout, req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	if startDate.Before(rrule.Date(time.Now()).AddDate(0, 0, -1)) {
+		return nil, customErrors.ErrStartDateInPast
+	}
+
+	existing, err := s.enrollmentRepo.GetActiveEnrollment(ctx, userID, program.ID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to check existing program enrollment")
+		return nil, err
+	}
+	if existing != nil {
+		return nil, customErrors.ErrAlreadyEnrolled
+	}
+
+	enrollment := models.ProgramEnrollment{
+		EnrollmentID:     uuid.New().String(),
+		UserID:           userID,
+		ProgramID:        program.ID,
+		ProgramVersion:   program.Version,
+		StartDate:        startDate,
+		CurrentWeek:      0,
+		CurrentWeekStart: startDate,
+		Status:           models.EnrollmentStatusActive,
+	}
+	workouts := programWorkouts(enrollment, program.Schedule(0, startDate))
+
+	err = s.enrollmentRepo.CreateEnrollment(ctx, enrollment, workouts)
+	if err != nil {
+		// A concurrent enrollment in the same program won the race for the active one
+		if err == gorm.ErrDuplicatedKey {
+			return nil, customErrors.ErrAlreadyEnrolled
+		}
+		log.Logger.Error().Err(err).Msg("Failed to enroll in training program")
+		return nil, err
+	}
+
+	enrollment.CreatedAt = time.Now()
+	response := toEnrollmentResponse(enrollment, program)
+	return &response, nil
+}
+
+func (s *trainingProgramService) GetEnrollments(ctx context.Context, userID uint) ([]models.ProgramEnrollmentResponse, error) {
+	enrollments, err := s.enrollmentRepo.GetEnrollmentsByUserID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollments")
+		return nil, err
+	}
+
+	today := rrule.Date(time.Now())
+	responses := make([]models.ProgramEnrollmentResponse, len(enrollments))
+	for i := range enrollments {
+		program, err := s.syncEnrollment(ctx, &enrollments[i], today)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = toEnrollmentResponse(enrollments[i], program)
+	}
+
+	return responses, nil
+}
+
+// GetEnrollment returns an enrollment brought up to date with its adherence so far
+func (s *trainingProgramService) GetEnrollment(ctx context.Context, userID uint, enrollmentID string) (*models.ProgramEnrollmentResponse, error) {
+	enrollment, err := s.enrollmentRepo.GetEnrollmentByID(ctx, enrollmentID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollment")
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, customErrors.ErrEnrollmentNotFound
+	}
+
+	today := rrule.Date(time.Now())
+	program, err := s.syncEnrollment(ctx, enrollment, today)
+	if err != nil {
+		return nil, err
+	}
+
+	adherence, err := s.adherence(ctx, *enrollment, today)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toEnrollmentResponse(*enrollment, program)
+	response.Adherence = adherence
+	return &response, nil
+}
+
+// adherence adds up the finished weeks and the sessions of the week in progress that
+// were due by today
+func (s *trainingProgramService) adherence(ctx context.Context, enrollment models.ProgramEnrollment, today time.Time) (*models.PlanAdherence, error) {
+	weeks, err := s.enrollmentRepo.GetEnrollmentWeeks(ctx, enrollment.EnrollmentID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollment weeks")
+		return nil, err
+	}
+
+	adherence := &models.PlanAdherence{Weeks: make([]models.WeekAdherence, 0, len(weeks)+1)}
+	for _, week := range weeks {
+		adherence.Weeks = append(adherence.Weeks, models.WeekAdherence{
+			Week:              week.Week + 1,
+			StartDate:         week.StartDate.Format(models.DateLayout),
+			PlannedSessions:   week.PlannedSessions,
+			CompletedSessions: week.CompletedSessions,
+			Rate:              roundTo(trainingplan.Adherence(week.PlannedSessions, week.CompletedSessions), 2),
+			Repeated:          week.Repeated,
+		})
+	}
+
+	if enrollment.Status == models.EnrollmentStatusActive && !today.Before(enrollment.CurrentWeekStart) {
+		planned, completed, err := s.enrollmentRepo.CountSessions(ctx, enrollment.EnrollmentID, enrollment.CurrentWeekStart, today)
+		if err != nil {
+			return nil, err
+		}
+		adherence.Weeks = append(adherence.Weeks, models.WeekAdherence{
+			Week:              enrollment.CurrentWeek + 1,
+			StartDate:         enrollment.CurrentWeekStart.Format(models.DateLayout),
+			PlannedSessions:   planned,
+			CompletedSessions: completed,
+			Rate:              roundTo(trainingplan.Adherence(planned, completed), 2),
+			InProgress:        true,
+		})
+	}
+
+	for _, week := range adherence.Weeks {
+		adherence.PlannedSessions += week.PlannedSessions
+		adherence.CompletedSessions += week.CompletedSessions
+	}
+	adherence.MissedSessions = adherence.PlannedSessions - adherence.CompletedSessions
+	adherence.Rate = roundTo(trainingplan.Adherence(adherence.PlannedSessions, adherence.CompletedSessions), 2)
+
+	return adherence, nil
+}
+
+// CancelEnrollment stops an active enrollment and removes its upcoming sessions
+func (s *trainingProgramService) CancelEnrollment(ctx context.Context, userID uint, enrollmentID string) error {
+	enrollment, err := s.enrollmentRepo.GetEnrollmentByID(ctx, enrollmentID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get program enrollment for cancellation")
+		return err
+	}
+	if enrollment == nil {
+		return customErrors.ErrEnrollmentNotFound
+	}
+	if enrollment.Status != models.EnrollmentStatusActive {
+		return customErrors.ErrEnrollmentNotActive
+	}
+
+	err = s.enrollmentRepo.CancelEnrollment(ctx, enrollmentID, userID, rrule.Date(time.Now()))
+	if err != nil {
+		// The enrollment finished or was cancelled since it was read
+		if err == gorm.ErrRecordNotFound {
+			return customErrors.ErrEnrollmentNotActive
+		}
+		return err
+	}
+	return nil
+}
+
+// syncEnrollment closes every week of an active enrollment that ended before today. A
+// week with too few sessions completed is repeated by scheduling it again, and the
+// rest of the program after it, from the following week on. The enrollment is
+// updated in place and its program is returned.
+func (s *trainingProgramService) syncEnrollment(ctx context.Context, enrollment *models.ProgramEnrollment, today time.Time) (trainingplan.Program, error) {
+	program, ok := s.catalog.Get(enrollment.ProgramID, enrollment.ProgramVersion)
+	if !ok {
+		log.Logger.Warn().Str("programId", enrollment.ProgramID).Int("version", enrollment.ProgramVersion).Msg("Enrolled program version is no longer in the catalog")
+		return trainingplan.Program{ID: enrollment.ProgramID, Version: enrollment.ProgramVersion}, nil
+	}
+
+	for enrollment.Status == models.EnrollmentStatusActive {
+		weekEnd := enrollment.CurrentWeekStart.AddDate(0, 0, 6)
+		if !today.After(weekEnd) {
+			break
+		}
+
+		planned, completed, err := s.enrollmentRepo.CountSessions(ctx, enrollment.EnrollmentID, enrollment.CurrentWeekStart, weekEnd)
+		if err != nil {
+			return program, err
+		}
+
+		nextWeek, nextRepeats, repeated := program.Advance(enrollment.CurrentWeek, enrollment.WeekRepeats, planned, completed)
+		nextStart := enrollment.CurrentWeekStart.AddDate(0, 0, 7)
+		now := time.Now()
+
+		updates := map[string]interface{}{
+			"current_week":       nextWeek,
+			"current_week_start": nextStart,
+			"week_repeats":       nextRepeats,
+			"updated_at":         now,
+		}
+		status, completedAt := models.EnrollmentStatusActive, (*time.Time)(nil)
+		if nextWeek >= len(program.Weeks) {
+			status, completedAt = models.EnrollmentStatusCompleted, &now
+			updates["status"] = status
+			updates["completed_at"] = completedAt
+		}
+
+		week := models.ProgramEnrollmentWeek{
+			EnrollmentID:      enrollment.EnrollmentID,
+			Week:              enrollment.CurrentWeek,
+			StartDate:         enrollment.CurrentWeekStart,
+			PlannedSessions:   planned,
+			CompletedSessions: completed,
+			Repeated:          repeated,
+		}
+
+		var rescheduleFrom *time.Time
+		var workouts []models.PlannedWorkout
+		if repeated {
+			rescheduleFrom = &nextStart
+			workouts = programWorkouts(*enrollment, program.Schedule(nextWeek, nextStart))
+		}
+
+		advanced, err := s.enrollmentRepo.AdvanceEnrollment(ctx, *enrollment, updates, week, rescheduleFrom, workouts)
+		if err != nil {
+			return program, err
+		}
+		if !advanced {
+			// Another request advanced the enrollment first; carry on from its state
+			current, err := s.enrollmentRepo.GetEnrollmentByID(ctx, enrollment.EnrollmentID, enrollment.UserID)
+			if err != nil {
+				return program, err
+			}
+			if current == nil {
+				return program, nil
+			}
+			*enrollment = *current
+			continue
+		}
+
+		enrollment.CurrentWeek = nextWeek
+		enrollment.CurrentWeekStart = nextStart
+		enrollment.WeekRepeats = nextRepeats
+		enrollment.Status = status
+		enrollment.CompletedAt = completedAt
+	}
+
+	return program, nil
+}
+
+// StartProgressionSync brings active enrollments up to date in the background every
+// interval, so that repeated weeks show on the calendar without visiting the enrollment
+func (s *trainingProgramService) StartProgressionSync(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx := context.Background()
+			enrollments, err := s.enrollmentRepo.GetActiveEnrollments(ctx)
+			if err != nil {
+				log.Logger.Error().Err(err).Msg("Failed to get active program enrollments for sync")
+				continue
+			}
+
+			today := rrule.Date(time.Now())
+			for i := range enrollments {
+				_, err := s.syncEnrollment(ctx, &enrollments[i], today)
+				if err != nil {
+					log.Logger.Error().Err(err).Str("enrollmentId", enrollments[i].EnrollmentID).Msg("Failed to sync program enrollment")
+				}
+			}
+		}
+	}()
+}
+
+// programWorkouts turns scheduled program sessions into planned workouts of the enrollment
+func programWorkouts(enrollment models.ProgramEnrollment, sessions []trainingplan.ScheduledSession) []models.PlannedWorkout {
+	workouts := make([]models.PlannedWorkout, len(sessions))
+	for i, scheduled := range sessions {
+		session := scheduled.Session
+		lastDate := scheduled.Date
+		enrollmentID := enrollment.EnrollmentID
+
+		var notes *string
+		if session.Notes != "" {
+			notes = &session.Notes
+		}
+
+		workouts[i] = models.PlannedWorkout{
+			PlannedWorkoutID:        uuid.New().String(),
+			UserID:                  enrollment.UserID,
+			ActivityType:            session.ActivityType,
+			ScheduledDate:           scheduled.Date,
+			TargetDurationInMinutes: session.DurationInMinutes,
+			TargetCalories:          models.ActivityTypeCalories[session.ActivityType] * session.DurationInMinutes,
+			LastDate:                &lastDate,
+			Notes:                   notes,
+			EnrollmentID:            &enrollmentID,
+		}
+	}
+	return workouts
+}
+
+func toEnrollmentResponse(enrollment models.ProgramEnrollment, program trainingplan.Program) models.ProgramEnrollmentResponse {
+	currentWeek := enrollment.CurrentWeek + 1
+	if totalWeeks := len(program.Weeks); totalWeeks > 0 && currentWeek > totalWeeks {
+		currentWeek = totalWeeks
+	}
+	return models.ProgramEnrollmentResponse{
+		EnrollmentID:   enrollment.EnrollmentID,
+		ProgramID:      enrollment.ProgramID,
+		ProgramVersion: enrollment.ProgramVersion,
+		ProgramName:    program.Name,
+		StartDate:      enrollment.StartDate.Format(models.DateLayout),
+		Status:         enrollment.Status,
+		CurrentWeek:    currentWeek,
+		TotalWeeks:     len(program.Weeks),
+		WeekRepeats:    enrollment.WeekRepeats,
+		CompletedAt:    enrollment.CompletedAt,
+		CreatedAt:      enrollment.CreatedAt,
+	}
+}
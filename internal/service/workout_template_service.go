@@ -0,0 +1,214 @@
+package service
+
+import (
+	customErrors "FitByte/internal/errors"
+	"FitByte/internal/models"
+	"FitByte/internal/repositories"
+	"FitByte/pkg/log"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkoutTemplateService interface {
+	CreateTemplate(ctx context.Context, userID uint, req models.CreateWorkoutTemplateRequest) (*models.WorkoutTemplateResponse, error)
+	GetTemplates(ctx context.Context, userID uint) ([]models.WorkoutTemplateResponse, error)
+	GetTemplate(ctx context.Context, userID uint, templateID string) (*models.WorkoutTemplateResponse, error)
+	UpdateTemplate(ctx context.Context, userID uint, templateID string, req models.UpdateWorkoutTemplateRequest) (*models.WorkoutTemplateResponse, error)
+	DeleteTemplate(ctx context.Context, userID uint, templateID string) error
+	CreateActivityFromTemplate(ctx context.Context, userID uint, templateID string, req models.CreateActivityFromTemplateRequest, conflictPolicy string) (*models.ActivityResponse, error)
+}
+
+type workoutTemplateService struct {
+	templateRepo repositories.WorkoutTemplateRepository
+	activitySvc  ActivityService
+}
+
+func NewWorkoutTemplateService(templateRepo repositories.WorkoutTemplateRepository, activityService ActivityService) WorkoutTemplateService {
+	return &workoutTemplateService{
+		templateRepo: templateRepo,
+		activitySvc:  activityService,
+	}
+}
+
+func (s *workoutTemplateService) CreateTemplate(ctx context.Context, userID uint, req models.CreateWorkoutTemplateRequest) (*models.WorkoutTemplateResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	err := s.checkNameAvailable(ctx, userID, name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	template := models.WorkoutTemplate{
+		TemplateID:        uuid.New().String(),
+		UserID:            userID,
+		Name:              name,
+		ActivityType:      req.ActivityType,
+		DurationInMinutes: req.DurationInMinutes,
+		Notes:             normalizeNotes(req.Notes),
+		Exercises:         models.TemplateExercises(req.Exercises),
+	}
+
+	err = s.templateRepo.CreateTemplate(ctx, template)
+	if err != nil {
+		if err == gorm.ErrDuplicatedKey {
+			// A concurrent request took the name since it was checked
+			return nil, customErrors.ErrTemplateNameTaken
+		}
+		log.Logger.Error().Err(err).Msg("Failed to create workout template")
+		return nil, err
+	}
+
+	now := time.Now()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	response := toWorkoutTemplateResponse(template)
+	return &response, nil
+}
+
+// checkNameAvailable returns ErrTemplateNameTaken when another of the user's templates
+// than templateID is already called name
+func (s *workoutTemplateService) checkNameAvailable(ctx context.Context, userID uint, name, templateID string) error {
+	existing, err := s.templateRepo.GetTemplateByName(ctx, name, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to check workout template name")
+		return err
+	}
+	if existing != nil && existing.TemplateID != templateID {
+		return customErrors.ErrTemplateNameTaken
+	}
+	return nil
+}
+
+func (s *workoutTemplateService) GetTemplates(ctx context.Context, userID uint) ([]models.WorkoutTemplateResponse, error) {
+	templates, err := s.templateRepo.GetTemplatesByUserID(ctx, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get workout templates")
+		return nil, err
+	}
+
+	responses := make([]models.WorkoutTemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = toWorkoutTemplateResponse(template)
+	}
+
+	return responses, nil
+}
+
+func (s *workoutTemplateService) GetTemplate(ctx context.Context, userID uint, templateID string) (*models.WorkoutTemplateResponse, error) {
+	template, err := s.templateRepo.GetTemplateByID(ctx, templateID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get workout template")
+		return nil, err
+	}
+	if template == nil {
+		return nil, customErrors.ErrTemplateNotFound
+	}
+
+	response := toWorkoutTemplateResponse(*template)
+	return &response, nil
+}
+
+func (s *workoutTemplateService) UpdateTemplate(ctx context.Context, userID uint, templateID string, req models.UpdateWorkoutTemplateRequest) (*models.WorkoutTemplateResponse, error) {
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		err := s.checkNameAvailable(ctx, userID, name, templateID)
+		if err != nil {
+			return nil, err
+		}
+		updates["name"] = name
+	}
+
+	if req.ActivityType != nil {
+		updates["activity_type"] = *req.ActivityType
+	}
+
+	if req.DurationInMinutes != nil {
+		updates["duration_in_minutes"] = *req.DurationInMinutes
+	}
+
+	if req.Notes != nil {
+		updates["notes"] = normalizeNotes(req.Notes)
+	}
+
+	if req.Exercises != nil {
+		updates["exercises"] = models.TemplateExercises(*req.Exercises)
+	}
+
+	updates["updated_at"] = time.Now()
+
+	err := s.templateRepo.UpdateTemplate(ctx, templateID, userID, updates)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customErrors.ErrTemplateNotFound
+		}
+		if err == gorm.ErrDuplicatedKey {
+			return nil, customErrors.ErrTemplateNameTaken
+		}
+		log.Logger.Error().Err(err).Msg("Failed to update workout template")
+		return nil, err
+	}
+
+	return s.GetTemplate(ctx, userID, templateID)
+}
+
+func (s *workoutTemplateService) DeleteTemplate(ctx context.Context, userID uint, templateID string) error {
+	err := s.templateRepo.DeleteTemplate(ctx, templateID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customErrors.ErrTemplateNotFound
+		}
+		log.Logger.Error().Err(err).Msg("Failed to delete workout template")
+		return err
+	}
+	return nil
+}
+
+// CreateActivityFromTemplate logs an activity of the template's type, duration and
+// notes. DoneAt defaults to now; the request may override it and the duration.
+func (s *workoutTemplateService) CreateActivityFromTemplate(ctx context.Context, userID uint, templateID string, req models.CreateActivityFromTemplateRequest, conflictPolicy string) (*models.ActivityResponse, error) {
+	template, err := s.templateRepo.GetTemplateByID(ctx, templateID, userID)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get workout template for activity")
+		return nil, err
+	}
+	if template == nil {
+		return nil, customErrors.ErrTemplateNotFound
+	}
+
+	activityReq := models.CreateActivityRequest{
+		ActivityType:      template.ActivityType,
+		DoneAt:            time.Now().UTC().Format(time.RFC3339),
+		DurationInMinutes: template.DurationInMinutes,
+		Notes:             template.Notes,
+	}
+	if req.DoneAt != nil {
+		activityReq.DoneAt = *req.DoneAt
+	}
+	if req.DurationInMinutes != nil {
+		activityReq.DurationInMinutes = *req.DurationInMinutes
+	}
+
+	return s.activitySvc.CreateActivity(ctx, userID, activityReq, conflictPolicy)
+}
+
+func toWorkoutTemplateResponse(template models.WorkoutTemplate) models.WorkoutTemplateResponse {
+	exercises := []models.TemplateExercise(template.Exercises)
+	if exercises == nil {
+		exercises = []models.TemplateExercise{}
+	}
+	return models.WorkoutTemplateResponse{
+		TemplateID:        template.TemplateID,
+		Name:              template.Name,
+		ActivityType:      template.ActivityType,
+		DurationInMinutes: template.DurationInMinutes,
+		Notes:             template.Notes,
+		Exercises:         exercises,
+		CreatedAt:         template.CreatedAt,
+		UpdatedAt:         template.UpdatedAt,
+	}
+}
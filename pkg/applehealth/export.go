@@ -0,0 +1,215 @@
+package applehealth
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrExportNotFound = errors.New("export.xml not found in archive")
+
+// dateLayout is the timestamp format used throughout export.xml
+const dateLayout = "2006-01-02 15:04:05 -0700"
+
+const workoutTypePrefix = "HKWorkoutActivityType"
+
+// uuidMetadataKeys are the metadata entries that carry a stable identifier for a workout, in order of preference
+var uuidMetadataKeys = []string{"HKExternalUUID", "HKMetadataKeyExternalUUID", "HKMetadataKeySyncIdentifier"}
+
+// uuidNamespace scopes identifiers derived for workouts that carry none of their own
+var uuidNamespace = uuid.MustParse("6f1c5b0e-2b43-4f0e-9a43-7c1e5a9c2d10")
+
+// Workout is a single HKWorkout record from an Apple Health export
+type Workout struct {
+	UUID           string
+	Sport          string
+	SourceName     string
+	StartDate      time.Time
+	EndDate        time.Time
+	Duration       time.Duration
+	DistanceMeters float64
+	EnergyKcal     float64
+}
+
+type workoutElement struct {
+	ActivityType      string `xml:"workoutActivityType,attr"`
+	Duration          string `xml:"duration,attr"`
+	DurationUnit      string `xml:"durationUnit,attr"`
+	TotalDistance     string `xml:"totalDistance,attr"`
+	TotalDistanceUnit string `xml:"totalDistanceUnit,attr"`
+	TotalEnergy       string `xml:"totalEnergyBurned,attr"`
+	TotalEnergyUnit   string `xml:"totalEnergyBurnedUnit,attr"`
+	SourceName        string `xml:"sourceName,attr"`
+	StartDate         string `xml:"startDate,attr"`
+	EndDate           string `xml:"endDate,attr"`
+	Metadata          []struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"MetadataEntry"`
+}
+
+// Export is an open export.xml document inside an export.zip archive
+type Export struct {
+	io.ReadCloser
+	// Size is the uncompressed size of export.xml, for progress reporting
+	Size int64
+	zip  *zip.ReadCloser
+}
+
+// OpenArchive opens the export.xml entry of an Apple Health export.zip without
+// extracting it; the entry is decompressed as it is read.
+func OpenArchive(name string) (*Export, error) {
+	archive, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+
+	for _, f := range archive.File {
+		if path.Base(f.Name) != "export.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			archive.Close()
+			return nil, fmt.Errorf("open export.xml: %w", err)
+		}
+		return &Export{ReadCloser: rc, Size: int64(f.UncompressedSize64), zip: archive}, nil
+	}
+
+	archive.Close()
+	return nil, ErrExportNotFound
+}
+
+func (e *Export) Close() error {
+	err := e.ReadCloser.Close()
+	if zipErr := e.zip.Close(); err == nil {
+		err = zipErr
+	}
+	return err
+}
+
+// ReadWorkouts streams export.xml and calls fn for every Workout element. Only one
+// workout element is held in memory at a time; all other records are skipped unparsed.
+func ReadWorkouts(r io.Reader, fn func(Workout) error) error {
+	decoder := xml.NewDecoder(r)
+	// Exports from older iOS versions contain characters that strict mode rejects
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read export.xml: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Workout" {
+			continue
+		}
+
+		var element workoutElement
+		if err := decoder.DecodeElement(&element, &start); err != nil {
+			return fmt.Errorf("decode workout: %w", err)
+		}
+
+		workout, err := element.toWorkout()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(workout); err != nil {
+			return err
+		}
+	}
+}
+
+func (e workoutElement) toWorkout() (Workout, error) {
+	startDate, err := time.Parse(dateLayout, e.StartDate)
+	if err != nil {
+		return Workout{}, fmt.Errorf("parse workout startDate %q: %w", e.StartDate, err)
+	}
+	endDate, err := time.Parse(dateLayout, e.EndDate)
+	if err != nil {
+		return Workout{}, fmt.Errorf("parse workout endDate %q: %w", e.EndDate, err)
+	}
+
+	w := Workout{
+		Sport:      strings.ToLower(strings.TrimPrefix(e.ActivityType, workoutTypePrefix)),
+		SourceName: e.SourceName,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Duration:   endDate.Sub(startDate),
+	}
+
+	if value, err := strconv.ParseFloat(e.Duration, 64); err == nil {
+		w.Duration = durationOf(value, e.DurationUnit)
+	}
+
+	if value, err := strconv.ParseFloat(e.TotalDistance, 64); err == nil {
+		w.DistanceMeters = metersOf(value, e.TotalDistanceUnit)
+	}
+
+	if value, err := strconv.ParseFloat(e.TotalEnergy, 64); err == nil {
+		w.EnergyKcal = value
+		if e.TotalEnergyUnit == "kJ" {
+			w.EnergyKcal = value / 4.184
+		}
+	}
+
+	for _, key := range uuidMetadataKeys {
+		for _, entry := range e.Metadata {
+			if entry.Key == key && entry.Value != "" {
+				w.UUID = entry.Value
+				break
+			}
+		}
+		if w.UUID != "" {
+			break
+		}
+	}
+
+	// Fall back to an identifier derived from the fields that make a workout unique,
+	// so the same export imported twice produces the same identifiers
+	if w.UUID == "" {
+		name := strings.Join([]string{e.SourceName, e.ActivityType, e.StartDate, e.EndDate}, "|")
+		w.UUID = uuid.NewSHA1(uuidNamespace, []byte(name)).String()
+	}
+
+	return w, nil
+}
+
+func durationOf(value float64, unit string) time.Duration {
+	switch unit {
+	case "s":
+		return time.Duration(value * float64(time.Second))
+	case "hr", "h":
+		return time.Duration(value * float64(time.Hour))
+	default:
+		return time.Duration(value * float64(time.Minute))
+	}
+}
+
+func metersOf(value float64, unit string) float64 {
+	switch unit {
+	case "km":
+		return value * 1000
+	case "mi":
+		return value * 1609.344
+	case "yd":
+		return value * 0.9144
+	case "ft":
+		return value * 0.3048
+	default:
+		return value
+	}
+}
@@ -0,0 +1,257 @@
+package applehealth
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testExport = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE HealthData>
+<HealthData locale="en_US">
+ <Me HKCharacteristicTypeIdentifierDateOfBirth="1990-01-01"/>
+ <Record type="HKQuantityTypeIdentifierHeartRate" unit="count/min" value="62" startDate="2024-05-01 07:00:00 +0200" endDate="2024-05-01 07:00:00 +0200"/>
+ <Workout workoutActivityType="HKWorkoutActivityTypeRunning" duration="45.5" durationUnit="min" totalDistance="8.2" totalDistanceUnit="km" totalEnergyBurned="520" totalEnergyBurnedUnit="kcal" sourceName="Apple Watch" startDate="2024-05-01 07:30:00 +0200" endDate="2024-05-01 08:16:00 +0200">
+  <MetadataEntry key="HKIndoorWorkout" value="0"/>
+  <MetadataEntry key="HKExternalUUID" value="run-1"/>
+  <WorkoutEvent type="HKWorkoutEventTypePause" date="2024-05-01 07:50:00 +0200"/>
+ </Workout>
+ <Workout workoutActivityType="HKWorkoutActivityTypeCycling" duration="1.5" durationUnit="hr" totalDistance="25" totalDistanceUnit="mi" totalEnergyBurned="2500" totalEnergyBurnedUnit="kJ" sourceName="Wahoo" startDate="2024-05-02 18:00:00 +0000" endDate="2024-05-02 19:30:00 +0000"/>
+ <Workout workoutActivityType="HKWorkoutActivityTypeFishing" sourceName="iPhone" startDate="2024-05-03 06:00:00 +0000" endDate="2024-05-03 09:00:00 +0000"/>
+</HealthData>
+`
+
+func readAll(t *testing.T, r io.Reader) []Workout {
+	t.Helper()
+	var workouts []Workout
+	err := ReadWorkouts(r, func(w Workout) error {
+		workouts = append(workouts, w)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadWorkouts: %v", err)
+	}
+	return workouts
+}
+
+func TestReadWorkouts(t *testing.T) {
+	workouts := readAll(t, strings.NewReader(testExport))
+	if len(workouts) != 3 {
+		t.Fatalf("read %d workouts, want 3", len(workouts))
+	}
+
+	run := workouts[0]
+	if run.UUID != "run-1" || run.Sport != "running" || run.SourceName != "Apple Watch" {
+		t.Errorf("run = %+v", run)
+	}
+	if want := time.Date(2024, 5, 1, 5, 30, 0, 0, time.UTC); !run.StartDate.Equal(want) {
+		t.Errorf("run StartDate = %v, want %v", run.StartDate, want)
+	}
+	// The recorded duration wins over the span between start and end, which includes a pause
+	if want := 45*time.Minute + 30*time.Second; run.Duration != want {
+		t.Errorf("run Duration = %v, want %v", run.Duration, want)
+	}
+	if run.DistanceMeters != 8200 || run.EnergyKcal != 520 {
+		t.Errorf("run distance %v m, energy %v kcal", run.DistanceMeters, run.EnergyKcal)
+	}
+
+	if workouts[2].Sport != "fishing" {
+		t.Errorf("unsupported workout type read as sport %q, want it passed through", workouts[2].Sport)
+	}
+}
+
+func TestReadWorkoutsUnits(t *testing.T) {
+	tests := []struct {
+		name         string
+		attrs        string
+		wantDuration time.Duration
+		wantMeters   float64
+		wantKcal     float64
+	}{
+		{
+			name:         "metric",
+			attrs:        `duration="30" durationUnit="min" totalDistance="5" totalDistanceUnit="km" totalEnergyBurned="300" totalEnergyBurnedUnit="kcal"`,
+			wantDuration: 30 * time.Minute, wantMeters: 5000, wantKcal: 300,
+		},
+		{
+			name:         "imperial",
+			attrs:        `duration="1.5" durationUnit="hr" totalDistance="25" totalDistanceUnit="mi" totalEnergyBurned="2500" totalEnergyBurnedUnit="kJ"`,
+			wantDuration: 90 * time.Minute, wantMeters: 40233.6, wantKcal: 597.5,
+		},
+		{
+			name:         "seconds and yards",
+			attrs:        `duration="1800" durationUnit="s" totalDistance="1000" totalDistanceUnit="yd"`,
+			wantDuration: 30 * time.Minute, wantMeters: 914.4,
+		},
+		{
+			name:         "feet",
+			attrs:        `duration="20" durationUnit="min" totalDistance="1000" totalDistanceUnit="ft"`,
+			wantDuration: 20 * time.Minute, wantMeters: 304.8,
+		},
+		{
+			name:         "meters",
+			attrs:        `duration="20" durationUnit="min" totalDistance="1500" totalDistanceUnit="m"`,
+			wantDuration: 20 * time.Minute, wantMeters: 1500,
+		},
+		{
+			// Without a recorded duration it is the span between start and end
+			name:         "missing attributes",
+			attrs:        ``,
+			wantDuration: time.Hour,
+		},
+		{
+			name:         "unparseable numbers",
+			attrs:        `duration="long" totalDistance="far" totalEnergyBurned="lots"`,
+			wantDuration: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := `<HealthData><Workout workoutActivityType="HKWorkoutActivityTypeWalking" ` + tt.attrs +
+				` startDate="2024-05-01 07:00:00 +0000" endDate="2024-05-01 08:00:00 +0000"/></HealthData>`
+			workouts := readAll(t, strings.NewReader(export))
+			if len(workouts) != 1 {
+				t.Fatalf("read %d workouts, want 1", len(workouts))
+			}
+
+			w := workouts[0]
+			if w.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", w.Duration, tt.wantDuration)
+			}
+			if math.Abs(w.DistanceMeters-tt.wantMeters) > 0.01 {
+				t.Errorf("DistanceMeters = %v, want %v", w.DistanceMeters, tt.wantMeters)
+			}
+			if math.Abs(w.EnergyKcal-tt.wantKcal) > 0.1 {
+				t.Errorf("EnergyKcal = %v, want %v", w.EnergyKcal, tt.wantKcal)
+			}
+		})
+	}
+}
+
+func TestReadWorkoutsDerivedUUID(t *testing.T) {
+	export := `<HealthData>
+ <Workout workoutActivityType="HKWorkoutActivityTypeYoga" sourceName="iPhone" startDate="2024-05-01 07:00:00 +0000" endDate="2024-05-01 08:00:00 +0000"/>
+ <Workout workoutActivityType="HKWorkoutActivityTypeYoga" sourceName="iPhone" startDate="2024-05-02 07:00:00 +0000" endDate="2024-05-02 08:00:00 +0000">
+  <MetadataEntry key="HKMetadataKeySyncIdentifier" value="sync-2"/>
+ </Workout>
+</HealthData>`
+
+	first := readAll(t, strings.NewReader(export))
+	second := readAll(t, strings.NewReader(export))
+
+	if first[0].UUID == "" || first[0].UUID != second[0].UUID {
+		t.Errorf("derived UUIDs %q and %q, want the same for the same workout", first[0].UUID, second[0].UUID)
+	}
+	if first[1].UUID != "sync-2" {
+		t.Errorf("UUID = %q, want the sync identifier", first[1].UUID)
+	}
+}
+
+func TestReadWorkoutsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+	}{
+		{
+			name:   "missing start date",
+			export: `<HealthData><Workout workoutActivityType="HKWorkoutActivityTypeRunning" endDate="2024-05-01 08:00:00 +0000"/></HealthData>`,
+		},
+		{
+			name:   "malformed end date",
+			export: `<HealthData><Workout workoutActivityType="HKWorkoutActivityTypeRunning" startDate="2024-05-01 07:00:00 +0000" endDate="yesterday"/></HealthData>`,
+		},
+		{
+			name:   "truncated document",
+			export: `<HealthData><Workout workoutActivityType="HKWorkoutActivityTypeRunning" startDate="2024-05-01 07:00:00 +0000" endDate="2024-05-01 08:00:00 +0000"><MetadataEntry key="HKExternalUUID"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ReadWorkouts(strings.NewReader(tt.export), func(Workout) error { return nil })
+			if err == nil {
+				t.Error("ReadWorkouts succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestReadWorkoutsCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := ReadWorkouts(strings.NewReader(testExport), func(Workout) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ReadWorkouts = %v after %d calls, want the callback error after 1", err, calls)
+	}
+}
+
+func writeArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	archive := zip.NewWriter(f)
+	for fileName, content := range files {
+		w, err := archive.Create(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestOpenArchive(t *testing.T) {
+	name := writeArchive(t, map[string]string{
+		"apple_health_export/export_cda.xml":            "<cda/>",
+		"apple_health_export/workout-routes/route1.gpx": "<gpx/>",
+		"apple_health_export/export.xml":                testExport,
+	})
+
+	export, err := OpenArchive(name)
+	if err != nil {
+		t.Fatalf("OpenArchive: %v", err)
+	}
+	defer export.Close()
+
+	if export.Size != int64(len(testExport)) {
+		t.Errorf("Size = %d, want %d", export.Size, len(testExport))
+	}
+	if workouts := readAll(t, export); len(workouts) != 3 {
+		t.Errorf("read %d workouts from the archive, want 3", len(workouts))
+	}
+}
+
+func TestOpenArchiveInvalid(t *testing.T) {
+	name := writeArchive(t, map[string]string{"apple_health_export/export_cda.xml": "<cda/>"})
+	if _, err := OpenArchive(name); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("OpenArchive without export.xml = %v, want ErrExportNotFound", err)
+	}
+
+	notZip := filepath.Join(t.TempDir(), "export.zip")
+	if err := os.WriteFile(notZip, []byte(testExport), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenArchive(notZip); err == nil {
+		t.Error("OpenArchive of a file that is not a zip succeeded")
+	}
+}
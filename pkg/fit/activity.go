@@ -0,0 +1,225 @@
+package fit
+
+import (
+	"io"
+	"time"
+)
+
+// Global message numbers from the FIT profile
+const (
+	MesgNumFileID  uint16 = 0
+	MesgNumSession uint16 = 18
+	MesgNumLap     uint16 = 19
+	MesgNumRecord  uint16 = 20
+)
+
+// Field numbers shared by session and lap messages
+const (
+	fieldStartTime        = 2
+	fieldTotalElapsedTime = 7
+	fieldTotalTimerTime   = 8
+	fieldTotalDistance    = 9
+	fieldTotalCalories    = 11
+)
+
+// Session message field numbers
+const (
+	sessionFieldSport        = 5
+	sessionFieldAvgHeartRate = 16
+	sessionFieldMaxHeartRate = 17
+	sessionFieldTotalAscent  = 22
+)
+
+// Lap message field numbers
+const (
+	lapFieldAvgHeartRate = 15
+	lapFieldMaxHeartRate = 16
+	lapFieldTotalAscent  = 21
+)
+
+// Record message field numbers
+const (
+	recordFieldPositionLat      = 0
+	recordFieldPositionLong     = 1
+	recordFieldAltitude         = 2
+	recordFieldHeartRate        = 3
+	recordFieldDistance         = 5
+	recordFieldEnhancedAltitude = 78
+)
+
+// fitEpoch is the zero point of FIT timestamps (1989-12-31T00:00:00Z)
+var fitEpoch = time.Date(1989, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// semicircleDegrees converts FIT semicircles to degrees
+const semicircleDegrees = 180.0 / (1 << 31)
+
+var sportNames = map[uint64]string{
+	0:  "generic",
+	1:  "running",
+	2:  "cycling",
+	5:  "swimming",
+	10: "training",
+	11: "walking",
+	17: "hiking",
+	62: "hiit",
+}
+
+// Session summarises a whole activity as reported by the device
+type Session struct {
+	Sport            string
+	StartTime        time.Time
+	TotalElapsedTime time.Duration
+	TotalTimerTime   time.Duration
+	TotalDistance    float64
+	TotalCalories    int
+	TotalAscent      float64
+	AvgHeartRate     int
+	MaxHeartRate     int
+}
+
+// Lap is a device lap within an activity
+type Lap struct {
+	StartTime        time.Time
+	TotalElapsedTime time.Duration
+	TotalTimerTime   time.Duration
+	TotalDistance    float64
+	TotalCalories    int
+	TotalAscent      float64
+	AvgHeartRate     int
+	MaxHeartRate     int
+}
+
+// Record is a single sample recorded during an activity
+type Record struct {
+	Timestamp   time.Time
+	Latitude    float64
+	Longitude   float64
+	HasPosition bool
+	Altitude    float64
+	HasAltitude bool
+	HeartRate   int
+	Distance    float64
+	HasDistance bool
+}
+
+// Activity holds the session, lap and record messages of a FIT activity file
+type Activity struct {
+	Sessions []Session
+	Laps     []Lap
+	Records  []Record
+}
+
+// DecodeActivity decodes a FIT file and extracts its session, lap and record messages
+func DecodeActivity(r io.Reader) (*Activity, error) {
+	file, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &Activity{}
+	for _, msg := range file.Messages {
+		switch msg.Global {
+		case MesgNumSession:
+			activity.Sessions = append(activity.Sessions, decodeSession(msg))
+		case MesgNumLap:
+			activity.Laps = append(activity.Laps, decodeLap(msg))
+		case MesgNumRecord:
+			activity.Records = append(activity.Records, decodeRecord(msg))
+		}
+	}
+
+	return activity, nil
+}
+
+func decodeSession(msg Message) Session {
+	session := Session{
+		StartTime:        msg.fieldTime(fieldStartTime),
+		TotalElapsedTime: msg.fieldDuration(fieldTotalElapsedTime),
+		TotalTimerTime:   msg.fieldDuration(fieldTotalTimerTime),
+		TotalDistance:    msg.fieldScaled(fieldTotalDistance, 100, 0),
+		TotalCalories:    int(msg.fieldUint(fieldTotalCalories)),
+		TotalAscent:      float64(msg.fieldUint(sessionFieldTotalAscent)),
+		AvgHeartRate:     int(msg.fieldUint(sessionFieldAvgHeartRate)),
+		MaxHeartRate:     int(msg.fieldUint(sessionFieldMaxHeartRate)),
+	}
+
+	if sport, ok := msg.Fields[sessionFieldSport].Uint(); ok {
+		session.Sport = sportNames[sport]
+	}
+
+	return session
+}
+
+func decodeLap(msg Message) Lap {
+	return Lap{
+		StartTime:        msg.fieldTime(fieldStartTime),
+		TotalElapsedTime: msg.fieldDuration(fieldTotalElapsedTime),
+		TotalTimerTime:   msg.fieldDuration(fieldTotalTimerTime),
+		TotalDistance:    msg.fieldScaled(fieldTotalDistance, 100, 0),
+		TotalCalories:    int(msg.fieldUint(fieldTotalCalories)),
+		TotalAscent:      float64(msg.fieldUint(lapFieldTotalAscent)),
+		AvgHeartRate:     int(msg.fieldUint(lapFieldAvgHeartRate)),
+		MaxHeartRate:     int(msg.fieldUint(lapFieldMaxHeartRate)),
+	}
+}
+
+func decodeRecord(msg Message) Record {
+	record := Record{
+		Timestamp: msg.fieldTime(fieldNumTimestamp),
+		HeartRate: int(msg.fieldUint(recordFieldHeartRate)),
+	}
+
+	lat, latOK := msg.Fields[recordFieldPositionLat].Int()
+	long, longOK := msg.Fields[recordFieldPositionLong].Int()
+	if latOK && longOK {
+		record.Latitude = float64(lat) * semicircleDegrees
+		record.Longitude = float64(long) * semicircleDegrees
+		record.HasPosition = true
+	}
+
+	// Enhanced altitude supersedes the 16-bit altitude field when both are present
+	if altitude, ok := msg.Fields[recordFieldEnhancedAltitude].Uint(); ok {
+		record.Altitude = float64(altitude)/5 - 500
+		record.HasAltitude = true
+	} else if altitude, ok := msg.Fields[recordFieldAltitude].Uint(); ok {
+		record.Altitude = float64(altitude)/5 - 500
+		record.HasAltitude = true
+	}
+
+	if distance, ok := msg.Fields[recordFieldDistance].Uint(); ok {
+		record.Distance = float64(distance) / 100
+		record.HasDistance = true
+	}
+
+	return record
+}
+
+func (m Message) fieldUint(field byte) uint64 {
+	value, _ := m.Fields[field].Uint()
+	return value
+}
+
+func (m Message) fieldScaled(field byte, scale, offset float64) float64 {
+	value, ok := m.Fields[field].Uint()
+	if !ok {
+		return 0
+	}
+	return float64(value)/scale - offset
+}
+
+func (m Message) fieldTime(field byte) time.Time {
+	value, ok := m.Fields[field].Uint()
+	if !ok {
+		return time.Time{}
+	}
+	return fitEpoch.Add(time.Duration(value) * time.Second)
+}
+
+// fieldDuration reads a time field stored in milliseconds (scale 1000)
+func (m Message) fieldDuration(field byte) time.Duration {
+	value, ok := m.Fields[field].Uint()
+	if !ok {
+		return 0
+	}
+	return time.Duration(value) * time.Millisecond
+}
@@ -0,0 +1,385 @@
+package fit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+var (
+	ErrInvalidHeader      = errors.New("fit: invalid file header")
+	ErrHeaderCRC          = errors.New("fit: header checksum mismatch")
+	ErrFileCRC            = errors.New("fit: file checksum mismatch")
+	ErrMissingDefinition  = errors.New("fit: data message without definition")
+	ErrUnexpectedEndOfFit = errors.New("fit: unexpected end of data")
+)
+
+const (
+	headerSizeLegacy = 12
+	headerSizeFull   = 14
+
+	recordHeaderCompressed  = 0x80
+	recordHeaderDefinition  = 0x40
+	recordHeaderDeveloper   = 0x20
+	recordHeaderLocalMask   = 0x0F
+	compressedLocalMask     = 0x60
+	compressedTimeOffsetMax = 0x1F
+
+	fieldNumTimestamp = 253
+)
+
+// BaseType identifies how the bytes of a field are encoded
+type BaseType byte
+
+const (
+	BaseEnum    BaseType = 0x00
+	BaseSint8   BaseType = 0x01
+	BaseUint8   BaseType = 0x02
+	BaseSint16  BaseType = 0x83
+	BaseUint16  BaseType = 0x84
+	BaseSint32  BaseType = 0x85
+	BaseUint32  BaseType = 0x86
+	BaseString  BaseType = 0x07
+	BaseFloat32 BaseType = 0x88
+	BaseFloat64 BaseType = 0x89
+	BaseUint8z  BaseType = 0x0A
+	BaseUint16z BaseType = 0x8B
+	BaseUint32z BaseType = 0x8C
+	BaseByte    BaseType = 0x0D
+	BaseSint64  BaseType = 0x8E
+	BaseUint64  BaseType = 0x8F
+	BaseUint64z BaseType = 0x90
+)
+
+// Header is the FIT file header
+type Header struct {
+	Size            byte
+	ProtocolVersion byte
+	ProfileVersion  uint16
+	DataSize        uint32
+	CRC             uint16
+}
+
+// Value is the raw content of a single field in a data message
+type Value struct {
+	Type      BaseType
+	BigEndian bool
+	Data      []byte
+}
+
+// Message is a decoded data message, keyed by field definition number
+type Message struct {
+	Global uint16
+	Fields map[byte]Value
+}
+
+// File is the result of decoding a FIT file
+type File struct {
+	Header   Header
+	Messages []Message
+}
+
+type fieldDefinition struct {
+	num      byte
+	size     byte
+	baseType BaseType
+}
+
+type definition struct {
+	global       uint16
+	bigEndian    bool
+	fields       []fieldDefinition
+	developerLen int
+}
+
+// Decode reads a complete FIT file, verifying the header and file checksums and
+// decoding every data message. Developer fields are skipped.
+func Decode(r io.Reader) (*File, error) {
+	headerSize := make([]byte, 1)
+	if _, err := io.ReadFull(r, headerSize); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if headerSize[0] != headerSizeLegacy && headerSize[0] != headerSizeFull {
+		return nil, ErrInvalidHeader
+	}
+
+	header := make([]byte, headerSize[0])
+	header[0] = headerSize[0]
+	if _, err := io.ReadFull(r, header[1:]); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if string(header[8:12]) != ".FIT" {
+		return nil, ErrInvalidHeader
+	}
+
+	file := &File{
+		Header: Header{
+			Size:            header[0],
+			ProtocolVersion: header[1],
+			ProfileVersion:  binary.LittleEndian.Uint16(header[2:4]),
+			DataSize:        binary.LittleEndian.Uint32(header[4:8]),
+		},
+	}
+
+	if header[0] == headerSizeFull {
+		file.Header.CRC = binary.LittleEndian.Uint16(header[12:14])
+		// A zero header checksum means the writer did not compute one
+		if file.Header.CRC != 0 && file.Header.CRC != CRC(0, header[:12]) {
+			return nil, ErrHeaderCRC
+		}
+	}
+
+	// Read through a limit so a corrupt data size cannot force a huge allocation up front
+	body, err := io.ReadAll(io.LimitReader(r, int64(file.Header.DataSize)+2))
+	if err != nil || len(body) != int(file.Header.DataSize)+2 {
+		return nil, ErrUnexpectedEndOfFit
+	}
+
+	data := body[:file.Header.DataSize]
+	fileCRC := binary.LittleEndian.Uint16(body[file.Header.DataSize:])
+	if CRC(CRC(0, header), data) != fileCRC {
+		return nil, ErrFileCRC
+	}
+
+	messages, err := decodeRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	file.Messages = messages
+
+	return file, nil
+}
+
+func decodeRecords(data []byte) ([]Message, error) {
+	var (
+		definitions   [16]*definition
+		messages      []Message
+		lastTimestamp uint32
+		pos           int
+	)
+
+	for pos < len(data) {
+		recordHeader := data[pos]
+		pos++
+
+		// Compressed timestamp header: a data message whose timestamp is an offset from the last one seen
+		if recordHeader&recordHeaderCompressed != 0 {
+			local := (recordHeader & compressedLocalMask) >> 5
+			offset := uint32(recordHeader & compressedTimeOffsetMax)
+
+			timestamp := (lastTimestamp &^ compressedTimeOffsetMax) | offset
+			if offset < lastTimestamp&compressedTimeOffsetMax {
+				timestamp += compressedTimeOffsetMax + 1
+			}
+			lastTimestamp = timestamp
+
+			def := definitions[local]
+			if def == nil {
+				return nil, ErrMissingDefinition
+			}
+
+			msg, n, err := decodeMessage(def, data[pos:])
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+
+			ts := make([]byte, 4)
+			binary.LittleEndian.PutUint32(ts, timestamp)
+			msg.Fields[fieldNumTimestamp] = Value{Type: BaseUint32, Data: ts}
+			messages = append(messages, msg)
+			continue
+		}
+
+		local := recordHeader & recordHeaderLocalMask
+
+		if recordHeader&recordHeaderDefinition != 0 {
+			def, n, err := decodeDefinition(data[pos:], recordHeader&recordHeaderDeveloper != 0)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+			definitions[local] = def
+			continue
+		}
+
+		def := definitions[local]
+		if def == nil {
+			return nil, ErrMissingDefinition
+		}
+
+		msg, n, err := decodeMessage(def, data[pos:])
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+
+		if ts, ok := msg.Fields[fieldNumTimestamp].Uint(); ok {
+			lastTimestamp = uint32(ts)
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+func decodeDefinition(data []byte, developer bool) (*definition, int, error) {
+	// reserved, architecture, global message number (2), number of fields
+	if len(data) < 5 {
+		return nil, 0, ErrUnexpectedEndOfFit
+	}
+
+	def := &definition{bigEndian: data[1] == 1}
+	if def.bigEndian {
+		def.global = binary.BigEndian.Uint16(data[2:4])
+	} else {
+		def.global = binary.LittleEndian.Uint16(data[2:4])
+	}
+
+	numFields := int(data[4])
+	pos := 5
+	if len(data) < pos+numFields*3 {
+		return nil, 0, ErrUnexpectedEndOfFit
+	}
+	for i := 0; i < numFields; i++ {
+		def.fields = append(def.fields, fieldDefinition{
+			num:      data[pos],
+			size:     data[pos+1],
+			baseType: BaseType(data[pos+2]),
+		})
+		pos += 3
+	}
+
+	if developer {
+		if len(data) < pos+1 {
+			return nil, 0, ErrUnexpectedEndOfFit
+		}
+		numDevFields := int(data[pos])
+		pos++
+		if len(data) < pos+numDevFields*3 {
+			return nil, 0, ErrUnexpectedEndOfFit
+		}
+		for i := 0; i < numDevFields; i++ {
+			def.developerLen += int(data[pos+1])
+			pos += 3
+		}
+	}
+
+	return def, pos, nil
+}
+
+func decodeMessage(def *definition, data []byte) (Message, int, error) {
+	msg := Message{
+		Global: def.global,
+		Fields: make(map[byte]Value, len(def.fields)),
+	}
+
+	pos := 0
+	for _, field := range def.fields {
+		end := pos + int(field.size)
+		if end > len(data) {
+			return Message{}, 0, ErrUnexpectedEndOfFit
+		}
+		msg.Fields[field.num] = Value{
+			Type:      field.baseType,
+			BigEndian: def.bigEndian,
+			Data:      data[pos:end],
+		}
+		pos = end
+	}
+
+	pos += def.developerLen
+	if pos > len(data) {
+		return Message{}, 0, ErrUnexpectedEndOfFit
+	}
+
+	return msg, pos, nil
+}
+
+func (v Value) order() binary.ByteOrder {
+	if v.BigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// Uint returns the first element of an unsigned integer or enum field.
+// ok is false when the field is missing, has another type, or holds the invalid value.
+func (v Value) Uint() (uint64, bool) {
+	var value, invalid uint64
+	switch v.Type {
+	case BaseEnum, BaseUint8, BaseByte:
+		if len(v.Data) < 1 {
+			return 0, false
+		}
+		value, invalid = uint64(v.Data[0]), math.MaxUint8
+	case BaseUint8z:
+		if len(v.Data) < 1 {
+			return 0, false
+		}
+		value, invalid = uint64(v.Data[0]), 0
+	case BaseUint16, BaseUint16z:
+		if len(v.Data) < 2 {
+			return 0, false
+		}
+		value, invalid = uint64(v.order().Uint16(v.Data)), math.MaxUint16
+		if v.Type == BaseUint16z {
+			invalid = 0
+		}
+	case BaseUint32, BaseUint32z:
+		if len(v.Data) < 4 {
+			return 0, false
+		}
+		value, invalid = uint64(v.order().Uint32(v.Data)), math.MaxUint32
+		if v.Type == BaseUint32z {
+			invalid = 0
+		}
+	case BaseUint64, BaseUint64z:
+		if len(v.Data) < 8 {
+			return 0, false
+		}
+		value, invalid = v.order().Uint64(v.Data), math.MaxUint64
+		if v.Type == BaseUint64z {
+			invalid = 0
+		}
+	default:
+		return 0, false
+	}
+
+	if value == invalid {
+		return 0, false
+	}
+	return value, true
+}
+
+// Int returns the first element of a signed integer field.
+// ok is false when the field is missing, has another type, or holds the invalid value.
+func (v Value) Int() (int64, bool) {
+	switch v.Type {
+	case BaseSint8:
+		if len(v.Data) < 1 || v.Data[0] == math.MaxInt8 {
+			return 0, false
+		}
+		return int64(int8(v.Data[0])), true
+	case BaseSint16:
+		if len(v.Data) < 2 {
+			return 0, false
+		}
+		value := int16(v.order().Uint16(v.Data))
+		return int64(value), value != math.MaxInt16
+	case BaseSint32:
+		if len(v.Data) < 4 {
+			return 0, false
+		}
+		value := int32(v.order().Uint32(v.Data))
+		return int64(value), value != math.MaxInt32
+	case BaseSint64:
+		if len(v.Data) < 8 {
+			return 0, false
+		}
+		value := int64(v.order().Uint64(v.Data))
+		return value, value != math.MaxInt64
+	}
+	return 0, false
+}
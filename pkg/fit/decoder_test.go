@@ -0,0 +1,178 @@
+package fit
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("open fixture %s: %v", name, err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+func TestCRC(t *testing.T) {
+	// CRC-16/ARC check value
+	if got := CRC(0, []byte("123456789")); got != 0xBB3D {
+		t.Fatalf("CRC = %#04x, want 0xbb3d", got)
+	}
+}
+
+func TestDecodeActivity(t *testing.T) {
+	activity, err := DecodeActivity(openFixture(t, "running.fit"))
+	if err != nil {
+		t.Fatalf("DecodeActivity: %v", err)
+	}
+
+	start := time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)
+
+	if len(activity.Sessions) != 1 {
+		t.Fatalf("sessions = %d, want 1", len(activity.Sessions))
+	}
+	session := activity.Sessions[0]
+	if session.Sport != "running" {
+		t.Errorf("sport = %q, want running", session.Sport)
+	}
+	if !session.StartTime.Equal(start) {
+		t.Errorf("start time = %v, want %v", session.StartTime, start)
+	}
+	if session.TotalTimerTime != 300*time.Second || session.TotalElapsedTime != 310*time.Second {
+		t.Errorf("timer/elapsed = %v/%v, want 5m0s/5m10s", session.TotalTimerTime, session.TotalElapsedTime)
+	}
+	if !almostEqual(session.TotalDistance, 1000) {
+		t.Errorf("distance = %v, want 1000", session.TotalDistance)
+	}
+	if session.TotalAscent != 13 || session.TotalCalories != 85 {
+		t.Errorf("ascent/calories = %v/%v, want 13/85", session.TotalAscent, session.TotalCalories)
+	}
+	if session.AvgHeartRate != 141 || session.MaxHeartRate != 155 {
+		t.Errorf("heart rate avg/max = %d/%d, want 141/155", session.AvgHeartRate, session.MaxHeartRate)
+	}
+
+	if len(activity.Laps) != 2 {
+		t.Fatalf("laps = %d, want 2", len(activity.Laps))
+	}
+	lap := activity.Laps[1]
+	if !lap.StartTime.Equal(start.Add(150*time.Second)) || lap.TotalTimerTime != 150*time.Second {
+		t.Errorf("second lap start/timer = %v/%v", lap.StartTime, lap.TotalTimerTime)
+	}
+	if !almostEqual(lap.TotalDistance, 500) || lap.AvgHeartRate != 152 || lap.TotalAscent != 6 {
+		t.Errorf("second lap distance/avg hr/ascent = %v/%d/%v", lap.TotalDistance, lap.AvgHeartRate, lap.TotalAscent)
+	}
+
+	if len(activity.Records) != 6 {
+		t.Fatalf("records = %d, want 6", len(activity.Records))
+	}
+
+	first := activity.Records[0]
+	if !first.HasPosition || !almostEqual(first.Latitude, 52.52) || !almostEqual(first.Longitude, 13.405) {
+		t.Errorf("first record position = %v,%v (%v)", first.Latitude, first.Longitude, first.HasPosition)
+	}
+	if !first.HasAltitude || !almostEqual(first.Altitude, 34) || first.HeartRate != 120 {
+		t.Errorf("first record altitude/hr = %v/%d", first.Altitude, first.HeartRate)
+	}
+
+	// Records 1 and 3 use compressed timestamp headers
+	for i, want := range []time.Duration{0, 20, 40, 60, 80, 100} {
+		if got := activity.Records[i].Timestamp; !got.Equal(start.Add(want * time.Second)) {
+			t.Errorf("record %d timestamp = %v, want %v", i, got, start.Add(want*time.Second))
+		}
+	}
+
+	if !almostEqual(activity.Records[3].Distance, 600) || activity.Records[3].HeartRate != 150 {
+		t.Errorf("record 3 distance/hr = %v/%d", activity.Records[3].Distance, activity.Records[3].HeartRate)
+	}
+
+	// The last record carries invalid position, altitude and heart rate values
+	last := activity.Records[5]
+	if last.HasPosition || last.HasAltitude || last.HeartRate != 0 {
+		t.Errorf("last record should have no position, altitude or heart rate: %+v", last)
+	}
+	if !last.HasDistance || !almostEqual(last.Distance, 1000) {
+		t.Errorf("last record distance = %v", last.Distance)
+	}
+}
+
+func TestDecodeLegacyHeaderBigEndian(t *testing.T) {
+	file, err := Decode(openFixture(t, "cycling_legacy_header.fit"))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if file.Header.Size != 12 || file.Header.CRC != 0 {
+		t.Errorf("header = %+v, want 12-byte header without checksum", file.Header)
+	}
+
+	activity, err := DecodeActivity(openFixture(t, "cycling_legacy_header.fit"))
+	if err != nil {
+		t.Fatalf("DecodeActivity: %v", err)
+	}
+	if len(activity.Sessions) != 1 || activity.Sessions[0].Sport != "cycling" {
+		t.Fatalf("sessions = %+v, want one cycling session", activity.Sessions)
+	}
+	if activity.Sessions[0].TotalTimerTime != 20*time.Minute || !almostEqual(activity.Sessions[0].TotalDistance, 2220) {
+		t.Errorf("session timer/distance = %v/%v", activity.Sessions[0].TotalTimerTime, activity.Sessions[0].TotalDistance)
+	}
+	if len(activity.Records) != 3 {
+		t.Fatalf("records = %d, want 3", len(activity.Records))
+	}
+	record := activity.Records[1]
+	if !record.HasAltitude || !almostEqual(record.Altitude, 30) {
+		t.Errorf("enhanced altitude = %v, want 30", record.Altitude)
+	}
+	if !almostEqual(record.Latitude, -33.8588) || !almostEqual(record.Distance, 1110) {
+		t.Errorf("record latitude/distance = %v/%v", record.Latitude, record.Distance)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		data    []byte
+		want    error
+	}{
+		{name: "corrupt checksum", fixture: "corrupt_crc.fit", want: ErrFileCRC},
+		{name: "truncated", fixture: "truncated.fit", want: ErrUnexpectedEndOfFit},
+		{name: "not a fit file", data: []byte("<?xml version=\"1.0\"?><gpx></gpx>"), want: ErrInvalidHeader},
+		{name: "empty", data: []byte{}, want: ErrInvalidHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.fixture != "" {
+				_, err = Decode(openFixture(t, tt.fixture))
+			} else {
+				_, err = Decode(bytes.NewReader(tt.data))
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHeaderCRC(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "running.fit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[12] ^= 0xFF
+
+	if _, err := Decode(bytes.NewReader(data)); !errors.Is(err, ErrHeaderCRC) {
+		t.Fatalf("Decode error = %v, want %v", err, ErrHeaderCRC)
+	}
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// earthRadiusMeters is the mean Earth radius used for distances and projections
+const earthRadiusMeters = 6371008.8
+
+// Point is a position on a recorded route. Time, elevation and heart rate are optional.
+type Point struct {
+	Latitude     float64
+	Longitude    float64
+	Elevation    float64
+	HasElevation bool
+	Time         time.Time
+	HeartRate    int
+}
+
+// Haversine returns the great-circle distance in meters between two coordinates
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadiusMeters * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// BoundingBox is the smallest latitude/longitude rectangle containing a route
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Bounds returns the bounding box of the points, or false when there are none
+func Bounds(points []Point) (BoundingBox, bool) {
+	if len(points) == 0 {
+		return BoundingBox{}, false
+	}
+
+	box := BoundingBox{
+		MinLatitude:  points[0].Latitude,
+		MinLongitude: points[0].Longitude,
+		MaxLatitude:  points[0].Latitude,
+		MaxLongitude: points[0].Longitude,
+	}
+	for _, p := range points[1:] {
+		box.MinLatitude = math.Min(box.MinLatitude, p.Latitude)
+		box.MinLongitude = math.Min(box.MinLongitude, p.Longitude)
+		box.MaxLatitude = math.Max(box.MaxLatitude, p.Latitude)
+		box.MaxLongitude = math.Max(box.MaxLongitude, p.Longitude)
+	}
+	return box, true
+}
+
+// Simplify reduces a route with the Douglas-Peucker algorithm, dropping every point
+// that lies closer than toleranceMeters to the simplified line. The first and last
+// points are always kept; a non-positive tolerance returns the route unchanged.
+func Simplify(points []Point, toleranceMeters float64) []Point {
+	if toleranceMeters <= 0 || len(points) < 3 {
+		return points
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	// Iterative rather than recursive so long tracks cannot exhaust the stack
+	type segment struct{ first, last int }
+	stack := []segment{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		seg := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		maxDistance, index := 0.0, -1
+		for i := seg.first + 1; i < seg.last; i++ {
+			d := segmentDistance(points[i], points[seg.first], points[seg.last])
+			if d > maxDistance {
+				maxDistance, index = d, i
+			}
+		}
+
+		if index != -1 && maxDistance > toleranceMeters {
+			keep[index] = true
+			stack = append(stack, segment{seg.first, index}, segment{index, seg.last})
+		}
+	}
+
+	simplified := make([]Point, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance returns the distance in meters from p to the segment a-b, using an
+// equirectangular projection around a which is accurate at route scale
+func segmentDistance(p, a, b Point) float64 {
+	cosLat := math.Cos(a.Latitude * math.Pi / 180)
+	project := func(q Point) (float64, float64) {
+		x := (q.Longitude - a.Longitude) * math.Pi / 180 * cosLat * earthRadiusMeters
+		y := (q.Latitude - a.Latitude) * math.Pi / 180 * earthRadiusMeters
+		return x, y
+	}
+
+	px, py := project(p)
+	bx, by := project(b)
+
+	lengthSquared := bx*bx + by*by
+	if lengthSquared == 0 {
+		return math.Hypot(px, py)
+	}
+
+	t := math.Max(0, math.Min(1, (px*bx+py*by)/lengthSquared))
+	return math.Hypot(px-t*bx, py-t*by)
+}
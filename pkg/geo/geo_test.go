@@ -0,0 +1,183 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEncodePolyline(t *testing.T) {
+	// Example from the encoded polyline algorithm format documentation
+	points := []Point{
+		{Latitude: 38.5, Longitude: -120.2},
+		{Latitude: 40.7, Longitude: -120.95},
+		{Latitude: 43.252, Longitude: -126.453},
+	}
+
+	want := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if got := EncodePolyline(points); got != want {
+		t.Fatalf("EncodePolyline = %q, want %q", got, want)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	// A straight line north with a single 50 m detour east in the middle
+	points := []Point{
+		{Latitude: 52.0000, Longitude: 13.0},
+		{Latitude: 52.0010, Longitude: 13.0},
+		{Latitude: 52.0020, Longitude: 13.0},
+		{Latitude: 52.0030, Longitude: 13.00073},
+		{Latitude: 52.0040, Longitude: 13.0},
+		{Latitude: 52.0050, Longitude: 13.0},
+	}
+
+	if got := Simplify(points, 0); len(got) != len(points) {
+		t.Errorf("tolerance 0 kept %d points, want %d", len(got), len(points))
+	}
+
+	got := Simplify(points, 10)
+	if len(got) != 5 {
+		t.Fatalf("tolerance 10m kept %d points, want 5", len(got))
+	}
+	if got[0] != points[0] || got[len(got)-1] != points[len(points)-1] {
+		t.Errorf("endpoints were not kept")
+	}
+
+	if got := Simplify(points, 100); len(got) != 2 {
+		t.Errorf("tolerance 100m kept %d points, want 2", len(got))
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	// One hundredth of a degree along a meridian
+	if got := Haversine(0, 0, 0.01, 0); math.Abs(got-1111.95) > 0.01 {
+		t.Errorf("Haversine = %v, want 1111.95", got)
+	}
+	if got := Haversine(52.52, 13.405, 52.52, 13.405); got != 0 {
+		t.Errorf("Haversine of the same point = %v, want 0", got)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	if _, ok := Bounds(nil); ok {
+		t.Errorf("Bounds of no points should not be ok")
+	}
+
+	box, ok := Bounds([]Point{
+		{Latitude: 1, Longitude: 5},
+		{Latitude: -2, Longitude: 7},
+		{Latitude: 3, Longitude: -4},
+	})
+	want := BoundingBox{MinLatitude: -2, MinLongitude: -4, MaxLatitude: 3, MaxLongitude: 7}
+	if !ok || box != want {
+		t.Errorf("Bounds = %+v, want %+v", box, want)
+	}
+}
+
+func TestTrackRoundTrip(t *testing.T) {
+	start := time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Latitude: 52.520008, Longitude: 13.404954, Elevation: 34.5, HasElevation: true, Time: start, HeartRate: 142},
+		{Latitude: 52.520512, Longitude: 13.405871, Elevation: 35.1, HasElevation: true, Time: start.Add(1500 * time.Millisecond)},
+		{Latitude: 52.521003, Longitude: 13.406500},
+		{Latitude: -33.868820, Longitude: 151.209290, Elevation: -2.3, HasElevation: true, Time: start.Add(time.Hour)},
+	}
+
+	decoded, err := DecodeTrack(EncodeTrack(points))
+	if err != nil {
+		t.Fatalf("DecodeTrack: %v", err)
+	}
+	if len(decoded) != len(points) {
+		t.Fatalf("decoded %d points, want %d", len(decoded), len(points))
+	}
+
+	for i, p := range decoded {
+		want := points[i]
+		if math.Abs(p.Latitude-want.Latitude) > 1e-6 || math.Abs(p.Longitude-want.Longitude) > 1e-6 {
+			t.Errorf("point %d position = %v,%v, want %v,%v", i, p.Latitude, p.Longitude, want.Latitude, want.Longitude)
+		}
+		if p.HasElevation != want.HasElevation || math.Abs(p.Elevation-want.Elevation) > 0.05 {
+			t.Errorf("point %d elevation = %v (%v), want %v (%v)", i, p.Elevation, p.HasElevation, want.Elevation, want.HasElevation)
+		}
+		if !p.Time.Equal(want.Time) {
+			t.Errorf("point %d time = %v, want %v", i, p.Time, want.Time)
+		}
+		if p.HeartRate != want.HeartRate {
+			t.Errorf("point %d heart rate = %v, want %v", i, p.HeartRate, want.HeartRate)
+		}
+	}
+}
+
+func TestDecodeTrackInvalid(t *testing.T) {
+	encoded := EncodeTrack([]Point{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}})
+
+	for name, data := range map[string][]byte{
+		"empty":       nil,
+		"bad version": append([]byte{99}, encoded[1:]...),
+		"truncated":   encoded[:len(encoded)-1],
+	} {
+		if _, err := DecodeTrack(data); !errors.Is(err, ErrInvalidTrack) {
+			t.Errorf("%s: err = %v, want ErrInvalidTrack", name, err)
+		}
+	}
+}
+
+func TestSplits(t *testing.T) {
+	start := time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)
+
+	// 0.0045 degrees of latitude is about 500 m; 2.5 km north at a steady 5 min/km, climbing 10 m per point
+	points := make([]Point, 6)
+	for i := range points {
+		points[i] = Point{
+			Latitude:     float64(i) * 0.0045,
+			Elevation:    float64(i) * 10,
+			HasElevation: true,
+			Time:         start.Add(time.Duration(i) * 150 * time.Second),
+			HeartRate:    140 + i*2,
+		}
+	}
+	segment := Distance(points[0], points[1])
+
+	splits := Splits(points, 1000)
+	if len(splits) != 3 {
+		t.Fatalf("splits = %d, want 3", len(splits))
+	}
+
+	for i, split := range splits[:2] {
+		if split.DistanceMeters != 1000 {
+			t.Errorf("split %d distance = %v, want 1000", i, split.DistanceMeters)
+		}
+		wantDuration := time.Duration(1000 / segment * 150 * float64(time.Second))
+		if diff := split.Duration - wantDuration; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("split %d duration = %v, want %v", i, split.Duration, wantDuration)
+		}
+		if math.Abs(split.ElevationChange-1000/segment*10) > 0.01 || !split.HasElevation {
+			t.Errorf("split %d elevation change = %v", i, split.ElevationChange)
+		}
+	}
+
+	last := splits[2]
+	if math.Abs(last.DistanceMeters-(5*segment-2000)) > 0.01 {
+		t.Errorf("last split distance = %v, want %v", last.DistanceMeters, 5*segment-2000)
+	}
+	if splits[0].AvgHeartRate != 141 {
+		t.Errorf("first split heart rate = %v, want 141", splits[0].AvgHeartRate)
+	}
+
+	var total time.Duration
+	for _, split := range splits {
+		total += split.Duration
+	}
+	if total != 750*time.Second {
+		t.Errorf("total duration = %v, want 12m30s", total)
+	}
+
+	// Without timestamps there is nothing to split
+	for i := range points {
+		points[i].Time = time.Time{}
+	}
+	if got := Splits(points, 1000); got != nil {
+		t.Errorf("splits without time = %d, want none", len(got))
+	}
+}
@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// Split is one fixed-distance segment of a route. The last split of a route is
+// usually shorter than the rest.
+type Split struct {
+	DistanceMeters  float64
+	Duration        time.Duration
+	ElevationChange float64
+	HasElevation    bool
+	AvgHeartRate    int
+}
+
+// Distance returns the great-circle distance in meters between two points
+func Distance(a, b Point) float64 {
+	return Haversine(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+}
+
+// Splits cuts a route into consecutive segments of splitMeters. Time and elevation
+// are interpolated where a boundary falls between two points. Points without a
+// timestamp are ignored, so a route recorded without time has no splits.
+func Splits(points []Point, splitMeters float64) []Split {
+	if splitMeters <= 0 {
+		return nil
+	}
+
+	timed := make([]Point, 0, len(points))
+	for _, p := range points {
+		if !p.Time.IsZero() {
+			timed = append(timed, p)
+		}
+	}
+	if len(timed) < 2 {
+		return nil
+	}
+
+	// Fill elevation gaps with the nearest known elevation so interpolation stays continuous
+	hasElevation := false
+	for i := range timed {
+		if timed[i].HasElevation {
+			if !hasElevation {
+				for j := 0; j < i; j++ {
+					timed[j].Elevation = timed[i].Elevation
+				}
+			}
+			hasElevation = true
+		} else if i > 0 {
+			timed[i].Elevation = timed[i-1].Elevation
+		}
+	}
+
+	var (
+		splits []Split
+		// State of the split being built, starting at the previous boundary
+		startTime      = timed[0].Time
+		startElevation = timed[0].Elevation
+		covered        float64
+		heartRateSum   int
+		heartRateCount int
+	)
+
+	addHeartRate := func(p Point) {
+		if p.HeartRate > 0 {
+			heartRateSum += p.HeartRate
+			heartRateCount++
+		}
+	}
+	finish := func(distance float64, end time.Time, endElevation float64) {
+		split := Split{
+			DistanceMeters:  distance,
+			Duration:        end.Sub(startTime),
+			ElevationChange: endElevation - startElevation,
+			HasElevation:    hasElevation,
+		}
+		if heartRateCount > 0 {
+			split.AvgHeartRate = int(math.Round(float64(heartRateSum) / float64(heartRateCount)))
+		}
+		splits = append(splits, split)
+
+		startTime, startElevation = end, endElevation
+		covered, heartRateSum, heartRateCount = 0, 0, 0
+	}
+
+	addHeartRate(timed[0])
+	for i := 1; i < len(timed); i++ {
+		prev, p := timed[i-1], timed[i]
+		segment := Distance(prev, p)
+
+		// A single segment can cross several boundaries when points are far apart
+		walked := 0.0
+		for segment-walked >= splitMeters-covered {
+			walked += splitMeters - covered
+			fraction := walked / segment
+			at := prev.Time.Add(time.Duration(fraction * float64(p.Time.Sub(prev.Time))))
+			finish(splitMeters, at, prev.Elevation+fraction*(p.Elevation-prev.Elevation))
+		}
+
+		covered += segment - walked
+		addHeartRate(p)
+	}
+
+	// Keep the remainder as a final partial split unless it is just rounding noise
+	if covered >= 1 {
+		last := timed[len(timed)-1]
+		finish(covered, last.Time, last.Elevation)
+	}
+
+	return splits
+}
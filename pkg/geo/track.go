@@ -0,0 +1,134 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// trackEncodingVersion is written as the first byte of every encoded track
+const trackEncodingVersion = 1
+
+const (
+	flagHasElevation = 1 << iota
+	flagHasTime
+	flagHasHeartRate
+)
+
+var ErrInvalidTrack = errors.New("invalid encoded track")
+
+// EncodeTrack packs a route into a compact binary form for storage. Coordinates are
+// kept at 1e-6 degrees, elevation at decimeters and time at milliseconds, each delta
+// encoded against the previous point as a variable-length integer. Heart rate is
+// stored as is.
+func EncodeTrack(points []Point) []byte {
+	buf := make([]byte, 0, 2+len(points)*8)
+	buf = append(buf, trackEncodingVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(points)))
+
+	var prevLat, prevLon, prevElevation, prevTime int64
+	for _, p := range points {
+		var flags byte
+		if p.HasElevation {
+			flags |= flagHasElevation
+		}
+		if !p.Time.IsZero() {
+			flags |= flagHasTime
+		}
+		if p.HeartRate > 0 {
+			flags |= flagHasHeartRate
+		}
+		buf = append(buf, flags)
+
+		lat := int64(math.Round(p.Latitude * 1e6))
+		lon := int64(math.Round(p.Longitude * 1e6))
+		buf = binary.AppendVarint(buf, lat-prevLat)
+		buf = binary.AppendVarint(buf, lon-prevLon)
+		prevLat, prevLon = lat, lon
+
+		if p.HasElevation {
+			elevation := int64(math.Round(p.Elevation * 10))
+			buf = binary.AppendVarint(buf, elevation-prevElevation)
+			prevElevation = elevation
+		}
+		if !p.Time.IsZero() {
+			millis := p.Time.UnixMilli()
+			buf = binary.AppendVarint(buf, millis-prevTime)
+			prevTime = millis
+		}
+		if p.HeartRate > 0 {
+			buf = binary.AppendUvarint(buf, uint64(p.HeartRate))
+		}
+	}
+	return buf
+}
+
+// DecodeTrack unpacks a route written by EncodeTrack
+func DecodeTrack(data []byte) ([]Point, error) {
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil || version != trackEncodingVersion {
+		return nil, ErrInvalidTrack
+	}
+
+	count, err := binary.ReadUvarint(r)
+	// Every point takes at least three bytes, which bounds the allocation below
+	if err != nil || count > uint64(len(data))/3 {
+		return nil, ErrInvalidTrack
+	}
+
+	points := make([]Point, 0, count)
+	var prevLat, prevLon, prevElevation, prevTime int64
+	for i := uint64(0); i < count; i++ {
+		flags, err := r.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidTrack
+		}
+
+		dLat, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, ErrInvalidTrack
+		}
+		dLon, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, ErrInvalidTrack
+		}
+		prevLat, prevLon = prevLat+dLat, prevLon+dLon
+
+		p := Point{
+			Latitude:  float64(prevLat) / 1e6,
+			Longitude: float64(prevLon) / 1e6,
+		}
+
+		if flags&flagHasElevation != 0 {
+			dElevation, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, ErrInvalidTrack
+			}
+			prevElevation += dElevation
+			p.Elevation, p.HasElevation = float64(prevElevation)/10, true
+		}
+		if flags&flagHasTime != 0 {
+			dTime, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, ErrInvalidTrack
+			}
+			prevTime += dTime
+			p.Time = time.UnixMilli(prevTime).UTC()
+		}
+		if flags&flagHasHeartRate != 0 {
+			heartRate, err := binary.ReadUvarint(r)
+			if err != nil || heartRate > math.MaxUint8 {
+				return nil, ErrInvalidTrack
+			}
+			p.HeartRate = int(heartRate)
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
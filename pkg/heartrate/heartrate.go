@@ -0,0 +1,322 @@
+// Package heartrate stores heart rate recordings compactly and works out how long was
+// spent in each training zone and how hard a recording was as a training impulse.
+package heartrate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// MinBPM and MaxBPM bound the readings kept from a recording. Anything outside them is
+	// a sensor dropout or glitch rather than a heart rate.
+	MinBPM = 25
+	MaxBPM = 250
+
+	// ZoneCount is the number of training zones
+	ZoneCount = 5
+
+	// DefaultRestingBPM stands in for the resting heart rate when it is not known
+	DefaultRestingBPM = 60
+
+	// MaxSampleGap is the longest interval between two readings that is still counted as
+	// recorded time. Longer gaps are pauses or lost contact with the sensor.
+	MaxSampleGap = 30 * time.Second
+)
+
+// seriesEncodingVersion is written as the first byte of every encoded series
+const seriesEncodingVersion = 1
+
+var ErrInvalidSeries = errors.New("invalid encoded heart rate series")
+
+// trimpWeighting is the exponent of Banister's TRIMP weighting. It is the value fitted
+// for men; the one for women, 1.67, weighs hard efforts slightly less.
+const trimpWeighting = 1.92
+
+// zoneLowerBounds are the lower bounds of the zones as fractions of the maximum heart rate
+var zoneLowerBounds = [ZoneCount]float64{0.5, 0.6, 0.7, 0.8, 0.9}
+
+// Sample is a heart rate reading at a point in time
+type Sample struct {
+	Time time.Time
+	BPM  int
+}
+
+// Normalize orders samples by time at one-second resolution, dropping readings outside
+// MinBPM and MaxBPM. Of several readings in the same second the last one is kept.
+func Normalize(samples []Sample) []Sample {
+	normalized := make([]Sample, 0, len(samples))
+	for _, s := range samples {
+		if s.BPM < MinBPM || s.BPM > MaxBPM || s.Time.IsZero() {
+			continue
+		}
+		normalized = append(normalized, Sample{Time: s.Time.Truncate(time.Second).UTC(), BPM: s.BPM})
+	}
+
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].Time.Before(normalized[j].Time)
+	})
+
+	result := normalized[:0]
+	for _, s := range normalized {
+		if n := len(result); n > 0 && result[n-1].Time.Equal(s.Time) {
+			result[n-1] = s
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// Encode packs normalized samples into a compact binary form for storage. The first
+// reading is stored at whole seconds and every later one as the seconds and beats per
+// minute since the previous one, each a variable-length integer, which takes two bytes
+// per reading for a steady once-a-second recording.
+func Encode(samples []Sample) []byte {
+	buf := make([]byte, 0, 12+len(samples)*2)
+	buf = append(buf, seriesEncodingVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(samples)))
+
+	var prevTime, prevBPM int64
+	for i, s := range samples {
+		seconds := s.Time.Unix()
+		if i == 0 {
+			buf = binary.AppendVarint(buf, seconds)
+		} else {
+			buf = binary.AppendUvarint(buf, uint64(seconds-prevTime))
+		}
+		buf = binary.AppendVarint(buf, int64(s.BPM)-prevBPM)
+		prevTime, prevBPM = seconds, int64(s.BPM)
+	}
+	return buf
+}
+
+// Decode unpacks a series written by Encode
+func Decode(data []byte) ([]Sample, error) {
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil || version != seriesEncodingVersion {
+		return nil, ErrInvalidSeries
+	}
+
+	count, err := binary.ReadUvarint(r)
+	// Every reading takes at least two bytes, which bounds the allocation below
+	if err != nil || count > uint64(len(data))/2 {
+		return nil, ErrInvalidSeries
+	}
+
+	samples := make([]Sample, 0, count)
+	var prevTime, prevBPM int64
+	for i := uint64(0); i < count; i++ {
+		if i == 0 {
+			prevTime, err = binary.ReadVarint(r)
+		} else {
+			var delta uint64
+			delta, err = binary.ReadUvarint(r)
+			prevTime += int64(delta)
+		}
+		if err != nil {
+			return nil, ErrInvalidSeries
+		}
+
+		dBPM, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, ErrInvalidSeries
+		}
+		prevBPM += dBPM
+		if prevBPM < 0 || prevBPM > math.MaxUint8 {
+			return nil, ErrInvalidSeries
+		}
+
+		samples = append(samples, Sample{Time: time.Unix(prevTime, 0).UTC(), BPM: int(prevBPM)})
+	}
+
+	return samples, nil
+}
+
+// Downsample reduces samples to at most maxPoints by averaging consecutive runs of
+// readings. Each averaged point keeps the time of the first reading in its run.
+func Downsample(samples []Sample, maxPoints int) []Sample {
+	if maxPoints <= 0 || len(samples) <= maxPoints {
+		return samples
+	}
+
+	size := (len(samples) + maxPoints - 1) / maxPoints
+	points := make([]Sample, 0, maxPoints)
+	for start := 0; start < len(samples); start += size {
+		end := start + size
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		var sum int
+		for _, s := range samples[start:end] {
+			sum += s.BPM
+		}
+		points = append(points, Sample{
+			Time: samples[start].Time,
+			BPM:  int(math.Round(float64(sum) / float64(end-start))),
+		})
+	}
+	return points
+}
+
+// Stats returns the lowest, average and highest reading of samples, or false when
+// there are none. The average is weighted by how long each reading lasted.
+func Stats(samples []Sample) (min, avg, max int, ok bool) {
+	if len(samples) == 0 {
+		return 0, 0, 0, false
+	}
+
+	var weighted, total float64
+	var plain int
+	min, max = samples[0].BPM, samples[0].BPM
+	for i, s := range samples {
+		if s.BPM < min {
+			min = s.BPM
+		}
+		if s.BPM > max {
+			max = s.BPM
+		}
+		plain += s.BPM
+		if d := sampleDuration(samples, i); d > 0 {
+			weighted += float64(s.BPM) * d.Seconds()
+			total += d.Seconds()
+		}
+	}
+
+	if total > 0 {
+		avg = int(math.Round(weighted / total))
+	} else {
+		avg = int(math.Round(float64(plain) / float64(len(samples))))
+	}
+	return min, avg, max, true
+}
+
+// EstimateMaxHR estimates the maximum heart rate for an age with the formula of Tanaka,
+// Monahan and Seals, 208 - 0.7 × age
+func EstimateMaxHR(age int) int {
+	return int(math.Round(208 - 0.7*float64(age)))
+}
+
+// Age returns the age in whole years at the given time of someone born on birthDate
+func Age(birthDate, at time.Time) int {
+	age := at.Year() - birthDate.Year()
+	if at.Month() < birthDate.Month() || (at.Month() == birthDate.Month() && at.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// Zone is a heart rate training zone. It covers MinBPM to MaxBPM inclusive; the top zone
+// also takes anything above the maximum heart rate.
+type Zone struct {
+	Number int
+	MinBPM int
+	MaxBPM int
+}
+
+// Zones derives the five training zones from a maximum heart rate. They start at 50, 60,
+// 70, 80 and 90 percent of it.
+func Zones(maxHR int) [ZoneCount]Zone {
+	var zones [ZoneCount]Zone
+	for i, lower := range zoneLowerBounds {
+		zones[i] = Zone{Number: i + 1, MinBPM: int(math.Round(float64(maxHR) * lower))}
+	}
+	for i := range zones {
+		if i+1 < ZoneCount {
+			zones[i].MaxBPM = zones[i+1].MinBPM - 1
+		} else {
+			zones[i].MaxBPM = maxHR
+		}
+	}
+	return zones
+}
+
+// Distribution is the time spent in each zone. Below is the time spent under the first zone.
+type Distribution struct {
+	Below time.Duration
+	Zones [ZoneCount]time.Duration
+}
+
+// Total returns the time covered by the distribution
+func (d Distribution) Total() time.Duration {
+	total := d.Below
+	for _, z := range d.Zones {
+		total += z
+	}
+	return total
+}
+
+// Add sums another distribution into d
+func (d *Distribution) Add(other Distribution) {
+	d.Below += other.Below
+	for i := range d.Zones {
+		d.Zones[i] += other.Zones[i]
+	}
+}
+
+// TimeInZones works out how long samples stayed in each of the zones. Every reading lasts
+// until the next one, except across gaps longer than MaxSampleGap, which are not counted.
+func TimeInZones(samples []Sample, zones [ZoneCount]Zone) Distribution {
+	var distribution Distribution
+	for i, s := range samples {
+		d := sampleDuration(samples, i)
+		if d <= 0 {
+			continue
+		}
+
+		zone := -1
+		for j := ZoneCount - 1; j >= 0; j-- {
+			if s.BPM >= zones[j].MinBPM {
+				zone = j
+				break
+			}
+		}
+		if zone < 0 {
+			distribution.Below += d
+		} else {
+			distribution.Zones[zone] += d
+		}
+	}
+	return distribution
+}
+
+// TRIMP returns Banister's training impulse of samples: the minutes of every reading
+// weighted by the fraction of heart rate reserve used, which counts hard efforts
+// exponentially more than easy ones
+func TRIMP(samples []Sample, maxHR, restingHR int) float64 {
+	reserve := float64(maxHR - restingHR)
+	if reserve <= 0 {
+		return 0
+	}
+
+	var trimp float64
+	for i, s := range samples {
+		d := sampleDuration(samples, i)
+		if d <= 0 {
+			continue
+		}
+		fraction := math.Min(math.Max(float64(s.BPM-restingHR)/reserve, 0), 1)
+		trimp += d.Minutes() * fraction * 0.64 * math.Exp(trimpWeighting*fraction)
+	}
+	return trimp
+}
+
+// sampleDuration returns how long the reading at index i lasted, zero for the last
+// reading and for one followed by a gap
+func sampleDuration(samples []Sample, i int) time.Duration {
+	if i+1 >= len(samples) {
+		return 0
+	}
+	d := samples[i+1].Time.Sub(samples[i].Time)
+	if d > MaxSampleGap {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,189 @@
+package heartrate
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 5, 1, 7, 30, 0, 0, time.UTC)
+
+// steady returns one reading per interval with the given heart rates
+func steady(interval time.Duration, bpm ...int) []Sample {
+	samples := make([]Sample, len(bpm))
+	for i, b := range bpm {
+		samples[i] = Sample{Time: start.Add(time.Duration(i) * interval), BPM: b}
+	}
+	return samples
+}
+
+func TestNormalize(t *testing.T) {
+	samples := []Sample{
+		{Time: start.Add(2 * time.Second), BPM: 130},
+		{Time: start, BPM: 120},
+		{Time: start.Add(1500 * time.Millisecond), BPM: 0},
+		{Time: start.Add(2*time.Second + 400*time.Millisecond), BPM: 132},
+		{Time: start.Add(3 * time.Second), BPM: 300},
+		{Time: time.Time{}, BPM: 140},
+	}
+
+	got := Normalize(samples)
+	if len(got) != 2 {
+		t.Fatalf("Normalize returned %d samples, want 2: %+v", len(got), got)
+	}
+	if !got[0].Time.Equal(start) || got[0].BPM != 120 {
+		t.Errorf("first sample = %+v", got[0])
+	}
+	if !got[1].Time.Equal(start.Add(2*time.Second)) || got[1].BPM != 132 {
+		t.Errorf("second sample = %+v, want the last reading of the second", got[1])
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	samples := steady(time.Second, 98, 99, 101, 150, 149, 60)
+	samples = append(samples, Sample{Time: start.Add(time.Hour), BPM: 72})
+
+	data := Encode(samples)
+	if len(data) > 2+5+len(samples)*3 {
+		t.Errorf("encoded %d samples into %d bytes", len(samples), len(data))
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if len(decoded) != len(samples) {
+		t.Fatalf("Decode returned %d samples, want %d", len(decoded), len(samples))
+	}
+	for i := range samples {
+		if !decoded[i].Time.Equal(samples[i].Time) || decoded[i].BPM != samples[i].BPM {
+			t.Errorf("sample %d = %+v, want %+v", i, decoded[i], samples[i])
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid := Encode(steady(time.Second, 100, 110))
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{9}, valid[1:]...),
+		"truncated": valid[:len(valid)-1],
+		"count":     {seriesEncodingVersion, 100, 0},
+	} {
+		if _, err := Decode(data); !errors.Is(err, ErrInvalidSeries) {
+			t.Errorf("%s: Decode error = %v, want ErrInvalidSeries", name, err)
+		}
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	samples := steady(time.Second, 100, 102, 110, 112, 120)
+
+	got := Downsample(samples, 2)
+	if len(got) != 2 {
+		t.Fatalf("Downsample returned %d points, want 2", len(got))
+	}
+	if got[0].BPM != 104 || !got[0].Time.Equal(start) {
+		t.Errorf("first point = %+v", got[0])
+	}
+	if got[1].BPM != 116 || !got[1].Time.Equal(start.Add(3*time.Second)) {
+		t.Errorf("second point = %+v", got[1])
+	}
+
+	if got := Downsample(samples, 10); len(got) != len(samples) {
+		t.Errorf("Downsample below the limit returned %d points", len(got))
+	}
+}
+
+func TestStats(t *testing.T) {
+	// 100 lasts one second and 160 three; the final reading lasts nothing
+	samples := []Sample{
+		{Time: start, BPM: 100},
+		{Time: start.Add(time.Second), BPM: 160},
+		{Time: start.Add(4 * time.Second), BPM: 90},
+	}
+
+	min, avg, max, ok := Stats(samples)
+	if !ok || min != 90 || avg != 145 || max != 160 {
+		t.Errorf("Stats = %d, %d, %d, %v", min, avg, max, ok)
+	}
+	if _, _, _, ok := Stats(nil); ok {
+		t.Error("Stats of no samples reported ok")
+	}
+}
+
+func TestEstimateMaxHR(t *testing.T) {
+	if got := EstimateMaxHR(40); got != 180 {
+		t.Errorf("EstimateMaxHR(40) = %d, want 180", got)
+	}
+}
+
+func TestAge(t *testing.T) {
+	birthDate := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+	if got := Age(birthDate, time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)); got != 33 {
+		t.Errorf("Age the day before the birthday = %d, want 33", got)
+	}
+	if got := Age(birthDate, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)); got != 34 {
+		t.Errorf("Age on the birthday = %d, want 34", got)
+	}
+}
+
+func TestZones(t *testing.T) {
+	zones := Zones(190)
+	want := [ZoneCount]Zone{
+		{Number: 1, MinBPM: 95, MaxBPM: 113},
+		{Number: 2, MinBPM: 114, MaxBPM: 132},
+		{Number: 3, MinBPM: 133, MaxBPM: 151},
+		{Number: 4, MinBPM: 152, MaxBPM: 170},
+		{Number: 5, MinBPM: 171, MaxBPM: 190},
+	}
+	if zones != want {
+		t.Errorf("Zones(190) = %+v", zones)
+	}
+}
+
+func TestTimeInZones(t *testing.T) {
+	samples := steady(5*time.Second, 80, 100, 120, 140, 160, 180, 200)
+	// The reading before a pause is not counted
+	samples = append(samples, Sample{Time: samples[len(samples)-1].Time.Add(time.Minute), BPM: 150})
+
+	got := TimeInZones(samples, Zones(190))
+	if got.Below != 5*time.Second {
+		t.Errorf("Below = %v, want 5s", got.Below)
+	}
+	want := [ZoneCount]time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second}
+	if got.Zones != want {
+		t.Errorf("Zones = %v, want %v", got.Zones, want)
+	}
+	if got.Total() != 30*time.Second {
+		t.Errorf("Total = %v, want 30s", got.Total())
+	}
+
+	var sum Distribution
+	sum.Add(got)
+	sum.Add(got)
+	if sum.Total() != time.Minute || sum.Zones[4] != 10*time.Second {
+		t.Errorf("summed distribution = %+v", sum)
+	}
+}
+
+func TestTRIMP(t *testing.T) {
+	// An hour at 60% of a 60 to 180 reserve, 132 bpm
+	samples := make([]Sample, 3601)
+	for i := range samples {
+		samples[i] = Sample{Time: start.Add(time.Duration(i) * time.Second), BPM: 132}
+	}
+
+	got := TRIMP(samples, 180, 60)
+	if got < 72.9 || got > 73.0 {
+		t.Errorf("TRIMP = %v, want about 72.9", got)
+	}
+
+	if got := TRIMP(steady(time.Second, 50, 55, 58), 180, 60); got != 0 {
+		t.Errorf("TRIMP below the resting heart rate = %v, want 0", got)
+	}
+	if got := TRIMP(samples, 60, 60); got != 0 {
+		t.Errorf("TRIMP without a heart rate reserve = %v, want 0", got)
+	}
+}
@@ -0,0 +1,261 @@
+// Package rrule implements the subset of iCalendar recurrence rules (RFC 5545) needed
+// to repeat whole-day events: FREQ of DAILY, WEEKLY or MONTHLY with INTERVAL, COUNT,
+// UNTIL and, for weekly rules, BYDAY. Occurrences are calendar dates, represented as
+// midnight UTC.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a parsed recurrence rule. A zero Count and zero Until mean the rule repeats
+// forever.
+type Rule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10". An
+// "RRULE:" prefix is accepted. UNTIL may be a date (20240131) or a UTC date-time
+// (20240131T000000Z); only its date is used.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("%w: rule is empty", ErrInvalidRule)
+	}
+
+	rule := Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("%w: %q is not NAME=VALUE", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return Rule{}, fmt.Errorf("%w: %s is given more than once", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			if value != Daily && value != Weekly && value != Monthly {
+				return Rule{}, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRule)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > 366 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be between 1 and 366", ErrInvalidRule)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 || count > 1000 {
+				return Rule{}, fmt.Errorf("%w: COUNT must be between 1 and 1000", ErrInvalidRule)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.TrimSpace(day)]
+				if !ok {
+					return Rule{}, fmt.Errorf("%w: BYDAY must list days such as MO,WE,FR", ErrInvalidRule)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		default:
+			return Rule{}, fmt.Errorf("%w: %s is not supported", ErrInvalidRule, name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRule)
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return Rule{}, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRule)
+	}
+
+	// Weeks start on Monday, so sort the days that way and drop repeats
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return mondayOffset(rule.ByDay[i]) < mondayOffset(rule.ByDay[j])
+	})
+	days := rule.ByDay[:0]
+	for i, day := range rule.ByDay {
+		if i == 0 || day != rule.ByDay[i-1] {
+			days = append(days, day)
+		}
+	}
+	rule.ByDay = days
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if len(value) > 8 {
+		until, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: UNTIL must be a date such as 20240131", ErrInvalidRule)
+		}
+		return Date(until), nil
+	}
+	until, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: UNTIL must be a date such as 20240131", ErrInvalidRule)
+	}
+	return until, nil
+}
+
+// Date truncates t to its calendar date at midnight UTC
+func Date(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Bounded reports whether the rule stops repeating at some point
+func (r Rule) Bounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// Between returns the occurrences of the rule starting on start that fall within from
+// and to, both inclusive. With BYDAY, start itself only counts when it is one of the
+// listed days.
+func (r Rule) Between(start, from, to time.Time) []time.Time {
+	from, to = Date(from), Date(to)
+
+	var dates []time.Time
+	r.each(Date(start), to, func(date time.Time) {
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	})
+	return dates
+}
+
+// Last returns the final occurrence of a bounded rule starting on start. It is false
+// for rules that repeat forever or never occur at all.
+func (r Rule) Last(start time.Time) (time.Time, bool) {
+	if !r.Bounded() {
+		return time.Time{}, false
+	}
+
+	var last time.Time
+	var found bool
+	r.each(Date(start), time.Time{}, func(date time.Time) {
+		last, found = date, true
+	})
+	return last, found
+}
+
+// each calls fn with every occurrence in order until the rule ends or, when limit is
+// set, the occurrences pass limit
+func (r Rule) each(start, limit time.Time, fn func(time.Time)) {
+	end := r.Until
+	if !limit.IsZero() && (end.IsZero() || limit.Before(end)) {
+		end = limit
+	}
+	if end.IsZero() && r.Count == 0 {
+		// Unbounded rules are only ever walked up to a limit
+		return
+	}
+
+	emitted := 0
+	emit := func(date time.Time) bool {
+		if !end.IsZero() && date.After(end) {
+			return false
+		}
+		fn(date)
+		emitted++
+		return r.Count == 0 || emitted < r.Count
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case Daily:
+		for date := start; ; date = date.AddDate(0, 0, interval) {
+			if !emit(date) {
+				return
+			}
+		}
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		week := start.AddDate(0, 0, -mondayOffset(start.Weekday()))
+		for ; ; week = week.AddDate(0, 0, 7*interval) {
+			if !end.IsZero() && week.After(end) {
+				return
+			}
+			for _, day := range days {
+				date := week.AddDate(0, 0, mondayOffset(day))
+				if date.Before(start) {
+					continue
+				}
+				if !emit(date) {
+					return
+				}
+			}
+		}
+	case Monthly:
+		year, month, day := start.Date()
+		for k := 0; ; k += interval {
+			first := time.Date(year, month+time.Month(k), 1, 0, 0, 0, 0, time.UTC)
+			if !end.IsZero() && first.After(end) {
+				return
+			}
+			// Months without the start's day of month are skipped, as in RFC 5545
+			date := first.AddDate(0, 0, day-1)
+			if date.Month() != first.Month() {
+				continue
+			}
+			if !emit(date) {
+				return
+			}
+		}
+	}
+}
+
+// mondayOffset returns the number of days from Monday to day
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
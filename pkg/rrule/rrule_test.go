@@ -0,0 +1,158 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, len(dates))
+	for i, d := range dates {
+		formatted[i] = d.Format("2006-01-02")
+	}
+	return formatted
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,MO,MO;COUNT=4")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if rule.Freq != Weekly || rule.Interval != 2 || rule.Count != 4 {
+		t.Errorf("Parse = %+v", rule)
+	}
+	if len(rule.ByDay) != 2 || rule.ByDay[0] != time.Monday || rule.ByDay[1] != time.Friday {
+		t.Errorf("ByDay = %v, want [Monday Friday]", rule.ByDay)
+	}
+
+	rule, err = Parse("freq=daily;until=20240131T235959Z")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if !rule.Until.Equal(date("2024-01-31")) {
+		t.Errorf("Until = %v, want 2024-01-31", rule.Until)
+	}
+
+	invalid := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTH=1",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ",
+	}
+	for _, s := range invalid {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidRule", s, err)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		start    string
+		from, to string
+		want     []string
+	}{
+		{
+			name:  "daily with interval",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: "2024-01-01", from: "2024-01-05", to: "2024-01-13",
+			want: []string{"2024-01-07", "2024-01-10", "2024-01-13"},
+		},
+		{
+			name:  "weekly on the start's weekday",
+			rule:  "FREQ=WEEKLY",
+			start: "2024-01-03", from: "2024-01-01", to: "2024-01-20",
+			want: []string{"2024-01-03", "2024-01-10", "2024-01-17"},
+		},
+		{
+			name:  "weekly by day skips days before the start",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			start: "2024-01-03", from: "2024-01-01", to: "2024-01-09",
+			want: []string{"2024-01-03", "2024-01-05", "2024-01-08"},
+		},
+		{
+			name:  "every other week with count",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=3",
+			start: "2024-01-01", from: "2024-01-01", to: "2024-12-31",
+			want: []string{"2024-01-02", "2024-01-16", "2024-01-30"},
+		},
+		{
+			name:  "count is applied from the start, not from the range",
+			rule:  "FREQ=DAILY;COUNT=5",
+			start: "2024-01-01", from: "2024-01-04", to: "2024-01-31",
+			want: []string{"2024-01-04", "2024-01-05"},
+		},
+		{
+			name:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY;UNTIL=20240601",
+			start: "2024-01-31", from: "2024-01-01", to: "2024-12-31",
+			want: []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+		{
+			name:  "range before the start",
+			rule:  "FREQ=DAILY",
+			start: "2024-02-01", from: "2024-01-01", to: "2024-01-31",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			got := formatDates(rule.Between(date(tt.start), date(tt.from), date(tt.to)))
+			if !equalStrings(got, tt.want) {
+				t.Errorf("Between = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLast(t *testing.T) {
+	rule, _ := Parse("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=5")
+	last, ok := rule.Last(date("2024-01-01"))
+	if !ok || !last.Equal(date("2024-01-15")) {
+		t.Errorf("Last = %v, %v, want 2024-01-15", last, ok)
+	}
+
+	rule, _ = Parse("FREQ=DAILY")
+	if _, ok := rule.Last(date("2024-01-01")); ok {
+		t.Errorf("Last of an unbounded rule should not be ok")
+	}
+
+	rule, _ = Parse("FREQ=DAILY;UNTIL=20231231")
+	if _, ok := rule.Last(date("2024-01-01")); ok {
+		t.Errorf("Last of a rule ending before its start should not be ok")
+	}
+}
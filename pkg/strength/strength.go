@@ -0,0 +1,162 @@
+// Package strength describes resistance exercises and works out training volume and
+// estimated one-rep maxes from logged sets.
+package strength
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CategoryCompound  = "compound"
+	CategoryIsolation = "isolation"
+)
+
+// Equipment lists the equipment an exercise can be done with
+var Equipment = map[string]bool{
+	"barbell":    true,
+	"dumbbell":   true,
+	"kettlebell": true,
+	"machine":    true,
+	"cable":      true,
+	"bodyweight": true,
+	"band":       true,
+}
+
+// MaxEstimateReps is the most reps, counting reps in reserve, from which a one-rep max is
+// estimated. Beyond it a set says more about endurance than about maximal strength.
+const MaxEstimateReps = 12
+
+var ErrInvalidCatalog = errors.New("invalid exercise catalog")
+
+var exerciseIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Exercise is an entry of the exercise catalog
+type Exercise struct {
+	ID        string   `yaml:"id" json:"id"`
+	Name      string   `yaml:"name" json:"name"`
+	Category  string   `yaml:"category" json:"category"`
+	Equipment string   `yaml:"equipment" json:"equipment"`
+	Muscles   []string `yaml:"muscles" json:"muscles"`
+}
+
+// Set is one logged set. WeightKg is the external load, zero for bodyweight work, and RPE
+// is the rate of perceived exertion from 1 to 10, zero when not recorded.
+type Set struct {
+	Reps     int
+	WeightKg float64
+	RPE      float64
+}
+
+// Catalog holds the known exercises
+type Catalog struct {
+	exercises []Exercise
+	byID      map[string]Exercise
+}
+
+// NewCatalog builds a catalog, rejecting invalid exercises and IDs given twice
+func NewCatalog(exercises ...Exercise) (*Catalog, error) {
+	catalog := &Catalog{byID: make(map[string]Exercise, len(exercises))}
+	for _, exercise := range exercises {
+		if err := validateExercise(exercise); err != nil {
+			return nil, err
+		}
+		if _, exists := catalog.byID[exercise.ID]; exists {
+			return nil, fmt.Errorf("%w: exercise %s is defined more than once", ErrInvalidCatalog, exercise.ID)
+		}
+		catalog.byID[exercise.ID] = exercise
+		catalog.exercises = append(catalog.exercises, exercise)
+	}
+
+	sort.Slice(catalog.exercises, func(i, j int) bool { return catalog.exercises[i].Name < catalog.exercises[j].Name })
+	return catalog, nil
+}
+
+// ParseCatalog reads a YAML catalog holding a list of exercises under the exercises key.
+// Unknown fields are rejected so that typos in the catalog do not go unnoticed.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var file struct {
+		Exercises []Exercise `yaml:"exercises"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCatalog, err)
+	}
+	return NewCatalog(file.Exercises...)
+}
+
+func validateExercise(exercise Exercise) error {
+	if !exerciseIDPattern.MatchString(exercise.ID) {
+		return fmt.Errorf("%w: exercise id %q must be lowercase words joined by hyphens", ErrInvalidCatalog, exercise.ID)
+	}
+	if exercise.Name == "" {
+		return fmt.Errorf("%w: exercise %s has no name", ErrInvalidCatalog, exercise.ID)
+	}
+	if exercise.Category != CategoryCompound && exercise.Category != CategoryIsolation {
+		return fmt.Errorf("%w: exercise %s has unknown category %q", ErrInvalidCatalog, exercise.ID, exercise.Category)
+	}
+	if !Equipment[exercise.Equipment] {
+		return fmt.Errorf("%w: exercise %s has unknown equipment %q", ErrInvalidCatalog, exercise.ID, exercise.Equipment)
+	}
+	if len(exercise.Muscles) == 0 {
+		return fmt.Errorf("%w: exercise %s lists no muscles", ErrInvalidCatalog, exercise.ID)
+	}
+	return nil
+}
+
+// Get returns the exercise with the given ID
+func (c *Catalog) Get(id string) (Exercise, bool) {
+	exercise, ok := c.byID[id]
+	return exercise, ok
+}
+
+// All returns every exercise ordered by name
+func (c *Catalog) All() []Exercise {
+	return append([]Exercise(nil), c.exercises...)
+}
+
+// Volume is the tonnage of the sets: reps times load, summed
+func Volume(sets []Set) float64 {
+	var volume float64
+	for _, set := range sets {
+		volume += float64(set.Reps) * set.WeightKg
+	}
+	return volume
+}
+
+// EstimateOneRepMax estimates the heaviest single repetition the lifter could do from a
+// set, using the Epley formula. With an RPE of 6 or more, the reps left in reserve are
+// counted as done. ok is false for unloaded sets and sets too far from a maximal effort.
+func EstimateOneRepMax(set Set) (oneRepMax float64, ok bool) {
+	if set.WeightKg <= 0 || set.Reps < 1 {
+		return 0, false
+	}
+
+	reps := float64(set.Reps)
+	if set.RPE >= 6 && set.RPE <= 10 {
+		reps += 10 - set.RPE
+	}
+	if reps > MaxEstimateReps {
+		return 0, false
+	}
+	if reps == 1 {
+		return set.WeightKg, true
+	}
+	return set.WeightKg * (1 + reps/30), true
+}
+
+// BestOneRepMax returns the highest one-rep max estimated from any of the sets
+func BestOneRepMax(sets []Set) (best float64, ok bool) {
+	for _, set := range sets {
+		if estimate, estimated := EstimateOneRepMax(set); estimated && estimate > best {
+			best, ok = estimate, true
+		}
+	}
+	return best, ok
+}
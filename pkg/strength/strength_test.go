@@ -0,0 +1,126 @@
+package strength
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const sampleCatalog = `
+exercises:
+  - id: barbell-back-squat
+    name: Barbell Back Squat
+    category: compound
+    equipment: barbell
+    muscles: [quadriceps, glutes]
+  - id: pull-up
+    name: Pull-Up
+    category: compound
+    equipment: bodyweight
+    muscles: [lats, biceps]
+`
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestParseCatalog(t *testing.T) {
+	catalog, err := ParseCatalog([]byte(sampleCatalog))
+	if err != nil {
+		t.Fatalf("ParseCatalog returned error: %v", err)
+	}
+
+	exercises := catalog.All()
+	if len(exercises) != 2 || exercises[0].ID != "barbell-back-squat" || exercises[1].ID != "pull-up" {
+		t.Errorf("All = %+v", exercises)
+	}
+	squat, ok := catalog.Get("barbell-back-squat")
+	if !ok || squat.Name != "Barbell Back Squat" || len(squat.Muscles) != 2 {
+		t.Errorf("Get = %+v, %v", squat, ok)
+	}
+	if _, ok := catalog.Get("deadlift"); ok {
+		t.Error("Get found an exercise missing from the catalog")
+	}
+}
+
+func TestParseCatalogRejectsInvalidExercises(t *testing.T) {
+	tests := map[string]string{
+		"unknown field": `
+exercises:
+  - id: pull-up
+    name: Pull-Up
+    category: compound
+    equipment: bodyweight
+    muscles: [lats]
+    difficulty: hard
+`,
+		"duplicate id": `
+exercises:
+  - {id: pull-up, name: Pull-Up, category: compound, equipment: bodyweight, muscles: [lats]}
+  - {id: pull-up, name: Chin-Up, category: compound, equipment: bodyweight, muscles: [biceps]}
+`,
+		"bad id":            `exercises: [{id: Pull Up, name: Pull-Up, category: compound, equipment: bodyweight, muscles: [lats]}]`,
+		"unknown category":  `exercises: [{id: pull-up, name: Pull-Up, category: cardio, equipment: bodyweight, muscles: [lats]}]`,
+		"unknown equipment": `exercises: [{id: pull-up, name: Pull-Up, category: compound, equipment: rings, muscles: [lats]}]`,
+		"no muscles":        `exercises: [{id: pull-up, name: Pull-Up, category: compound, equipment: bodyweight}]`,
+	}
+
+	for name, data := range tests {
+		if _, err := ParseCatalog([]byte(data)); !errors.Is(err, ErrInvalidCatalog) {
+			t.Errorf("%s: error = %v, want ErrInvalidCatalog", name, err)
+		}
+	}
+}
+
+func TestVolume(t *testing.T) {
+	sets := []Set{
+		{Reps: 5, WeightKg: 100},
+		{Reps: 5, WeightKg: 102.5},
+		{Reps: 10, WeightKg: 0},
+	}
+	if got := Volume(sets); !almostEqual(got, 1012.5) {
+		t.Errorf("Volume = %v, want 1012.5", got)
+	}
+	if got := Volume(nil); got != 0 {
+		t.Errorf("Volume(nil) = %v, want 0", got)
+	}
+}
+
+func TestEstimateOneRepMax(t *testing.T) {
+	tests := []struct {
+		name string
+		set  Set
+		want float64
+		ok   bool
+	}{
+		{"single", Set{Reps: 1, WeightKg: 140}, 140, true},
+		{"epley", Set{Reps: 5, WeightKg: 120}, 140, true},
+		{"reps in reserve", Set{Reps: 3, WeightKg: 120, RPE: 8}, 140, true},
+		{"low rpe ignored", Set{Reps: 5, WeightKg: 120, RPE: 4}, 140, true},
+		{"too many reps", Set{Reps: 15, WeightKg: 60}, 0, false},
+		{"too far from failure", Set{Reps: 10, WeightKg: 60, RPE: 6}, 0, false},
+		{"bodyweight", Set{Reps: 8}, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := EstimateOneRepMax(tt.set)
+		if ok != tt.ok || !almostEqual(got, tt.want) {
+			t.Errorf("%s: EstimateOneRepMax = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBestOneRepMax(t *testing.T) {
+	best, ok := BestOneRepMax([]Set{
+		{Reps: 5, WeightKg: 120},
+		{Reps: 1, WeightKg: 145},
+		{Reps: 20, WeightKg: 80},
+	})
+	if !ok || !almostEqual(best, 145) {
+		t.Errorf("BestOneRepMax = %v, %v, want 145, true", best, ok)
+	}
+
+	if _, ok := BestOneRepMax([]Set{{Reps: 10}}); ok {
+		t.Error("BestOneRepMax estimated a max from bodyweight sets")
+	}
+}
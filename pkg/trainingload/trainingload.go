@@ -0,0 +1,49 @@
+// Package trainingload models fitness and fatigue as exponentially weighted averages of
+// daily training load.
+package trainingload
+
+import "math"
+
+const (
+	// AcuteDays is the time constant of the acute load, which tracks fatigue
+	AcuteDays = 7
+	// ChronicDays is the time constant of the chronic load, which tracks fitness
+	ChronicDays = 42
+)
+
+var (
+	acuteDecay   = math.Exp(-1.0 / AcuteDays)
+	chronicDecay = math.Exp(-1.0 / ChronicDays)
+)
+
+// State is the acute and chronic load at the end of a day
+type State struct {
+	Acute   float64
+	Chronic float64
+}
+
+// Next returns the state at the end of the following day, on which load was done. Each
+// average keeps e^(-1/days) of its previous value and takes the rest from the day's load.
+func (s State) Next(load float64) State {
+	return State{
+		Acute:   s.Acute*acuteDecay + load*(1-acuteDecay),
+		Chronic: s.Chronic*chronicDecay + load*(1-chronicDecay),
+	}
+}
+
+// Rest returns the state after the given number of days without training
+func (s State) Rest(days int) State {
+	if days <= 0 {
+		return s
+	}
+	return State{
+		Acute:   s.Acute * math.Pow(acuteDecay, float64(days)),
+		Chronic: s.Chronic * math.Pow(chronicDecay, float64(days)),
+	}
+}
+
+// Balance returns the training stress balance, chronic minus acute load. It is positive
+// when fresh and negative when fatigue outweighs fitness.
+func (s State) Balance() float64 {
+	return s.Chronic - s.Acute
+}
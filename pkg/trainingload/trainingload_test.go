@@ -0,0 +1,55 @@
+package trainingload
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestNext(t *testing.T) {
+	state := State{}.Next(100)
+
+	if want := 100 * (1 - math.Exp(-1.0/7)); !almostEqual(state.Acute, want) {
+		t.Errorf("Acute = %v, want %v", state.Acute, want)
+	}
+	if want := 100 * (1 - math.Exp(-1.0/42)); !almostEqual(state.Chronic, want) {
+		t.Errorf("Chronic = %v, want %v", state.Chronic, want)
+	}
+	if state.Balance() >= 0 {
+		t.Errorf("Balance after a hard day = %v, want negative", state.Balance())
+	}
+}
+
+func TestSteadyLoad(t *testing.T) {
+	// Training the same every day brings both averages to that load
+	var state State
+	for day := 0; day < 1000; day++ {
+		state = state.Next(50)
+	}
+	if !almostEqual(state.Acute, 50) || math.Abs(state.Chronic-50) > 1e-6 {
+		t.Errorf("state after steady training = %+v", state)
+	}
+}
+
+func TestRest(t *testing.T) {
+	state := State{Acute: 80, Chronic: 60}
+
+	stepped := state
+	for day := 0; day < 10; day++ {
+		stepped = stepped.Next(0)
+	}
+
+	rested := state.Rest(10)
+	if !almostEqual(rested.Acute, stepped.Acute) || !almostEqual(rested.Chronic, stepped.Chronic) {
+		t.Errorf("Rest(10) = %+v, want %+v", rested, stepped)
+	}
+	if rested.Balance() <= 0 {
+		t.Errorf("Balance after rest = %v, want positive", rested.Balance())
+	}
+	if state.Rest(0) != state {
+		t.Error("Rest(0) changed the state")
+	}
+}
@@ -0,0 +1,105 @@
+package trainingplan
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// Catalog holds every version of the available programs
+type Catalog struct {
+	programs map[string][]Program
+}
+
+// NewCatalog builds a catalog from programs, rejecting a version given twice
+func NewCatalog(programs ...Program) (*Catalog, error) {
+	catalog := &Catalog{programs: make(map[string][]Program)}
+	for _, program := range programs {
+		for _, existing := range catalog.programs[program.ID] {
+			if existing.Version == program.Version {
+				return nil, fmt.Errorf("%w: %s version %d is defined more than once", ErrInvalidProgram, program.ID, program.Version)
+			}
+		}
+		catalog.programs[program.ID] = append(catalog.programs[program.ID], program)
+	}
+
+	for _, versions := range catalog.programs {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	}
+	return catalog, nil
+}
+
+// LoadCatalog reads the .yaml, .yml and .json files at the root of fsys, one program
+// version per file. Other files are ignored.
+func LoadCatalog(fsys fs.FS) (*Catalog, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var programs []Program
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		var format string
+		switch path.Ext(entry.Name()) {
+		case ".yaml", ".yml":
+			format = FormatYAML
+		case ".json":
+			format = FormatJSON
+		default:
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		program, err := Parse(data, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		programs = append(programs, program)
+	}
+
+	return NewCatalog(programs...)
+}
+
+// Get returns the given version of a program, or its latest version when version is 0
+func (c *Catalog) Get(id string, version int) (Program, bool) {
+	versions := c.programs[id]
+	if len(versions) == 0 {
+		return Program{}, false
+	}
+	if version == 0 {
+		return versions[len(versions)-1], true
+	}
+	for _, program := range versions {
+		if program.Version == version {
+			return program, true
+		}
+	}
+	return Program{}, false
+}
+
+// Latest returns the latest version of every program, ordered by ID
+func (c *Catalog) Latest() []Program {
+	programs := make([]Program, 0, len(c.programs))
+	for _, versions := range c.programs {
+		programs = append(programs, versions[len(versions)-1])
+	}
+	sort.Slice(programs, func(i, j int) bool { return programs[i].ID < programs[j].ID })
+	return programs
+}
+
+// All returns every version of every program
+func (c *Catalog) All() []Program {
+	var programs []Program
+	for _, versions := range c.programs {
+		programs = append(programs, versions...)
+	}
+	return programs
+}
@@ -0,0 +1,187 @@
+// Package trainingplan reads multi-week training programs and works out their schedule.
+// A program is a list of weeks, each with sessions on days 1 to 7 of the week, and a
+// progression rule deciding when a week whose sessions were missed is repeated.
+package trainingplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// MaxWeeks is the longest program accepted
+const MaxWeeks = 104
+
+var ErrInvalidProgram = errors.New("invalid training program")
+
+var programIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Program is one version of a training program
+type Program struct {
+	ID          string      `yaml:"id" json:"id"`
+	Version     int         `yaml:"version" json:"version"`
+	Name        string      `yaml:"name" json:"name"`
+	Description string      `yaml:"description" json:"description"`
+	Weeks       []Week      `yaml:"weeks" json:"weeks"`
+	Progression Progression `yaml:"progression" json:"progression"`
+}
+
+// Week lists the sessions of one program week. A week without sessions is a rest week.
+type Week struct {
+	Sessions []Session `yaml:"sessions" json:"sessions"`
+}
+
+// Session is a workout on Day, counted from 1 for the first day of the week
+type Session struct {
+	Day               int    `yaml:"day" json:"day"`
+	ActivityType      string `yaml:"activityType" json:"activityType"`
+	DurationInMinutes int    `yaml:"durationInMinutes" json:"durationInMinutes"`
+	Notes             string `yaml:"notes,omitempty" json:"notes,omitempty"`
+}
+
+// Progression decides how the program adapts to missed sessions. A week whose share of
+// completed sessions is below RepeatWeekBelow is done again, at most MaxRepeats times
+// in a row. A zero RepeatWeekBelow never repeats weeks.
+type Progression struct {
+	RepeatWeekBelow float64 `yaml:"repeatWeekBelow" json:"repeatWeekBelow"`
+	MaxRepeats      int     `yaml:"maxRepeats" json:"maxRepeats"`
+}
+
+// ScheduledSession is a session placed on a calendar date. Week is counted from 0.
+type ScheduledSession struct {
+	Week    int
+	Date    time.Time
+	Session Session
+}
+
+// Parse reads and validates a program in the given format. Unknown fields are rejected
+// so that typos in a definition do not go unnoticed.
+func Parse(data []byte, format string) (Program, error) {
+	var program Program
+	switch format {
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&program); err != nil {
+			return Program{}, fmt.Errorf("%w: %v", ErrInvalidProgram, err)
+		}
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&program); err != nil {
+			return Program{}, fmt.Errorf("%w: %v", ErrInvalidProgram, err)
+		}
+	default:
+		return Program{}, fmt.Errorf("%w: unsupported format %q", ErrInvalidProgram, format)
+	}
+
+	if err := program.Validate(); err != nil {
+		return Program{}, err
+	}
+	return program, nil
+}
+
+// Validate checks the program's identity, weeks and progression rule. Activity types
+// are not checked here as they belong to the application.
+func (p Program) Validate() error {
+	if !programIDPattern.MatchString(p.ID) {
+		return fmt.Errorf("%w: id must be lowercase words separated by dashes", ErrInvalidProgram)
+	}
+	if p.Version < 1 {
+		return fmt.Errorf("%w: version must be at least 1", ErrInvalidProgram)
+	}
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProgram)
+	}
+	if len(p.Weeks) == 0 || len(p.Weeks) > MaxWeeks {
+		return fmt.Errorf("%w: a program must have between 1 and %d weeks", ErrInvalidProgram, MaxWeeks)
+	}
+
+	sessions := 0
+	for i, week := range p.Weeks {
+		days := make(map[int]bool, len(week.Sessions))
+		for _, session := range week.Sessions {
+			if session.Day < 1 || session.Day > 7 {
+				return fmt.Errorf("%w: week %d has a session on day %d, days go from 1 to 7", ErrInvalidProgram, i+1, session.Day)
+			}
+			if days[session.Day] {
+				return fmt.Errorf("%w: week %d has more than one session on day %d", ErrInvalidProgram, i+1, session.Day)
+			}
+			days[session.Day] = true
+			if session.ActivityType == "" {
+				return fmt.Errorf("%w: week %d day %d has no activity type", ErrInvalidProgram, i+1, session.Day)
+			}
+			if session.DurationInMinutes < 1 {
+				return fmt.Errorf("%w: week %d day %d must last at least a minute", ErrInvalidProgram, i+1, session.Day)
+			}
+		}
+		sessions += len(week.Sessions)
+	}
+	if sessions == 0 {
+		return fmt.Errorf("%w: a program must have at least one session", ErrInvalidProgram)
+	}
+
+	if p.Progression.RepeatWeekBelow < 0 || p.Progression.RepeatWeekBelow > 1 {
+		return fmt.Errorf("%w: repeatWeekBelow must be between 0 and 1", ErrInvalidProgram)
+	}
+	if p.Progression.MaxRepeats < 0 {
+		return fmt.Errorf("%w: maxRepeats must not be negative", ErrInvalidProgram)
+	}
+	return nil
+}
+
+// Schedule places the sessions of week fromWeek and every week after it on consecutive
+// weeks, the first starting on weekStart
+func (p Program) Schedule(fromWeek int, weekStart time.Time) []ScheduledSession {
+	weekStart = date(weekStart)
+
+	var sessions []ScheduledSession
+	for week := fromWeek; week >= 0 && week < len(p.Weeks); week++ {
+		start := weekStart.AddDate(0, 0, 7*(week-fromWeek))
+		for _, session := range p.Weeks[week].Sessions {
+			sessions = append(sessions, ScheduledSession{
+				Week:    week,
+				Date:    start.AddDate(0, 0, session.Day-1),
+				Session: session,
+			})
+		}
+	}
+	return sessions
+}
+
+// Advance decides what follows week once it is over, given how many times in a row it
+// has been repeated and its sessions. The week is done again when too few of its
+// sessions were completed and it may still be repeated; otherwise the program moves
+// on. The returned week equals len(p.Weeks) when the program is finished.
+func (p Program) Advance(week, repeats, planned, completed int) (nextWeek, nextRepeats int, repeated bool) {
+	rule := p.Progression
+	if planned > 0 && Adherence(planned, completed) < rule.RepeatWeekBelow && repeats < rule.MaxRepeats {
+		return week, repeats + 1, true
+	}
+	return week + 1, 0, false
+}
+
+// Adherence returns the share of planned sessions that were completed, or 1 when none
+// were planned
+func Adherence(planned, completed int) float64 {
+	if planned <= 0 {
+		return 1
+	}
+	return float64(completed) / float64(planned)
+}
+
+// date truncates t to its calendar date at midnight UTC
+func date(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
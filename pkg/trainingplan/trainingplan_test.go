@@ -0,0 +1,174 @@
+package trainingplan
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const sampleYAML = `
+id: couch-to-5k
+version: 2
+name: Couch to 5K
+weeks:
+  - sessions:
+      - day: 1
+        activityType: Running
+        durationInMinutes: 20
+      - day: 3
+        activityType: Running
+        durationInMinutes: 20
+  - sessions: []
+  - sessions:
+      - day: 2
+        activityType: Running
+        durationInMinutes: 25
+        notes: Jog 5 minutes, walk 2
+progression:
+  repeatWeekBelow: 0.5
+  maxRepeats: 1
+`
+
+const sampleJSON = `{
+  "id": "couch-to-5k",
+  "version": 1,
+  "name": "Couch to 5K",
+  "weeks": [{"sessions": [{"day": 7, "activityType": "Walking", "durationInMinutes": 30}]}]
+}`
+
+func parseDate(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	program, err := Parse([]byte(sampleYAML), FormatYAML)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if program.ID != "couch-to-5k" || program.Version != 2 || len(program.Weeks) != 3 {
+		t.Errorf("Parse = %+v", program)
+	}
+	if program.Weeks[2].Sessions[0].Notes != "Jog 5 minutes, walk 2" {
+		t.Errorf("Notes = %q", program.Weeks[2].Sessions[0].Notes)
+	}
+	if program.Progression.RepeatWeekBelow != 0.5 || program.Progression.MaxRepeats != 1 {
+		t.Errorf("Progression = %+v", program.Progression)
+	}
+
+	program, err = Parse([]byte(sampleJSON), FormatJSON)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if program.Version != 1 || program.Weeks[0].Sessions[0].Day != 7 {
+		t.Errorf("Parse = %+v", program)
+	}
+
+	invalid := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"unknown field", `{"id": "a", "version": 1, "name": "A", "weeks": [], "level": 3}`, FormatJSON},
+		{"unknown yaml field", "id: a\nversion: 1\nname: A\nweek: []\n", FormatYAML},
+		{"bad id", `{"id": "Couch To 5K", "version": 1, "name": "A", "weeks": [{"sessions": [{"day": 1, "activityType": "Running", "durationInMinutes": 20}]}]}`, FormatJSON},
+		{"no version", `{"id": "a", "name": "A", "weeks": [{"sessions": [{"day": 1, "activityType": "Running", "durationInMinutes": 20}]}]}`, FormatJSON},
+		{"no sessions", `{"id": "a", "version": 1, "name": "A", "weeks": [{"sessions": []}]}`, FormatJSON},
+		{"day out of range", `{"id": "a", "version": 1, "name": "A", "weeks": [{"sessions": [{"day": 8, "activityType": "Running", "durationInMinutes": 20}]}]}`, FormatJSON},
+		{"same day twice", `{"id": "a", "version": 1, "name": "A", "weeks": [{"sessions": [{"day": 1, "activityType": "Running", "durationInMinutes": 20}, {"day": 1, "activityType": "Yoga", "durationInMinutes": 20}]}]}`, FormatJSON},
+		{"no duration", `{"id": "a", "version": 1, "name": "A", "weeks": [{"sessions": [{"day": 1, "activityType": "Running"}]}]}`, FormatJSON},
+		{"threshold above one", `{"id": "a", "version": 1, "name": "A", "weeks": [{"sessions": [{"day": 1, "activityType": "Running", "durationInMinutes": 20}]}], "progression": {"repeatWeekBelow": 1.5}}`, FormatJSON},
+		{"unsupported format", sampleJSON, "toml"},
+	}
+	for _, tt := range invalid {
+		if _, err := Parse([]byte(tt.data), tt.format); !errors.Is(err, ErrInvalidProgram) {
+			t.Errorf("%s: error = %v, want ErrInvalidProgram", tt.name, err)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	program, _ := Parse([]byte(sampleYAML), FormatYAML)
+
+	sessions := program.Schedule(0, parseDate("2024-03-04"))
+	want := []struct {
+		week int
+		date string
+	}{{0, "2024-03-04"}, {0, "2024-03-06"}, {2, "2024-03-19"}}
+	if len(sessions) != len(want) {
+		t.Fatalf("Schedule returned %d sessions, want %d", len(sessions), len(want))
+	}
+	for i, w := range want {
+		if sessions[i].Week != w.week || !sessions[i].Date.Equal(parseDate(w.date)) {
+			t.Errorf("session %d = week %d on %s, want week %d on %s", i, sessions[i].Week, sessions[i].Date.Format("2006-01-02"), w.week, w.date)
+		}
+	}
+
+	sessions = program.Schedule(2, parseDate("2024-04-01"))
+	if len(sessions) != 1 || !sessions[0].Date.Equal(parseDate("2024-04-02")) {
+		t.Errorf("Schedule from week 2 = %+v", sessions)
+	}
+
+	if sessions := program.Schedule(3, parseDate("2024-04-01")); len(sessions) != 0 {
+		t.Errorf("Schedule past the last week = %+v, want none", sessions)
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	program, _ := Parse([]byte(sampleYAML), FormatYAML)
+
+	tests := []struct {
+		name               string
+		week, repeats      int
+		planned, completed int
+		wantWeek           int
+		wantRepeats        int
+		wantRepeated       bool
+	}{
+		{"enough sessions done", 0, 0, 2, 1, 1, 0, false},
+		{"too few sessions done", 0, 0, 2, 0, 0, 1, true},
+		{"repeats exhausted", 0, 1, 2, 0, 1, 0, false},
+		{"rest week", 1, 0, 0, 0, 2, 0, false},
+		{"last week finishes the program", 2, 0, 1, 1, 3, 0, false},
+	}
+	for _, tt := range tests {
+		week, repeats, repeated := program.Advance(tt.week, tt.repeats, tt.planned, tt.completed)
+		if week != tt.wantWeek || repeats != tt.wantRepeats || repeated != tt.wantRepeated {
+			t.Errorf("%s: Advance = %d, %d, %v, want %d, %d, %v", tt.name, week, repeats, repeated, tt.wantWeek, tt.wantRepeats, tt.wantRepeated)
+		}
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	fsys := fstest.MapFS{
+		"couch-to-5k.v1.json": {Data: []byte(sampleJSON)},
+		"couch-to-5k.v2.yaml": {Data: []byte(sampleYAML)},
+		"README.md":           {Data: []byte("not a program")},
+	}
+	catalog, err := LoadCatalog(fsys)
+	if err != nil {
+		t.Fatalf("LoadCatalog returned error: %v", err)
+	}
+
+	if program, ok := catalog.Get("couch-to-5k", 0); !ok || program.Version != 2 {
+		t.Errorf("Get latest = %+v, %v, want version 2", program, ok)
+	}
+	if program, ok := catalog.Get("couch-to-5k", 1); !ok || program.Version != 1 {
+		t.Errorf("Get version 1 = %+v, %v", program, ok)
+	}
+	if _, ok := catalog.Get("couch-to-5k", 3); ok {
+		t.Errorf("Get of a missing version should not be ok")
+	}
+	if latest := catalog.Latest(); len(latest) != 1 || latest[0].Version != 2 {
+		t.Errorf("Latest = %+v", latest)
+	}
+
+	fsys["copy.yml"] = &fstest.MapFile{Data: []byte(sampleYAML)}
+	if _, err := LoadCatalog(fsys); !errors.Is(err, ErrInvalidProgram) {
+		t.Errorf("LoadCatalog with a duplicate version error = %v, want ErrInvalidProgram", err)
+	}
+}
@@ -0,0 +1,63 @@
+package workout
+
+import (
+	"FitByte/pkg/fit"
+	"fmt"
+	"io"
+)
+
+// ParseFIT decodes a Garmin FIT activity file into a workout. The first session
+// provides the summary metrics; device laps and records are kept as laps and points.
+func ParseFIT(r io.Reader) (*Workout, error) {
+	activity, err := fit.DecodeActivity(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode fit: %w", err)
+	}
+
+	if len(activity.Sessions) == 0 && len(activity.Records) == 0 {
+		return nil, ErrNoTrackpoints
+	}
+
+	w := &Workout{}
+	if len(activity.Sessions) > 0 {
+		session := activity.Sessions[0]
+		w.Sport = session.Sport
+		w.StartTime = session.StartTime
+		w.Duration = session.TotalTimerTime
+		if w.Duration == 0 {
+			w.Duration = session.TotalElapsedTime
+		}
+		w.DistanceMeters = session.TotalDistance
+		w.ElevationGainMeters = session.TotalAscent
+	}
+
+	for _, l := range activity.Laps {
+		duration := l.TotalTimerTime
+		if duration == 0 {
+			duration = l.TotalElapsedTime
+		}
+		w.Laps = append(w.Laps, Lap{
+			StartTime:      l.StartTime,
+			Duration:       duration,
+			DistanceMeters: l.TotalDistance,
+			Calories:       l.TotalCalories,
+			AvgHeartRate:   l.AvgHeartRate,
+			MaxHeartRate:   l.MaxHeartRate,
+		})
+	}
+
+	for _, record := range activity.Records {
+		w.Points = append(w.Points, Point{
+			Time:         record.Timestamp,
+			Latitude:     record.Latitude,
+			Longitude:    record.Longitude,
+			HasPosition:  record.HasPosition,
+			Elevation:    record.Altitude,
+			HasElevation: record.HasAltitude,
+			HeartRate:    record.HeartRate,
+			Distance:     record.Distance,
+		})
+	}
+
+	return w, nil
+}
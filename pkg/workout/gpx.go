@@ -0,0 +1,74 @@
+package workout
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type gpxFile struct {
+	Metadata struct {
+		Time time.Time `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Type     string       `xml:"type"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat       float64   `xml:"lat,attr"`
+	Lon       float64   `xml:"lon,attr"`
+	Elevation *float64  `xml:"ele"`
+	Time      time.Time `xml:"time"`
+	HeartRate int       `xml:"extensions>TrackPointExtension>hr"`
+}
+
+// ParseGPX decodes a GPX 1.1 document into a workout. The sport is taken from
+// the first track's type element, and heart rate from Garmin TrackPointExtension.
+func ParseGPX(r io.Reader) (*Workout, error) {
+	var doc gpxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode gpx: %w", err)
+	}
+
+	w := &Workout{}
+	for _, track := range doc.Tracks {
+		if w.Sport == "" {
+			w.Sport = track.Type
+		}
+		for _, segment := range track.Segments {
+			for _, p := range segment.Points {
+				point := Point{
+					Time:        p.Time,
+					Latitude:    p.Lat,
+					Longitude:   p.Lon,
+					HasPosition: true,
+					HeartRate:   p.HeartRate,
+				}
+				if p.Elevation != nil {
+					point.Elevation = *p.Elevation
+					point.HasElevation = true
+				}
+				w.Points = append(w.Points, point)
+			}
+		}
+	}
+
+	if len(w.Points) == 0 {
+		return nil, ErrNoTrackpoints
+	}
+
+	if w.Points[0].Time.IsZero() {
+		w.StartTime = doc.Metadata.Time
+	}
+
+	return w, nil
+}
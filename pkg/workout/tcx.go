@@ -0,0 +1,100 @@
+package workout
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+type tcxFile struct {
+	Activities []tcxActivity `xml:"Activities>Activity"`
+}
+
+type tcxActivity struct {
+	Sport string    `xml:"Sport,attr"`
+	ID    time.Time `xml:"Id"`
+	Laps  []tcxLap  `xml:"Lap"`
+}
+
+type tcxLap struct {
+	StartTime        time.Time       `xml:"StartTime,attr"`
+	TotalTimeSeconds float64         `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64         `xml:"DistanceMeters"`
+	Calories         int             `xml:"Calories"`
+	AverageHeartRate int             `xml:"AverageHeartRateBpm>Value"`
+	MaximumHeartRate int             `xml:"MaximumHeartRateBpm>Value"`
+	Trackpoints      []tcxTrackpoint `xml:"Track>Trackpoint"`
+}
+
+type tcxTrackpoint struct {
+	Time           time.Time `xml:"Time"`
+	Latitude       *float64  `xml:"Position>LatitudeDegrees"`
+	Longitude      *float64  `xml:"Position>LongitudeDegrees"`
+	AltitudeMeters *float64  `xml:"AltitudeMeters"`
+	DistanceMeters float64   `xml:"DistanceMeters"`
+	HeartRate      int       `xml:"HeartRateBpm>Value"`
+}
+
+// ParseTCX decodes a Garmin Training Center document into a workout. Only the
+// first activity is read; its laps are kept and their totals are used as the summary.
+func ParseTCX(r io.Reader) (*Workout, error) {
+	var doc tcxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode tcx: %w", err)
+	}
+
+	if len(doc.Activities) == 0 {
+		return nil, ErrNoTrackpoints
+	}
+
+	activity := doc.Activities[0]
+	w := &Workout{
+		Sport:     activity.Sport,
+		StartTime: activity.ID,
+	}
+
+	var totalSeconds float64
+	for _, l := range activity.Laps {
+		w.Laps = append(w.Laps, Lap{
+			StartTime:      l.StartTime,
+			Duration:       time.Duration(math.Round(l.TotalTimeSeconds * float64(time.Second))),
+			DistanceMeters: l.DistanceMeters,
+			Calories:       l.Calories,
+			AvgHeartRate:   l.AverageHeartRate,
+			MaxHeartRate:   l.MaximumHeartRate,
+		})
+		totalSeconds += l.TotalTimeSeconds
+		w.DistanceMeters += l.DistanceMeters
+
+		for _, tp := range l.Trackpoints {
+			point := Point{
+				Time:      tp.Time,
+				HeartRate: tp.HeartRate,
+				Distance:  tp.DistanceMeters,
+			}
+			if tp.Latitude != nil && tp.Longitude != nil {
+				point.Latitude = *tp.Latitude
+				point.Longitude = *tp.Longitude
+				point.HasPosition = true
+			}
+			if tp.AltitudeMeters != nil {
+				point.Elevation = *tp.AltitudeMeters
+				point.HasElevation = true
+			}
+			w.Points = append(w.Points, point)
+		}
+	}
+
+	if len(w.Points) == 0 && len(w.Laps) == 0 {
+		return nil, ErrNoTrackpoints
+	}
+
+	w.Duration = time.Duration(math.Round(totalSeconds * float64(time.Second)))
+	if w.StartTime.IsZero() && len(w.Laps) > 0 {
+		w.StartTime = w.Laps[0].StartTime
+	}
+
+	return w, nil
+}
@@ -0,0 +1,141 @@
+package workout
+
+import (
+	"FitByte/pkg/geo"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported workout file format")
+	ErrNoTrackpoints     = errors.New("workout file contains no trackpoints")
+)
+
+// Point is a single recorded trackpoint. Position, elevation, heart rate and
+// cumulative distance are optional and flagged or zero when the device did not record them.
+type Point struct {
+	Time         time.Time
+	Latitude     float64
+	Longitude    float64
+	HasPosition  bool
+	Elevation    float64
+	HasElevation bool
+	HeartRate    int
+	Distance     float64
+}
+
+// Lap is a device-recorded lap as found in TCX and FIT files
+type Lap struct {
+	StartTime      time.Time
+	Duration       time.Duration
+	DistanceMeters float64
+	Calories       int
+	AvgHeartRate   int
+	MaxHeartRate   int
+}
+
+// Workout is the format-independent result of parsing a workout file
+type Workout struct {
+	Sport               string
+	StartTime           time.Time
+	Duration            time.Duration
+	DistanceMeters      float64
+	ElevationGainMeters float64
+	Points              []Point
+	Laps                []Lap
+}
+
+// Parse decodes a workout file of the given format and derives its summary metrics
+func Parse(format string, r io.Reader) (*Workout, error) {
+	var (
+		w   *Workout
+		err error
+	)
+
+	switch strings.ToLower(format) {
+	case FormatGPX:
+		w, err = ParseGPX(r)
+	case FormatTCX:
+		w, err = ParseTCX(r)
+	case FormatFIT:
+		w, err = ParseFIT(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w.Summarize()
+	return w, nil
+}
+
+// Summarize fills in start time, duration, distance and elevation gain from the
+// trackpoints for any value the file did not provide itself.
+func (w *Workout) Summarize() {
+	if len(w.Points) == 0 {
+		return
+	}
+
+	first, last := w.Points[0], w.Points[len(w.Points)-1]
+
+	if w.StartTime.IsZero() {
+		w.StartTime = first.Time
+	}
+
+	if w.Duration == 0 && !first.Time.IsZero() && !last.Time.IsZero() {
+		w.Duration = last.Time.Sub(first.Time)
+	}
+
+	if w.DistanceMeters == 0 {
+		if last.Distance > 0 {
+			w.DistanceMeters = last.Distance
+		} else {
+			w.DistanceMeters = TrackDistance(w.Points)
+		}
+	}
+
+	if w.ElevationGainMeters == 0 {
+		w.ElevationGainMeters = ElevationGain(w.Points)
+	}
+}
+
+// TrackDistance returns the great-circle length of the track in meters
+func TrackDistance(points []Point) float64 {
+	var total float64
+	var prev *Point
+	for i := range points {
+		if !points[i].HasPosition {
+			continue
+		}
+		if prev != nil {
+			total += geo.Haversine(prev.Latitude, prev.Longitude, points[i].Latitude, points[i].Longitude)
+		}
+		prev = &points[i]
+	}
+	return total
+}
+
+// ElevationGain returns the sum of all climbs along the track in meters
+func ElevationGain(points []Point) float64 {
+	var gain float64
+	var prev *Point
+	for i := range points {
+		if !points[i].HasElevation {
+			continue
+		}
+		if prev != nil && points[i].Elevation > prev.Elevation {
+			gain += points[i].Elevation - prev.Elevation
+		}
+		prev = &points[i]
+	}
+	return gain
+}
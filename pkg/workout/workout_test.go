@@ -0,0 +1,307 @@
+package workout
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// One hundredth of a degree along a meridian
+const hundredthDegreeMeters = 1111.95
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestParseGPX(t *testing.T) {
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		gpx          string
+		wantErr      error
+		wantSport    string
+		wantStart    time.Time
+		wantDuration time.Duration
+		wantDistance float64
+		wantGain     float64
+		wantPoints   int
+	}{
+		{
+			name: "track",
+			gpx: `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+ <trk><type>running</type><trkseg>
+  <trkpt lat="0" lon="0"><ele>10</ele><time>2024-05-01T07:00:00Z</time><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+  <trkpt lat="0.01" lon="0"><ele>14</ele><time>2024-05-01T07:05:00Z</time></trkpt>
+ </trkseg><trkseg>
+  <trkpt lat="0.02" lon="0"><ele>12</ele><time>2024-05-01T07:10:00Z</time></trkpt>
+ </trkseg></trk>
+</gpx>`,
+			wantSport:    "running",
+			wantStart:    start,
+			wantDuration: 10 * time.Minute,
+			wantDistance: 2 * hundredthDegreeMeters,
+			wantGain:     4,
+			wantPoints:   3,
+		},
+		{
+			// Routes drawn on a map carry no times, so the start comes from the metadata
+			name: "no timestamps",
+			gpx: `<gpx><metadata><time>2024-05-01T07:00:00Z</time></metadata><trk><trkseg>
+  <trkpt lat="0" lon="0"/><trkpt lat="0.01" lon="0"/>
+ </trkseg></trk></gpx>`,
+			wantStart:    start,
+			wantDistance: hundredthDegreeMeters,
+			wantPoints:   2,
+		},
+		{
+			name:       "single point",
+			gpx:        `<gpx><trk><trkseg><trkpt lat="52.52" lon="13.405"><time>2024-05-01T07:00:00Z</time></trkpt></trkseg></trk></gpx>`,
+			wantStart:  start,
+			wantPoints: 1,
+		},
+		{
+			name:    "no trackpoints",
+			gpx:     `<gpx><trk><type>running</type><trkseg></trkseg></trk></gpx>`,
+			wantErr: ErrNoTrackpoints,
+		},
+		{
+			name: "invalid xml",
+			gpx:  `<gpx><trk><trkseg><trkpt lat="0" lon="0"></trkseg></trk></gpx>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Parse(FormatGPX, strings.NewReader(tt.gpx))
+			if tt.wantErr != nil || tt.wantPoints == 0 {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Parse error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if w.Sport != tt.wantSport || !w.StartTime.Equal(tt.wantStart) || w.Duration != tt.wantDuration {
+				t.Errorf("sport/start/duration = %q/%v/%v, want %q/%v/%v",
+					w.Sport, w.StartTime, w.Duration, tt.wantSport, tt.wantStart, tt.wantDuration)
+			}
+			if !almostEqual(w.DistanceMeters, tt.wantDistance) || !almostEqual(w.ElevationGainMeters, tt.wantGain) {
+				t.Errorf("distance/gain = %v/%v, want %v/%v", w.DistanceMeters, w.ElevationGainMeters, tt.wantDistance, tt.wantGain)
+			}
+			if len(w.Points) != tt.wantPoints {
+				t.Errorf("points = %d, want %d", len(w.Points), tt.wantPoints)
+			}
+		})
+	}
+}
+
+func TestParseGPXPoints(t *testing.T) {
+	gpx := `<gpx xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1"><trk><trkseg>
+  <trkpt lat="52.52" lon="13.405"><ele>34</ele><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>131</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+  <trkpt lat="52.53" lon="13.405"/>
+ </trkseg></trk></gpx>`
+
+	w, err := ParseGPX(strings.NewReader(gpx))
+	if err != nil {
+		t.Fatalf("ParseGPX: %v", err)
+	}
+
+	first, second := w.Points[0], w.Points[1]
+	if !first.HasPosition || first.Latitude != 52.52 || first.Longitude != 13.405 {
+		t.Errorf("first point position = %v,%v (%v)", first.Latitude, first.Longitude, first.HasPosition)
+	}
+	if !first.HasElevation || first.Elevation != 34 || first.HeartRate != 131 {
+		t.Errorf("first point elevation/hr = %v/%d", first.Elevation, first.HeartRate)
+	}
+	if second.HasElevation || second.HeartRate != 0 {
+		t.Errorf("second point should have no elevation or heart rate: %+v", second)
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		tcx          string
+		wantErr      error
+		wantStart    time.Time
+		wantDuration time.Duration
+		wantDistance float64
+		wantGain     float64
+		wantLaps     int
+		wantPoints   int
+	}{
+		{
+			name: "laps",
+			tcx: `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+ <Activities><Activity Sport="Running"><Id>2024-05-01T07:00:00Z</Id>
+  <Lap StartTime="2024-05-01T07:00:00Z">
+   <TotalTimeSeconds>150.4</TotalTimeSeconds><DistanceMeters>500</DistanceMeters><Calories>40</Calories>
+   <AverageHeartRateBpm><Value>131</Value></AverageHeartRateBpm><MaximumHeartRateBpm><Value>140</Value></MaximumHeartRateBpm>
+   <Track>
+    <Trackpoint><Time>2024-05-01T07:00:00Z</Time><Position><LatitudeDegrees>0</LatitudeDegrees><LongitudeDegrees>0</LongitudeDegrees></Position><AltitudeMeters>10</AltitudeMeters><DistanceMeters>0</DistanceMeters><HeartRateBpm><Value>120</Value></HeartRateBpm></Trackpoint>
+    <Trackpoint><Time>2024-05-01T07:02:30Z</Time><AltitudeMeters>16</AltitudeMeters><DistanceMeters>500</DistanceMeters></Trackpoint>
+   </Track>
+  </Lap>
+  <Lap StartTime="2024-05-01T07:02:30Z">
+   <TotalTimeSeconds>149.6</TotalTimeSeconds><DistanceMeters>520</DistanceMeters><Calories>45</Calories>
+   <Track>
+    <Trackpoint><Time>2024-05-01T07:05:00Z</Time><AltitudeMeters>13</AltitudeMeters><DistanceMeters>1020</DistanceMeters></Trackpoint>
+   </Track>
+  </Lap>
+ </Activity></Activities>
+</TrainingCenterDatabase>`,
+			wantStart:    start,
+			wantDuration: 5 * time.Minute,
+			wantDistance: 1020,
+			wantGain:     6,
+			wantLaps:     2,
+			wantPoints:   3,
+		},
+		{
+			// Manually entered activities have a lap summary but no track
+			name: "laps without trackpoints",
+			tcx: `<TrainingCenterDatabase><Activities><Activity Sport="Biking">
+  <Lap StartTime="2024-05-01T07:00:00Z"><TotalTimeSeconds>1800</TotalTimeSeconds><DistanceMeters>12000</DistanceMeters></Lap>
+ </Activity></Activities></TrainingCenterDatabase>`,
+			wantStart:    start,
+			wantDuration: 30 * time.Minute,
+			wantDistance: 12000,
+			wantLaps:     1,
+		},
+		{
+			// Without lap totals the summary comes from the trackpoints
+			name: "no lap totals",
+			tcx: `<TrainingCenterDatabase><Activities><Activity Sport="Running"><Lap><Track>
+  <Trackpoint><Time>2024-05-01T07:00:00Z</Time><Position><LatitudeDegrees>0</LatitudeDegrees><LongitudeDegrees>0</LongitudeDegrees></Position></Trackpoint>
+  <Trackpoint><Time>2024-05-01T07:06:00Z</Time><Position><LatitudeDegrees>0.01</LatitudeDegrees><LongitudeDegrees>0</LongitudeDegrees></Position></Trackpoint>
+ </Track></Lap></Activity></Activities></TrainingCenterDatabase>`,
+			wantStart:    start,
+			wantDuration: 6 * time.Minute,
+			wantDistance: hundredthDegreeMeters,
+			wantLaps:     1,
+			wantPoints:   2,
+		},
+		{
+			name: "single point without timestamp",
+			tcx: `<TrainingCenterDatabase><Activities><Activity Sport="Other"><Lap><Track>
+  <Trackpoint><DistanceMeters>0</DistanceMeters></Trackpoint>
+ </Track></Lap></Activity></Activities></TrainingCenterDatabase>`,
+			wantLaps:   1,
+			wantPoints: 1,
+		},
+		{
+			name:    "no activities",
+			tcx:     `<TrainingCenterDatabase><Activities></Activities></TrainingCenterDatabase>`,
+			wantErr: ErrNoTrackpoints,
+		},
+		{
+			name:    "activity without laps",
+			tcx:     `<TrainingCenterDatabase><Activities><Activity Sport="Running"><Id>2024-05-01T07:00:00Z</Id></Activity></Activities></TrainingCenterDatabase>`,
+			wantErr: ErrNoTrackpoints,
+		},
+		{
+			name: "invalid xml",
+			tcx:  `<TrainingCenterDatabase><Activities><Activity Sport="Running">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Parse(FormatTCX, strings.NewReader(tt.tcx))
+			if tt.wantErr != nil || tt.wantLaps == 0 {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Parse error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if !w.StartTime.Equal(tt.wantStart) || w.Duration != tt.wantDuration {
+				t.Errorf("start/duration = %v/%v, want %v/%v", w.StartTime, w.Duration, tt.wantStart, tt.wantDuration)
+			}
+			if !almostEqual(w.DistanceMeters, tt.wantDistance) || !almostEqual(w.ElevationGainMeters, tt.wantGain) {
+				t.Errorf("distance/gain = %v/%v, want %v/%v", w.DistanceMeters, w.ElevationGainMeters, tt.wantDistance, tt.wantGain)
+			}
+			if len(w.Laps) != tt.wantLaps || len(w.Points) != tt.wantPoints {
+				t.Errorf("laps/points = %d/%d, want %d/%d", len(w.Laps), len(w.Points), tt.wantLaps, tt.wantPoints)
+			}
+		})
+	}
+}
+
+func TestParseTCXLaps(t *testing.T) {
+	tcx := `<TrainingCenterDatabase><Activities><Activity Sport="Running"><Id>2024-05-01T07:00:00Z</Id>
+  <Lap StartTime="2024-05-01T07:00:00Z">
+   <TotalTimeSeconds>150.4</TotalTimeSeconds><DistanceMeters>500</DistanceMeters><Calories>40</Calories>
+   <AverageHeartRateBpm><Value>131</Value></AverageHeartRateBpm><MaximumHeartRateBpm><Value>140</Value></MaximumHeartRateBpm>
+  </Lap>
+ </Activity></Activities></TrainingCenterDatabase>`
+
+	w, err := ParseTCX(strings.NewReader(tcx))
+	if err != nil {
+		t.Fatalf("ParseTCX: %v", err)
+	}
+
+	want := Lap{
+		StartTime:      time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+		Duration:       150400 * time.Millisecond,
+		DistanceMeters: 500,
+		Calories:       40,
+		AvgHeartRate:   131,
+		MaxHeartRate:   140,
+	}
+	if w.Sport != "Running" || len(w.Laps) != 1 {
+		t.Fatalf("sport/laps = %q/%d, want Running/1", w.Sport, len(w.Laps))
+	}
+	if got := w.Laps[0]; !got.StartTime.Equal(want.StartTime) || got.Duration != want.Duration ||
+		got.DistanceMeters != want.DistanceMeters || got.Calories != want.Calories ||
+		got.AvgHeartRate != want.AvgHeartRate || got.MaxHeartRate != want.MaxHeartRate {
+		t.Errorf("lap = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFIT(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "running.fit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w, err := Parse(FormatFIT, f)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if w.Sport != "running" || !w.StartTime.Equal(time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("sport/start = %q/%v", w.Sport, w.StartTime)
+	}
+	// The timer time leaves out the 10 seconds the watch was paused
+	if w.Duration != 5*time.Minute || !almostEqual(w.DistanceMeters, 1000) || w.ElevationGainMeters != 13 {
+		t.Errorf("duration/distance/gain = %v/%v/%v, want 5m0s/1000/13", w.Duration, w.DistanceMeters, w.ElevationGainMeters)
+	}
+	if len(w.Laps) != 2 || len(w.Points) != 6 {
+		t.Errorf("laps/points = %d/%d, want 2/6", len(w.Laps), len(w.Points))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("kml", strings.NewReader("<kml/>")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Parse kml error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+	if _, err := Parse(FormatFIT, strings.NewReader("<gpx/>")); err == nil {
+		t.Error("Parse of a GPX document as FIT succeeded")
+	}
+}
@@ -0,0 +1,9 @@
+-- Drop foreign key constraint
+ALTER TABLE goals DROP CONSTRAINT IF EXISTS fk_goals_user_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_goals_deleted_at;
+DROP INDEX IF EXISTS idx_goals_user_id;
+
+-- Drop the goals table
+DROP TABLE IF EXISTS goals;
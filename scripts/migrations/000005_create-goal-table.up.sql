@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS goals (
+    id BIGSERIAL PRIMARY KEY,
+    goal_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    metric VARCHAR(20) NOT NULL CHECK (metric IN ('ACTIVE_MINUTES', 'CALORIES', 'SESSIONS')),
+    activity_type VARCHAR(50) DEFAULT '' CHECK (activity_type IN ('', 'Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope')),
+    period VARCHAR(10) NOT NULL CHECK (period IN ('WEEKLY', 'MONTHLY')),
+    target INTEGER NOT NULL CHECK (target > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
+CREATE INDEX IF NOT EXISTS idx_goals_deleted_at ON goals(deleted_at);
+
+-- Add foreign key constraint to profiles table
+ALTER TABLE goals ADD CONSTRAINT fk_goals_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
@@ -0,0 +1,8 @@
+-- Drop foreign key constraint
+ALTER TABLE activities DROP CONSTRAINT IF EXISTS fk_activities_file_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activities_file_id;
+
+-- Drop the column
+ALTER TABLE activities DROP COLUMN IF EXISTS file_id;
@@ -0,0 +1,7 @@
+-- Link activities to the workout file they were imported from
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS file_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_activities_file_id ON activities(file_id);
+
+ALTER TABLE activities ADD CONSTRAINT fk_activities_file_id
+    FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE SET NULL;
@@ -0,0 +1,10 @@
+-- Drop foreign key constraint
+ALTER TABLE import_jobs DROP CONSTRAINT IF EXISTS fk_import_jobs_user_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_import_jobs_user_id;
+DROP INDEX IF EXISTS idx_activities_user_id_source_uuid;
+
+-- Drop the import jobs table and source column
+DROP TABLE IF EXISTS import_jobs;
+ALTER TABLE activities DROP COLUMN IF EXISTS source_uuid;
@@ -0,0 +1,30 @@
+-- Identifier of the record an activity was imported from, used to skip re-imports
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS source_uuid VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_activities_user_id_source_uuid
+    ON activities(user_id, source_uuid) WHERE source_uuid IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'RUNNING', 'COMPLETED', 'FAILED')),
+    bytes_total BIGINT DEFAULT 0,
+    bytes_processed BIGINT DEFAULT 0,
+    processed INTEGER DEFAULT 0,
+    imported INTEGER DEFAULT 0,
+    skipped INTEGER DEFAULT 0,
+    error TEXT DEFAULT '',
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_id ON import_jobs(user_id);
+
+-- Add foreign key constraint to profiles table
+ALTER TABLE import_jobs ADD CONSTRAINT fk_import_jobs_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activities_user_distance;
+
+-- Drop the columns
+ALTER TABLE activities DROP COLUMN IF EXISTS pool_length_meters;
+ALTER TABLE activities DROP COLUMN IF EXISTS elevation_gain_meters;
+ALTER TABLE activities DROP COLUMN IF EXISTS distance_meters;
@@ -0,0 +1,11 @@
+-- Distance and elevation for endurance activities, pool length for swimming
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS distance_meters DOUBLE PRECISION
+    CONSTRAINT chk_activities_distance_meters CHECK (distance_meters > 0);
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS elevation_gain_meters DOUBLE PRECISION
+    CONSTRAINT chk_activities_elevation_gain_meters CHECK (elevation_gain_meters >= 0);
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS pool_length_meters DOUBLE PRECISION
+    CONSTRAINT chk_activities_pool_length_meters CHECK (pool_length_meters > 0);
+
+-- Index for distance range filters
+CREATE INDEX IF NOT EXISTS idx_activities_user_distance ON activities(user_id, distance_meters)
+    WHERE distance_meters IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Drop foreign key constraints
+ALTER TABLE activity_tracks DROP CONSTRAINT IF EXISTS fk_activity_tracks_user_id;
+ALTER TABLE activity_tracks DROP CONSTRAINT IF EXISTS fk_activity_tracks_activity_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activity_tracks_user_bounds;
+
+-- Drop the table
+DROP TABLE IF EXISTS activity_tracks;
@@ -0,0 +1,23 @@
+-- GPS routes of activities, stored as compact delta-encoded points
+CREATE TABLE IF NOT EXISTS activity_tracks (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    point_count INTEGER NOT NULL CHECK (point_count > 0),
+    points BYTEA NOT NULL,
+    min_latitude DOUBLE PRECISION NOT NULL,
+    min_longitude DOUBLE PRECISION NOT NULL,
+    max_latitude DOUBLE PRECISION NOT NULL,
+    max_longitude DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for finding a user's activities within a map area
+CREATE INDEX IF NOT EXISTS idx_activity_tracks_user_bounds
+    ON activity_tracks(user_id, min_latitude, max_latitude, min_longitude, max_longitude);
+
+ALTER TABLE activity_tracks ADD CONSTRAINT fk_activity_tracks_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
+
+ALTER TABLE activity_tracks ADD CONSTRAINT fk_activity_tracks_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+-- Drop foreign key constraint
+ALTER TABLE activity_laps DROP CONSTRAINT IF EXISTS fk_activity_laps_activity_id;
+
+-- Drop the table
+DROP TABLE IF EXISTS activity_laps;
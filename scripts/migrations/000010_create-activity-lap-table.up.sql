@@ -0,0 +1,16 @@
+-- Laps recorded by the device in imported TCX and FIT files
+CREATE TABLE IF NOT EXISTS activity_laps (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id VARCHAR(255) NOT NULL,
+    lap_index INTEGER NOT NULL CHECK (lap_index >= 0),
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_in_seconds INTEGER NOT NULL CHECK (duration_in_seconds >= 0),
+    distance_meters DOUBLE PRECISION NOT NULL CHECK (distance_meters >= 0),
+    calories INTEGER,
+    avg_heart_rate INTEGER,
+    max_heart_rate INTEGER,
+    UNIQUE (activity_id, lap_index)
+);
+
+ALTER TABLE activity_laps ADD CONSTRAINT fk_activity_laps_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
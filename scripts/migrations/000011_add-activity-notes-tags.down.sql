@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activities_tags;
+DROP INDEX IF EXISTS idx_activities_search_vector;
+
+-- Drop the columns
+ALTER TABLE activities DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE activities DROP COLUMN IF EXISTS tags;
+ALTER TABLE activities DROP COLUMN IF EXISTS notes;
+
+DROP FUNCTION IF EXISTS activity_search_vector(TEXT, TEXT[]);
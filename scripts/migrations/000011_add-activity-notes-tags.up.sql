@@ -0,0 +1,16 @@
+-- Free-text notes and user-defined tags on activities
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS notes TEXT;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- to_tsvector with an explicit configuration is immutable but array_to_string is not
+-- declared so, hence the wrapper that makes the generated column possible
+CREATE OR REPLACE FUNCTION activity_search_vector(notes TEXT, tags TEXT[]) RETURNS tsvector
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT to_tsvector('simple', coalesce(notes, '') || ' ' || array_to_string(tags, ' ')) $$;
+
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (activity_search_vector(notes, tags)) STORED;
+
+-- Indexes for full-text search and tag filtering
+CREATE INDEX IF NOT EXISTS idx_activities_search_vector ON activities USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_activities_tags ON activities USING GIN (tags);
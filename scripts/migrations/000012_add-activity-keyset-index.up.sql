@@ -0,0 +1,2 @@
+-- Composite index for keyset pagination of a user's activities by done_at
+CREATE INDEX IF NOT EXISTS idx_activities_user_done_at_id ON activities(user_id, done_at, id);
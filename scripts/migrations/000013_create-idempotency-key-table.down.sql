@@ -0,0 +1,8 @@
+-- Drop foreign key constraint
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS fk_idempotency_keys_user_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+
+-- Drop the table
+DROP TABLE IF EXISTS idempotency_keys;
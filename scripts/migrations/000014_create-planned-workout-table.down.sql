@@ -0,0 +1,13 @@
+-- Drop foreign key constraints
+ALTER TABLE planned_workout_completions DROP CONSTRAINT IF EXISTS fk_planned_workout_completions_activity_id;
+ALTER TABLE planned_workout_completions DROP CONSTRAINT IF EXISTS fk_planned_workout_completions_planned_workout_id;
+ALTER TABLE planned_workouts DROP CONSTRAINT IF EXISTS fk_planned_workouts_user_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_planned_workout_completions_user_id_date;
+DROP INDEX IF EXISTS idx_planned_workouts_deleted_at;
+DROP INDEX IF EXISTS idx_planned_workouts_user_id_dates;
+
+-- Drop the tables
+DROP TABLE IF EXISTS planned_workout_completions;
+DROP TABLE IF EXISTS planned_workouts;
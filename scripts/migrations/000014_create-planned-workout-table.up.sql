@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS planned_workouts (
+    id BIGSERIAL PRIMARY KEY,
+    planned_workout_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    activity_type VARCHAR(50) NOT NULL CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope')),
+    scheduled_date DATE NOT NULL,
+    target_duration_in_minutes INTEGER NOT NULL CHECK (target_duration_in_minutes > 0),
+    target_calories INTEGER NOT NULL CHECK (target_calories > 0),
+    recurrence VARCHAR(255),
+    -- Final occurrence of a recurring workout, NULL when it repeats forever
+    last_date DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Index for finding the workouts planned within a date range
+CREATE INDEX IF NOT EXISTS idx_planned_workouts_user_id_dates ON planned_workouts(user_id, scheduled_date, last_date);
+CREATE INDEX IF NOT EXISTS idx_planned_workouts_deleted_at ON planned_workouts(deleted_at);
+
+ALTER TABLE planned_workouts ADD CONSTRAINT fk_planned_workouts_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+-- Occurrences of planned workouts completed by a logged activity
+CREATE TABLE IF NOT EXISTS planned_workout_completions (
+    id BIGSERIAL PRIMARY KEY,
+    planned_workout_id VARCHAR(255) NOT NULL,
+    user_id BIGINT NOT NULL,
+    occurrence_date DATE NOT NULL,
+    activity_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (planned_workout_id, occurrence_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_planned_workout_completions_user_id_date ON planned_workout_completions(user_id, occurrence_date);
+
+ALTER TABLE planned_workout_completions ADD CONSTRAINT fk_planned_workout_completions_planned_workout_id
+    FOREIGN KEY (planned_workout_id) REFERENCES planned_workouts(planned_workout_id) ON DELETE CASCADE;
+
+ALTER TABLE planned_workout_completions ADD CONSTRAINT fk_planned_workout_completions_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+-- Drop foreign key constraint
+ALTER TABLE workout_templates DROP CONSTRAINT IF EXISTS fk_workout_templates_user_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_workout_templates_deleted_at;
+DROP INDEX IF EXISTS idx_workout_templates_user_id_name;
+
+-- Drop the table
+DROP TABLE IF EXISTS workout_templates;
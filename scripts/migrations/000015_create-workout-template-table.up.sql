@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS workout_templates (
+    id BIGSERIAL PRIMARY KEY,
+    template_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    activity_type VARCHAR(50) NOT NULL CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope')),
+    duration_in_minutes INTEGER NOT NULL CHECK (duration_in_minutes > 0),
+    notes TEXT,
+    exercises JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Template names are unique per user among templates that have not been deleted
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workout_templates_user_id_name ON workout_templates(user_id, name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_workout_templates_deleted_at ON workout_templates(deleted_at);
+
+ALTER TABLE workout_templates ADD CONSTRAINT fk_workout_templates_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
@@ -0,0 +1,18 @@
+-- Drop the planned workout columns
+ALTER TABLE planned_workouts DROP CONSTRAINT IF EXISTS fk_planned_workouts_enrollment_id;
+DROP INDEX IF EXISTS idx_planned_workouts_enrollment_id_date;
+ALTER TABLE planned_workouts DROP COLUMN IF EXISTS notes;
+ALTER TABLE planned_workouts DROP COLUMN IF EXISTS enrollment_id;
+
+-- Drop foreign key constraints
+ALTER TABLE program_enrollment_weeks DROP CONSTRAINT IF EXISTS fk_program_enrollment_weeks_enrollment_id;
+ALTER TABLE program_enrollments DROP CONSTRAINT IF EXISTS fk_program_enrollments_user_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_program_enrollments_deleted_at;
+DROP INDEX IF EXISTS idx_program_enrollments_status;
+DROP INDEX IF EXISTS idx_program_enrollments_user_id_program_id;
+
+-- Drop the tables
+DROP TABLE IF EXISTS program_enrollment_weeks;
+DROP TABLE IF EXISTS program_enrollments;
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS program_enrollments (
+    id BIGSERIAL PRIMARY KEY,
+    enrollment_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    program_id VARCHAR(100) NOT NULL,
+    program_version INTEGER NOT NULL CHECK (program_version > 0),
+    start_date DATE NOT NULL,
+    -- Program week in progress, counted from 0, and the date it started on
+    current_week INTEGER NOT NULL DEFAULT 0 CHECK (current_week >= 0),
+    current_week_start DATE NOT NULL,
+    week_repeats INTEGER NOT NULL DEFAULT 0 CHECK (week_repeats >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled')),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- A user follows a program at most once at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_program_enrollments_user_id_program_id ON program_enrollments(user_id, program_id) WHERE status = 'active' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_program_enrollments_status ON program_enrollments(status);
+CREATE INDEX IF NOT EXISTS idx_program_enrollments_deleted_at ON program_enrollments(deleted_at);
+
+ALTER TABLE program_enrollments ADD CONSTRAINT fk_program_enrollments_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+-- Outcome of every finished program week, repeated weeks included
+CREATE TABLE IF NOT EXISTS program_enrollment_weeks (
+    id BIGSERIAL PRIMARY KEY,
+    enrollment_id VARCHAR(255) NOT NULL,
+    week INTEGER NOT NULL CHECK (week >= 0),
+    start_date DATE NOT NULL,
+    planned_sessions INTEGER NOT NULL DEFAULT 0,
+    completed_sessions INTEGER NOT NULL DEFAULT 0,
+    repeated BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (enrollment_id, start_date)
+);
+
+ALTER TABLE program_enrollment_weeks ADD CONSTRAINT fk_program_enrollment_weeks_enrollment_id
+    FOREIGN KEY (enrollment_id) REFERENCES program_enrollments(enrollment_id) ON DELETE CASCADE;
+
+-- Planned workouts materialized from a program session
+ALTER TABLE planned_workouts ADD COLUMN IF NOT EXISTS enrollment_id VARCHAR(255);
+ALTER TABLE planned_workouts ADD COLUMN IF NOT EXISTS notes TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_planned_workouts_enrollment_id_date ON planned_workouts(enrollment_id, scheduled_date);
+
+ALTER TABLE planned_workouts ADD CONSTRAINT fk_planned_workouts_enrollment_id
+    FOREIGN KEY (enrollment_id) REFERENCES program_enrollments(enrollment_id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- Drop the trash index
+DROP INDEX IF EXISTS idx_activities_user_id_deleted_at;
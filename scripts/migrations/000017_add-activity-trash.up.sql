@@ -0,0 +1,9 @@
+-- A restore only clears deleted_at, so a trashed activity keeps its activity_id and cannot
+-- conflict when it comes back. activity_id stays unique over every row because tracks, laps
+-- and plan completions reference it by foreign key, which a partial index cannot back. The
+-- index on imported source records keeps covering trashed activities too, so an import
+-- skips them instead of creating a record their restore would clash with.
+
+-- Index for listing a user's trash and purging it
+CREATE INDEX IF NOT EXISTS idx_activities_user_id_deleted_at
+    ON activities(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
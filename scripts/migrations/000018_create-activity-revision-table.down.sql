@@ -0,0 +1,8 @@
+-- Drop foreign key constraint
+ALTER TABLE activity_revisions DROP CONSTRAINT IF EXISTS fk_activity_revisions_activity_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activity_revisions_user_id;
+
+-- Drop the table
+DROP TABLE IF EXISTS activity_revisions;
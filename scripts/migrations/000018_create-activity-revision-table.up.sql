@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS activity_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id VARCHAR(255) NOT NULL,
+    user_id BIGINT NOT NULL,
+    revision INTEGER NOT NULL CHECK (revision > 0),
+    operation VARCHAR(20) NOT NULL CHECK (operation IN ('create', 'update', 'delete', 'restore', 'merge', 'revert')),
+    -- Changed fields with their old and new values
+    changes JSONB NOT NULL DEFAULT '{}',
+    -- Editable fields of the activity after the change
+    snapshot JSONB NOT NULL,
+    actor_id BIGINT NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    request_id VARCHAR(255),
+    reverted_to_revision INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (activity_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_revisions_user_id ON activity_revisions(user_id);
+
+-- History is purged together with the activity when it leaves the trash
+ALTER TABLE activity_revisions ADD CONSTRAINT fk_activity_revisions_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
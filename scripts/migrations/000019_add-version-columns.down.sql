@@ -0,0 +1,3 @@
+-- Drop the version columns
+ALTER TABLE profiles DROP COLUMN IF EXISTS version;
+ALTER TABLE activities DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Every write bumps the version, which clients send back in If-Match so that a stale
+-- edit is rejected instead of overwriting a newer one
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
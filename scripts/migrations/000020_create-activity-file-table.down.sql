@@ -0,0 +1,9 @@
+-- Drop foreign key constraints
+ALTER TABLE activity_files DROP CONSTRAINT IF EXISTS fk_activity_files_file_id;
+ALTER TABLE activity_files DROP CONSTRAINT IF EXISTS fk_activity_files_activity_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activity_files_activity_id;
+
+-- Drop the table
+DROP TABLE IF EXISTS activity_files;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS activity_files (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id VARCHAR(255) NOT NULL,
+    -- An upload can be attached to a single activity only
+    file_id INTEGER NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_files_activity_id ON activity_files(activity_id);
+
+ALTER TABLE activity_files ADD CONSTRAINT fk_activity_files_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
+
+ALTER TABLE activity_files ADD CONSTRAINT fk_activity_files_file_id
+    FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE CASCADE;
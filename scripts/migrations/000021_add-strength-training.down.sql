@@ -0,0 +1,24 @@
+-- Drop the strength sets table
+DROP TABLE IF EXISTS strength_sets;
+
+-- Remove strength sessions and restore the previous activity types
+DELETE FROM activities WHERE activity_type = 'StrengthTraining';
+DELETE FROM goals WHERE activity_type = 'StrengthTraining';
+DELETE FROM planned_workouts WHERE activity_type = 'StrengthTraining';
+DELETE FROM workout_templates WHERE activity_type = 'StrengthTraining';
+
+ALTER TABLE activities DROP CONSTRAINT IF EXISTS activities_activity_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_activity_type_check
+    CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope'));
+
+ALTER TABLE goals DROP CONSTRAINT IF EXISTS goals_activity_type_check;
+ALTER TABLE goals ADD CONSTRAINT goals_activity_type_check
+    CHECK (activity_type IN ('', 'Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope'));
+
+ALTER TABLE planned_workouts DROP CONSTRAINT IF EXISTS planned_workouts_activity_type_check;
+ALTER TABLE planned_workouts ADD CONSTRAINT planned_workouts_activity_type_check
+    CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope'));
+
+ALTER TABLE workout_templates DROP CONSTRAINT IF EXISTS workout_templates_activity_type_check;
+ALTER TABLE workout_templates ADD CONSTRAINT workout_templates_activity_type_check
+    CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope'));
@@ -0,0 +1,38 @@
+-- Allow the StrengthTraining activity type everywhere an activity type is stored
+ALTER TABLE activities DROP CONSTRAINT IF EXISTS activities_activity_type_check;
+ALTER TABLE activities ADD CONSTRAINT activities_activity_type_check
+    CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope', 'StrengthTraining'));
+
+ALTER TABLE goals DROP CONSTRAINT IF EXISTS goals_activity_type_check;
+ALTER TABLE goals ADD CONSTRAINT goals_activity_type_check
+    CHECK (activity_type IN ('', 'Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope', 'StrengthTraining'));
+
+ALTER TABLE planned_workouts DROP CONSTRAINT IF EXISTS planned_workouts_activity_type_check;
+ALTER TABLE planned_workouts ADD CONSTRAINT planned_workouts_activity_type_check
+    CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope', 'StrengthTraining'));
+
+ALTER TABLE workout_templates DROP CONSTRAINT IF EXISTS workout_templates_activity_type_check;
+ALTER TABLE workout_templates ADD CONSTRAINT workout_templates_activity_type_check
+    CHECK (activity_type IN ('Walking', 'Yoga', 'Stretching', 'Cycling', 'Swimming', 'Dancing', 'Hiking', 'Running', 'HIIT', 'JumpRope', 'StrengthTraining'));
+
+-- Sets of a strength session, in the order the exercises and sets were done.
+-- exercise_id refers to the exercise catalog file rather than to a table.
+CREATE TABLE IF NOT EXISTS strength_sets (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id VARCHAR(255) NOT NULL,
+    user_id BIGINT NOT NULL,
+    exercise_id VARCHAR(100) NOT NULL,
+    exercise_index INTEGER NOT NULL CHECK (exercise_index >= 0),
+    set_index INTEGER NOT NULL CHECK (set_index >= 0),
+    reps INTEGER NOT NULL CHECK (reps > 0),
+    weight_kg DOUBLE PRECISION CHECK (weight_kg >= 0),
+    rpe DOUBLE PRECISION CHECK (rpe BETWEEN 1 AND 10),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (activity_id, exercise_index, set_index)
+);
+
+-- Index for per-exercise history
+CREATE INDEX IF NOT EXISTS idx_strength_sets_user_id_exercise_id ON strength_sets(user_id, exercise_id);
+
+ALTER TABLE strength_sets ADD CONSTRAINT fk_strength_sets_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+-- Drop the heart rate zone inputs
+ALTER TABLE profiles DROP COLUMN IF EXISTS birth_date;
+ALTER TABLE profiles DROP COLUMN IF EXISTS max_heart_rate;
+
+-- Drop foreign key constraints
+ALTER TABLE activity_heart_rates DROP CONSTRAINT IF EXISTS fk_activity_heart_rates_user_id;
+ALTER TABLE activity_heart_rates DROP CONSTRAINT IF EXISTS fk_activity_heart_rates_activity_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_activity_heart_rates_user_id;
+
+-- Drop the table
+DROP TABLE IF EXISTS activity_heart_rates;
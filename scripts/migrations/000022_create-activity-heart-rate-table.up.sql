@@ -0,0 +1,28 @@
+-- Heart rate recordings of activities, stored as compact delta-encoded samples
+CREATE TABLE IF NOT EXISTS activity_heart_rates (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id VARCHAR(255) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL,
+    sample_count INTEGER NOT NULL CHECK (sample_count > 0),
+    samples BYTEA NOT NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    min_bpm INTEGER NOT NULL,
+    avg_bpm INTEGER NOT NULL,
+    max_bpm INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_heart_rates_user_id ON activity_heart_rates(user_id);
+
+ALTER TABLE activity_heart_rates ADD CONSTRAINT fk_activity_heart_rates_activity_id
+    FOREIGN KEY (activity_id) REFERENCES activities(activity_id) ON DELETE CASCADE;
+
+ALTER TABLE activity_heart_rates ADD CONSTRAINT fk_activity_heart_rates_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+-- Heart rate zones are derived from the maximum heart rate, which is either set by the
+-- user or estimated from their age
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS max_heart_rate INTEGER;
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS birth_date DATE;
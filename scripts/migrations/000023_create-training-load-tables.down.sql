@@ -0,0 +1,10 @@
+-- Drop the resting heart rate
+ALTER TABLE profiles DROP COLUMN IF EXISTS resting_heart_rate;
+
+-- Drop foreign key constraints
+ALTER TABLE training_load_states DROP CONSTRAINT IF EXISTS fk_training_load_states_user_id;
+ALTER TABLE training_load_days DROP CONSTRAINT IF EXISTS fk_training_load_days_user_id;
+
+-- Drop the tables
+DROP TABLE IF EXISTS training_load_states;
+DROP TABLE IF EXISTS training_load_days;
@@ -0,0 +1,29 @@
+-- Acute and chronic training load at the end of every UTC day the user trained. Days in
+-- between only decay, so they are worked out when read.
+CREATE TABLE IF NOT EXISTS training_load_days (
+    user_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    activity_count INTEGER NOT NULL,
+    load DOUBLE PRECISION NOT NULL,
+    acute_load DOUBLE PRECISION NOT NULL,
+    chronic_load DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (user_id, day)
+);
+
+ALTER TABLE training_load_days ADD CONSTRAINT fk_training_load_days_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+-- Where the stored days stop being current. A change to an activity moves stale_from back
+-- to its day and bumps generation; reading the load recomputes the days from there on.
+-- A user without a row has never had their load computed.
+CREATE TABLE IF NOT EXISTS training_load_states (
+    user_id BIGINT PRIMARY KEY,
+    stale_from DATE,
+    generation BIGINT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE training_load_states ADD CONSTRAINT fk_training_load_states_user_id
+    FOREIGN KEY (user_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+-- TRIMP is scaled by the heart rate reserve, which starts at the resting heart rate
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS resting_heart_rate INTEGER;
@@ -0,0 +1,16 @@
+-- Drop activity visibility
+DROP INDEX IF EXISTS idx_activities_feed;
+ALTER TABLE activities DROP COLUMN IF EXISTS visibility;
+
+-- Drop private accounts
+ALTER TABLE profiles DROP COLUMN IF EXISTS is_private;
+
+-- Drop foreign key constraints
+ALTER TABLE follows DROP CONSTRAINT IF EXISTS fk_follows_followee_id;
+ALTER TABLE follows DROP CONSTRAINT IF EXISTS fk_follows_follower_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_follows_followee_status;
+
+-- Drop the table
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,32 @@
+-- Who follows whom. Following a private account starts out pending until the account
+-- accepts the request.
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id BIGINT NOT NULL,
+    followee_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'accepted')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+-- Followers and follow requests of a user
+CREATE INDEX IF NOT EXISTS idx_follows_followee_status ON follows(followee_id, status, created_at);
+
+ALTER TABLE follows ADD CONSTRAINT fk_follows_follower_id
+    FOREIGN KEY (follower_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+ALTER TABLE follows ADD CONSTRAINT fk_follows_followee_id
+    FOREIGN KEY (followee_id) REFERENCES profiles(id) ON DELETE CASCADE;
+
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS is_private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Activities recorded before they could be shared stay private; new ones are shared
+-- with followers unless the user says otherwise
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'private'
+    CHECK (visibility IN ('private', 'followers', 'public'));
+ALTER TABLE activities ALTER COLUMN visibility SET DEFAULT 'followers';
+
+-- The feed reads the latest shared activities of every followed user from this index
+CREATE INDEX IF NOT EXISTS idx_activities_feed ON activities(user_id, done_at DESC, id DESC)
+    WHERE deleted_at IS NULL AND visibility <> 'private';
@@ -0,0 +1,2 @@
+-- Drop the unit preference
+ALTER TABLE profiles DROP COLUMN IF EXISTS preferred_units;
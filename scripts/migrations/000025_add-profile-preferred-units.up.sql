@@ -0,0 +1,4 @@
+-- The unit system distances are shown in. Empty until the user picks one, in which case
+-- it follows the height unit.
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS preferred_units VARCHAR(10) NOT NULL DEFAULT ''
+    CHECK (preferred_units IN ('', 'METRIC', 'IMPERIAL'));